@@ -0,0 +1,383 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// annotationHubData holds the spec and status of the Hub version (v1beta1) the DynaKube has been converted from, so fields
+// without a v1alpha1 equivalent are kept when clients of v1alpha1 update the DynaKube.
+const annotationHubData = "operator.dynatrace.com/v1beta1-data"
+
+// hubData is the content of annotationHubData.
+type hubData struct {
+	Spec   v1beta1.DynaKubeSpec   `json:"spec"`
+	Status v1beta1.DynaKubeStatus `json:"status"`
+}
+
+// ConvertTo converts this DynaKube to the Hub version (v1beta1).
+//
+// v1alpha1 allows enabling several OneAgent modes at once, while v1beta1 only supports a single one. If more than one
+// mode is enabled, ClassicFullStack takes precedence, and InfraMonitoring together with CodeModules is converted
+// into CloudNativeFullStack. Configuration of disabled modes is dropped. Fields without a v1alpha1 equivalent are
+// restored from annotationHubData.
+func (src *DynaKube) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.DynaKube)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Annotations = copyAnnotations(src.Annotations, annotationHubData)
+
+	dst.Spec.APIURL = src.Spec.APIURL
+	dst.Spec.Tokens = src.Spec.Tokens
+	dst.Spec.CustomPullSecret = src.Spec.CustomPullSecret
+	dst.Spec.SkipCertCheck = src.Spec.SkipCertCheck
+	if src.Spec.Proxy != nil {
+		dst.Spec.Proxy = &v1beta1.DynaKubeProxy{Value: src.Spec.Proxy.Value, ValueFrom: src.Spec.Proxy.ValueFrom}
+	}
+	dst.Spec.TrustedCAs = src.Spec.TrustedCAs
+	dst.Spec.NetworkZone = src.Spec.NetworkZone
+	dst.Spec.EnableIstio = src.Spec.EnableIstio
+	dst.Spec.ActiveGate = v1beta1.ActiveGateSpec{Image: src.Spec.ActiveGate.Image, AutoUpdate: src.Spec.ActiveGate.AutoUpdate}
+	dst.Spec.OneAgent = src.convertOneAgentTo()
+	dst.Spec.RoutingSpec.CapabilityProperties = convertCapabilityTo(&src.Spec.RoutingSpec.CapabilityProperties)
+	dst.Spec.DataIngestSpec.CapabilityProperties = convertCapabilityTo(&src.Spec.DataIngestSpec.CapabilityProperties)
+	dst.Spec.KubernetesMonitoringSpec.CapabilityProperties = convertCapabilityTo(&src.Spec.KubernetesMonitoringSpec.CapabilityProperties)
+
+	src.convertStatusTo(&dst.Status)
+
+	if raw, ok := src.Annotations[annotationHubData]; ok {
+		var restored hubData
+		if err := json.Unmarshal([]byte(raw), &restored); err != nil {
+			return err
+		}
+		restoreSpec(&dst.Spec, &restored.Spec)
+		restoreStatus(&dst.Status, &restored.Status)
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version. The spec and status of the Hub version are
+// stored in annotationHubData.
+func (dst *DynaKube) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.DynaKube)
+	dst.ObjectMeta = src.ObjectMeta
+
+	raw, err := json.Marshal(hubData{Spec: src.Spec, Status: src.Status})
+	if err != nil {
+		return err
+	}
+	dst.Annotations = copyAnnotations(src.Annotations, annotationHubData)
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[annotationHubData] = string(raw)
+
+	dst.Spec.APIURL = src.Spec.APIURL
+	dst.Spec.Tokens = src.Spec.Tokens
+	dst.Spec.CustomPullSecret = src.Spec.CustomPullSecret
+	dst.Spec.SkipCertCheck = src.Spec.SkipCertCheck
+	if src.Spec.Proxy != nil {
+		dst.Spec.Proxy = &DynaKubeProxy{Value: src.Spec.Proxy.Value, ValueFrom: src.Spec.Proxy.ValueFrom}
+	}
+	dst.Spec.TrustedCAs = src.Spec.TrustedCAs
+	dst.Spec.NetworkZone = src.Spec.NetworkZone
+	dst.Spec.EnableIstio = src.Spec.EnableIstio
+	dst.Spec.ActiveGate = ActiveGateSpec{Image: src.Spec.ActiveGate.Image, AutoUpdate: src.Spec.ActiveGate.AutoUpdate}
+	dst.convertOneAgentFrom(&src.Spec.OneAgent)
+	dst.Spec.RoutingSpec.CapabilityProperties = convertCapabilityFrom(&src.Spec.RoutingSpec.CapabilityProperties)
+	dst.Spec.DataIngestSpec.CapabilityProperties = convertCapabilityFrom(&src.Spec.DataIngestSpec.CapabilityProperties)
+	dst.Spec.KubernetesMonitoringSpec.CapabilityProperties = convertCapabilityFrom(&src.Spec.KubernetesMonitoringSpec.CapabilityProperties)
+
+	dst.convertStatusFrom(&src.Status)
+	return nil
+}
+
+func (src *DynaKube) convertOneAgentTo() v1beta1.OneAgentSpec {
+	classic := &src.Spec.ClassicFullStack
+	infra := &src.Spec.InfraMonitoring
+	codeModules := &src.Spec.CodeModules
+
+	switch {
+	case classic.Enabled:
+		return v1beta1.OneAgentSpec{ClassicFullStack: src.convertHostInjectTo(classic)}
+	case infra.Enabled && codeModules.Enabled:
+		return v1beta1.OneAgentSpec{CloudNativeFullStack: &v1beta1.CloudNativeFullStackSpec{
+			HostInjectSpec:   *src.convertHostInjectTo(infra),
+			AppInjectionSpec: convertAppInjectionTo(codeModules),
+		}}
+	case infra.Enabled:
+		return v1beta1.OneAgentSpec{HostMonitoring: src.convertHostInjectTo(infra)}
+	case codeModules.Enabled:
+		return v1beta1.OneAgentSpec{ApplicationMonitoring: &v1beta1.ApplicationMonitoringSpec{
+			AppInjectionSpec: convertAppInjectionTo(codeModules),
+		}}
+	}
+	return v1beta1.OneAgentSpec{}
+}
+
+func (src *DynaKube) convertHostInjectTo(fullStack *FullStackSpec) *v1beta1.HostInjectSpec {
+	return &v1beta1.HostInjectSpec{
+		Version:             src.Spec.OneAgent.Version,
+		Image:               src.Spec.OneAgent.Image,
+		AutoUpdate:          src.Spec.OneAgent.AutoUpdate,
+		NodeSelector:        fullStack.NodeSelector,
+		Tolerations:         fullStack.Tolerations,
+		WaitReadySeconds:    fullStack.WaitReadySeconds,
+		Resources:           fullStack.Resources,
+		Args:                fullStack.Args,
+		Env:                 fullStack.Env,
+		PriorityClassName:   fullStack.PriorityClassName,
+		DNSPolicy:           fullStack.DNSPolicy,
+		ServiceAccountName:  fullStack.ServiceAccountName,
+		Labels:              fullStack.Labels,
+		UseUnprivilegedMode: fullStack.UseUnprivilegedMode,
+		UseImmutableImage:   fullStack.UseImmutableImage,
+	}
+}
+
+func convertAppInjectionTo(codeModules *CodeModulesSpec) v1beta1.AppInjectionSpec {
+	return v1beta1.AppInjectionSpec{
+		InitResources: codeModules.Resources,
+		Volume:        codeModules.Volume,
+	}
+}
+
+func (dst *DynaKube) convertOneAgentFrom(oneAgent *v1beta1.OneAgentSpec) {
+	switch {
+	case oneAgent.ClassicFullStack != nil:
+		dst.Spec.ClassicFullStack = dst.convertHostInjectFrom(oneAgent.ClassicFullStack)
+	case oneAgent.HostMonitoring != nil:
+		dst.Spec.InfraMonitoring = dst.convertHostInjectFrom(oneAgent.HostMonitoring)
+	case oneAgent.CloudNativeFullStack != nil:
+		dst.Spec.InfraMonitoring = dst.convertHostInjectFrom(&oneAgent.CloudNativeFullStack.HostInjectSpec)
+		dst.Spec.CodeModules = convertAppInjectionFrom(&oneAgent.CloudNativeFullStack.AppInjectionSpec)
+	case oneAgent.ApplicationMonitoring != nil:
+		dst.Spec.CodeModules = convertAppInjectionFrom(&oneAgent.ApplicationMonitoring.AppInjectionSpec)
+	}
+}
+
+func (dst *DynaKube) convertHostInjectFrom(hostInject *v1beta1.HostInjectSpec) FullStackSpec {
+	dst.Spec.OneAgent = OneAgentSpec{
+		Version:    hostInject.Version,
+		Image:      hostInject.Image,
+		AutoUpdate: hostInject.AutoUpdate,
+	}
+
+	return FullStackSpec{
+		Enabled:             true,
+		NodeSelector:        hostInject.NodeSelector,
+		Tolerations:         hostInject.Tolerations,
+		WaitReadySeconds:    hostInject.WaitReadySeconds,
+		Resources:           hostInject.Resources,
+		Args:                hostInject.Args,
+		Env:                 hostInject.Env,
+		PriorityClassName:   hostInject.PriorityClassName,
+		DNSPolicy:           hostInject.DNSPolicy,
+		ServiceAccountName:  hostInject.ServiceAccountName,
+		Labels:              hostInject.Labels,
+		UseUnprivilegedMode: hostInject.UseUnprivilegedMode,
+		UseImmutableImage:   hostInject.UseImmutableImage,
+	}
+}
+
+func convertAppInjectionFrom(appInjection *v1beta1.AppInjectionSpec) CodeModulesSpec {
+	return CodeModulesSpec{
+		Enabled:   true,
+		Resources: appInjection.InitResources,
+		Volume:    appInjection.Volume,
+	}
+}
+
+func convertCapabilityTo(src *CapabilityProperties) v1beta1.CapabilityProperties {
+	dst := v1beta1.CapabilityProperties{
+		Enabled:            src.Enabled,
+		Replicas:           src.Replicas,
+		Group:              src.Group,
+		Resources:          src.Resources,
+		NodeSelector:       src.NodeSelector,
+		Tolerations:        src.Tolerations,
+		Labels:             src.Labels,
+		Args:               src.Args,
+		Env:                src.Env,
+		ServiceAccountName: src.ServiceAccountName,
+	}
+	if src.CustomProperties != nil {
+		dst.CustomProperties = &v1beta1.DynaKubeValueSource{
+			Value:     src.CustomProperties.Value,
+			ValueFrom: src.CustomProperties.ValueFrom,
+		}
+	}
+	return dst
+}
+
+func convertCapabilityFrom(src *v1beta1.CapabilityProperties) CapabilityProperties {
+	dst := CapabilityProperties{
+		Enabled:            src.Enabled,
+		Replicas:           src.Replicas,
+		Group:              src.Group,
+		Resources:          src.Resources,
+		NodeSelector:       src.NodeSelector,
+		Tolerations:        src.Tolerations,
+		Labels:             src.Labels,
+		Args:               src.Args,
+		Env:                src.Env,
+		ServiceAccountName: src.ServiceAccountName,
+	}
+	if src.CustomProperties != nil {
+		dst.CustomProperties = &DynaKubeValueSource{
+			Value:     src.CustomProperties.Value,
+			ValueFrom: src.CustomProperties.ValueFrom,
+		}
+	}
+	return dst
+}
+
+func (src *DynaKube) convertStatusTo(dst *v1beta1.DynaKubeStatus) {
+	dst.Phase = v1beta1.DynaKubePhaseType(src.Status.Phase)
+	dst.UpdatedTimestamp = src.Status.UpdatedTimestamp
+	dst.LastAPITokenProbeTimestamp = src.Status.LastAPITokenProbeTimestamp
+	dst.LastPaaSTokenProbeTimestamp = src.Status.LastPaaSTokenProbeTimestamp
+	dst.Tokens = src.Status.Tokens
+	dst.LastClusterVersionProbeTimestamp = src.Status.LastClusterVersionProbeTimestamp
+	dst.KubeSystemUUID = src.Status.KubeSystemUUID
+	dst.ConnectionInfo.TenantUUID = src.Status.ConnectionInfo.TenantUUID
+	for _, host := range src.Status.ConnectionInfo.CommunicationHosts {
+		dst.ConnectionInfo.CommunicationHosts = append(dst.ConnectionInfo.CommunicationHosts, v1beta1.CommunicationHostStatus(host))
+	}
+	dst.CommunicationHostForClient = v1beta1.CommunicationHostStatus(src.Status.CommunicationHostForClient)
+	dst.LatestAgentVersionUnixDefault = src.Status.LatestAgentVersionUnixDefault
+	dst.LatestAgentVersionUnixPaas = src.Status.LatestAgentVersionUnixPaas
+	dst.Conditions = append([]metav1.Condition(nil), src.Status.Conditions...)
+	dst.ActiveGate.VersionStatus = convertVersionStatusTo(src.Status.ActiveGate.VersionStatus)
+	dst.OneAgent.VersionStatus = convertVersionStatusTo(src.Status.OneAgent.VersionStatus)
+	dst.OneAgent.UseImmutableImage = src.Status.OneAgent.UseImmutableImage
+	dst.OneAgent.LastHostsRequestTimestamp = src.Status.OneAgent.LastHostsRequestTimestamp
+	if src.Status.OneAgent.Instances != nil {
+		dst.OneAgent.Instances = make(map[string]v1beta1.OneAgentInstance, len(src.Status.OneAgent.Instances))
+		for node, instance := range src.Status.OneAgent.Instances {
+			dst.OneAgent.Instances[node] = v1beta1.OneAgentInstance{
+				PodName:   instance.PodName,
+				IPAddress: instance.IPAddress,
+			}
+		}
+	}
+}
+
+func (dst *DynaKube) convertStatusFrom(src *v1beta1.DynaKubeStatus) {
+	dst.Status.Phase = DynaKubePhaseType(src.Phase)
+	dst.Status.UpdatedTimestamp = src.UpdatedTimestamp
+	dst.Status.LastAPITokenProbeTimestamp = src.LastAPITokenProbeTimestamp
+	dst.Status.LastPaaSTokenProbeTimestamp = src.LastPaaSTokenProbeTimestamp
+	dst.Status.Tokens = src.Tokens
+	dst.Status.LastClusterVersionProbeTimestamp = src.LastClusterVersionProbeTimestamp
+	dst.Status.KubeSystemUUID = src.KubeSystemUUID
+	dst.Status.ConnectionInfo.TenantUUID = src.ConnectionInfo.TenantUUID
+	for _, host := range src.ConnectionInfo.CommunicationHosts {
+		dst.Status.ConnectionInfo.CommunicationHosts = append(dst.Status.ConnectionInfo.CommunicationHosts, CommunicationHostStatus(host))
+	}
+	dst.Status.CommunicationHostForClient = CommunicationHostStatus(src.CommunicationHostForClient)
+	dst.Status.LatestAgentVersionUnixDefault = src.LatestAgentVersionUnixDefault
+	dst.Status.LatestAgentVersionUnixPaas = src.LatestAgentVersionUnixPaas
+	dst.Status.Conditions = append([]metav1.Condition(nil), src.Conditions...)
+	dst.Status.ActiveGate.VersionStatus = convertVersionStatusFrom(src.ActiveGate.VersionStatus)
+	dst.Status.OneAgent.VersionStatus = convertVersionStatusFrom(src.OneAgent.VersionStatus)
+	dst.Status.OneAgent.UseImmutableImage = src.OneAgent.UseImmutableImage
+	dst.Status.OneAgent.LastHostsRequestTimestamp = src.OneAgent.LastHostsRequestTimestamp
+	if src.OneAgent.Instances != nil {
+		dst.Status.OneAgent.Instances = make(map[string]OneAgentInstance, len(src.OneAgent.Instances))
+		for node, instance := range src.OneAgent.Instances {
+			dst.Status.OneAgent.Instances[node] = OneAgentInstance{
+				PodName:   instance.PodName,
+				IPAddress: instance.IPAddress,
+			}
+		}
+	}
+}
+
+func convertVersionStatusTo(src VersionStatus) v1beta1.VersionStatus {
+	return v1beta1.VersionStatus{
+		ImageHash:                src.ImageHash,
+		Version:                  src.Version,
+		LastUpdateProbeTimestamp: src.LastUpdateProbeTimestamp,
+	}
+}
+
+func convertVersionStatusFrom(src v1beta1.VersionStatus) VersionStatus {
+	return VersionStatus{
+		ImageHash:                src.ImageHash,
+		Version:                  src.Version,
+		LastUpdateProbeTimestamp: src.LastUpdateProbeTimestamp,
+	}
+}
+
+// copyAnnotations returns a copy of the annotations without the excluded one, so they aren't shared between versions.
+func copyAnnotations(annotations map[string]string, excluded string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	out := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k != excluded {
+			out[k] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// restoreSpec sets the fields without a v1alpha1 equivalent from the restored spec, as long as the OneAgent mode
+// hasn't been changed.
+func restoreSpec(dst *v1beta1.DynaKubeSpec, restored *v1beta1.DynaKubeSpec) {
+	dst.APIRequestTimeoutSeconds = restored.APIRequestTimeoutSeconds
+	dst.MaintenanceWindow = restored.MaintenanceWindow
+	if dst.Proxy != nil && restored.Proxy != nil {
+		dst.Proxy.API = restored.Proxy.API
+		dst.Proxy.Communication = restored.Proxy.Communication
+		dst.Proxy.NoProxy = restored.Proxy.NoProxy
+	}
+
+	oneAgent, restoredOneAgent := &dst.OneAgent, &restored.OneAgent
+	switch {
+	case oneAgent.ClassicFullStack != nil && restoredOneAgent.ClassicFullStack != nil:
+		restoreHostInject(oneAgent.ClassicFullStack, restoredOneAgent.ClassicFullStack)
+	case oneAgent.HostMonitoring != nil && restoredOneAgent.HostMonitoring != nil:
+		restoreHostInject(oneAgent.HostMonitoring, restoredOneAgent.HostMonitoring)
+	case oneAgent.CloudNativeFullStack != nil && restoredOneAgent.CloudNativeFullStack != nil:
+		restoreHostInject(&oneAgent.CloudNativeFullStack.HostInjectSpec, &restoredOneAgent.CloudNativeFullStack.HostInjectSpec)
+		oneAgent.CloudNativeFullStack.CodeModulesVersion = restoredOneAgent.CloudNativeFullStack.CodeModulesVersion
+	case oneAgent.ApplicationMonitoring != nil && restoredOneAgent.ApplicationMonitoring != nil:
+		oneAgent.ApplicationMonitoring.CodeModulesVersion = restoredOneAgent.ApplicationMonitoring.CodeModulesVersion
+	}
+}
+
+func restoreHostInject(dst *v1beta1.HostInjectSpec, restored *v1beta1.HostInjectSpec) {
+	dst.VersionPolicy = restored.VersionPolicy
+	dst.PodTemplateOverride = restored.PodTemplateOverride
+	dst.HostConfig = restored.HostConfig
+	dst.HostConfigOverrides = restored.HostConfigOverrides
+}
+
+// restoreStatus sets the fields without a v1alpha1 equivalent from the restored status. Instances are only restored
+// for nodes which are still listed.
+func restoreStatus(dst *v1beta1.DynaKubeStatus, restored *v1beta1.DynaKubeStatus) {
+	dst.ActiveGate.PendingVersion = restored.ActiveGate.PendingVersion
+	dst.OneAgent.PendingVersion = restored.OneAgent.PendingVersion
+	dst.OneAgent.Architectures = restored.OneAgent.Architectures
+	dst.OneAgent.NodeGroups = restored.OneAgent.NodeGroups
+	dst.OneAgent.Canary = restored.OneAgent.Canary
+	dst.OneAgent.PreviousVersion = restored.OneAgent.PreviousVersion
+	dst.OneAgent.RolloutStartedTimestamp = restored.OneAgent.RolloutStartedTimestamp
+	dst.OneAgent.BlockedVersions = restored.OneAgent.BlockedVersions
+
+	for node, instance := range dst.OneAgent.Instances {
+		if restoredInstance, ok := restored.OneAgent.Instances[node]; ok {
+			restoredInstance.PodName = instance.PodName
+			restoredInstance.IPAddress = instance.IPAddress
+			dst.OneAgent.Instances[node] = restoredInstance
+		}
+	}
+}
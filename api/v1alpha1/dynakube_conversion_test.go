@@ -0,0 +1,292 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	testName     = "dynakube"
+	testAPIURL   = "https://test-tenant.dev.dynatracelabs.com/api"
+	testImage    = "registry/oneagent:latest"
+	testVersion  = "1.203.0.20201110-151542"
+	testPriority = "high-priority"
+)
+
+func newTestDynaKube() *DynaKube {
+	return &DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: "dynatrace"},
+		Spec: DynaKubeSpec{
+			APIURL: testAPIURL,
+			OneAgent: OneAgentSpec{
+				Version: testVersion,
+				Image:   testImage,
+			},
+		},
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	t.Run(`classicFullStack`, func(t *testing.T) {
+		src := newTestDynaKube()
+		src.Spec.ClassicFullStack = FullStackSpec{Enabled: true, PriorityClassName: testPriority}
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, src.ConvertTo(dst))
+
+		assert.Equal(t, testName, dst.Name)
+		assert.Equal(t, testAPIURL, dst.Spec.APIURL)
+		require.NotNil(t, dst.Spec.OneAgent.ClassicFullStack)
+		assert.Equal(t, testImage, dst.Spec.OneAgent.ClassicFullStack.Image)
+		assert.Equal(t, testVersion, dst.Spec.OneAgent.ClassicFullStack.Version)
+		assert.Equal(t, testPriority, dst.Spec.OneAgent.ClassicFullStack.PriorityClassName)
+		assert.Nil(t, dst.Spec.OneAgent.HostMonitoring)
+		assert.Nil(t, dst.Spec.OneAgent.CloudNativeFullStack)
+		assert.Nil(t, dst.Spec.OneAgent.ApplicationMonitoring)
+	})
+	t.Run(`classicFullStack takes precedence over other modes`, func(t *testing.T) {
+		src := newTestDynaKube()
+		src.Spec.ClassicFullStack = FullStackSpec{Enabled: true}
+		src.Spec.InfraMonitoring = FullStackSpec{Enabled: true}
+		src.Spec.CodeModules = CodeModulesSpec{Enabled: true}
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, src.ConvertTo(dst))
+
+		assert.True(t, dst.ClassicFullStackMode())
+		assert.False(t, dst.CloudNativeFullStackMode())
+	})
+	t.Run(`infraMonitoring and codeModules become cloudNativeFullStack`, func(t *testing.T) {
+		src := newTestDynaKube()
+		src.Spec.InfraMonitoring = FullStackSpec{Enabled: true, PriorityClassName: testPriority}
+		src.Spec.CodeModules = CodeModulesSpec{
+			Enabled: true,
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+		}
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, src.ConvertTo(dst))
+
+		require.NotNil(t, dst.Spec.OneAgent.CloudNativeFullStack)
+		assert.Equal(t, testImage, dst.Spec.OneAgent.CloudNativeFullStack.Image)
+		assert.Equal(t, testPriority, dst.Spec.OneAgent.CloudNativeFullStack.PriorityClassName)
+		assert.Equal(t, src.Spec.CodeModules.Resources, dst.Spec.OneAgent.CloudNativeFullStack.InitResources)
+	})
+	t.Run(`infraMonitoring becomes hostMonitoring`, func(t *testing.T) {
+		src := newTestDynaKube()
+		src.Spec.InfraMonitoring = FullStackSpec{Enabled: true}
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, src.ConvertTo(dst))
+
+		require.NotNil(t, dst.Spec.OneAgent.HostMonitoring)
+		assert.Equal(t, testVersion, dst.Spec.OneAgent.HostMonitoring.Version)
+	})
+	t.Run(`codeModules becomes applicationMonitoring`, func(t *testing.T) {
+		src := newTestDynaKube()
+		src.Spec.CodeModules = CodeModulesSpec{Enabled: true}
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, src.ConvertTo(dst))
+
+		assert.True(t, dst.ApplicationMonitoringMode())
+		assert.False(t, dst.NeedsOneAgent())
+	})
+	t.Run(`no mode enabled`, func(t *testing.T) {
+		src := newTestDynaKube()
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, src.ConvertTo(dst))
+
+		assert.Equal(t, v1beta1.OneAgentSpec{}, dst.Spec.OneAgent)
+	})
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	modes := map[string]func(dk *DynaKube){
+		"classicFullStack": func(dk *DynaKube) {
+			dk.Spec.ClassicFullStack = FullStackSpec{Enabled: true, Args: []string{"--set-host-group=test"}}
+		},
+		"hostMonitoring": func(dk *DynaKube) {
+			dk.Spec.InfraMonitoring = FullStackSpec{Enabled: true, UseImmutableImage: true}
+		},
+		"cloudNativeFullStack": func(dk *DynaKube) {
+			dk.Spec.InfraMonitoring = FullStackSpec{Enabled: true}
+			dk.Spec.CodeModules = CodeModulesSpec{Enabled: true}
+		},
+		"applicationMonitoring": func(dk *DynaKube) {
+			dk.Spec.OneAgent = OneAgentSpec{}
+			dk.Spec.CodeModules = CodeModulesSpec{Enabled: true}
+		},
+	}
+
+	for name, setMode := range modes {
+		t.Run(name, func(t *testing.T) {
+			src := newTestDynaKube()
+			setMode(src)
+			src.Spec.RoutingSpec.Enabled = true
+			src.Status.Phase = Running
+			src.Status.OneAgent.Instances = map[string]OneAgentInstance{"node": {PodName: "pod"}}
+
+			hub := &v1beta1.DynaKube{}
+			require.NoError(t, src.ConvertTo(hub))
+
+			dst := &DynaKube{}
+			require.NoError(t, dst.ConvertFrom(hub))
+
+			assert.Equal(t, src.Spec, dst.Spec)
+			assert.Equal(t, src.Status, dst.Status)
+		})
+	}
+}
+
+func newTestHub() *v1beta1.DynaKube {
+	timeout := uint16(60)
+	autoUpdate := false
+	ts := metav1.NewTime(time.Unix(1620000000, 0))
+	resources := corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}
+	hostInject := v1beta1.HostInjectSpec{
+		Version:           testVersion,
+		Image:             testImage,
+		AutoUpdate:        &autoUpdate,
+		NodeSelector:      map[string]string{"os": "linux"},
+		PriorityClassName: testPriority,
+		Args:              []string{"--set-app-log-content-access=true"},
+		VersionPolicy: &v1beta1.VersionPolicySpec{
+			Type:      v1beta1.VersionPolicyLatest,
+			Version:   ">=1.210",
+			DelayDays: 3,
+			Canary:    &v1beta1.CanarySpec{NodeSelector: map[string]string{"canary": "true"}},
+		},
+		PodTemplateOverride: &runtime.RawExtension{Raw: []byte(`{"metadata":{"annotations":{"team":"platform"}}}`)},
+		HostConfig: v1beta1.HostConfig{
+			HostGroup:      "cluster",
+			HostTags:       []string{"env=prod"},
+			HostProperties: map[string]string{"owner": "platform"},
+			MonitoringMode: v1beta1.MonitoringModeInfraOnly,
+		},
+		HostConfigOverrides: []v1beta1.HostConfigOverride{{
+			Name:         "gpu",
+			NodeSelector: map[string]string{"gpu": "true"},
+			Resources:    &resources,
+			HostConfig:   v1beta1.HostConfig{HostGroup: "gpu"},
+		}},
+	}
+
+	return &v1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: "dynatrace", Annotations: map[string]string{"team": "platform"}},
+		Spec: v1beta1.DynaKubeSpec{
+			APIURL:                   testAPIURL,
+			Tokens:                   "tokens",
+			NetworkZone:              "zone",
+			APIRequestTimeoutSeconds: &timeout,
+			MaintenanceWindow: &v1beta1.MaintenanceWindowSpec{
+				Schedule: "0 2 * * 6",
+				Duration: metav1.Duration{Duration: 2 * time.Hour},
+			},
+			Proxy: &v1beta1.DynaKubeProxy{
+				Value:         "http://proxy:3128",
+				API:           &v1beta1.DynaKubeProxySource{Value: "http://api-proxy:3128"},
+				Communication: &v1beta1.DynaKubeProxySource{ValueFrom: "proxy-secret"},
+				NoProxy:       []string{".cluster.local"},
+			},
+			OneAgent: v1beta1.OneAgentSpec{ClassicFullStack: &hostInject},
+			RoutingSpec: v1beta1.RoutingSpec{CapabilityProperties: v1beta1.CapabilityProperties{
+				Enabled: true,
+				Group:   "routing",
+			}},
+		},
+		Status: v1beta1.DynaKubeStatus{
+			Phase:          v1beta1.Running,
+			KubeSystemUUID: "uuid",
+			ActiveGate: v1beta1.ActiveGateStatus{VersionStatus: v1beta1.VersionStatus{
+				Version:        "1.215.0",
+				PendingVersion: "1.217.0",
+			}},
+			OneAgent: v1beta1.OneAgentStatus{
+				VersionStatus: v1beta1.VersionStatus{
+					Version:                  testVersion,
+					PendingVersion:           "1.205.0.20210101-000000",
+					LastUpdateProbeTimestamp: &ts,
+				},
+				Instances: map[string]v1beta1.OneAgentInstance{
+					"node1": {
+						PodName:            "pod1",
+						IPAddress:          "1.2.3.4",
+						Architecture:       "amd64",
+						NodeGroup:          "gpu",
+						Version:            testVersion,
+						PodPhase:           corev1.PodRunning,
+						Ready:              true,
+						RestartCount:       2,
+						LastTransitionTime: &ts,
+						EntityID:           "HOST-42",
+						VisibleInDynatrace: true,
+					},
+				},
+				Architectures:           map[string]v1beta1.OneAgentArchitectureStatus{"amd64": {DaemonSet: testName + "-classic", Pods: 1, ReadyPods: 1}},
+				NodeGroups:              map[string]v1beta1.OneAgentNodeGroupStatus{"gpu": {DaemonSets: []string{testName + "-classic-gpu"}, Pods: 1, ReadyPods: 1}},
+				Canary:                  &v1beta1.CanaryStatus{Version: testVersion, StartedTimestamp: ts},
+				PreviousVersion:         "1.201.0.20201001-000000",
+				RolloutStartedTimestamp: &ts,
+				BlockedVersions:         []string{"1.202.0.20201010-000000"},
+			},
+		},
+	}
+}
+
+func TestConvertHubRoundTrip(t *testing.T) {
+	t.Run(`classicFullStack`, func(t *testing.T) {
+		src := newTestHub()
+
+		spoke := &DynaKube{}
+		require.NoError(t, spoke.ConvertFrom(src.DeepCopy()))
+		assert.Contains(t, spoke.Annotations, annotationHubData)
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, spoke.ConvertTo(dst))
+
+		assert.Equal(t, src, dst)
+	})
+	t.Run(`cloudNativeFullStack`, func(t *testing.T) {
+		src := newTestHub()
+		src.Spec.OneAgent = v1beta1.OneAgentSpec{CloudNativeFullStack: &v1beta1.CloudNativeFullStackSpec{
+			HostInjectSpec:   *src.Spec.OneAgent.ClassicFullStack,
+			AppInjectionSpec: v1beta1.AppInjectionSpec{CodeModulesVersion: testVersion},
+		}}
+
+		spoke := &DynaKube{}
+		require.NoError(t, spoke.ConvertFrom(src.DeepCopy()))
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, spoke.ConvertTo(dst))
+
+		assert.Equal(t, src, dst)
+	})
+	t.Run(`fields of v1alpha1 updated by clients are kept`, func(t *testing.T) {
+		src := newTestHub()
+
+		spoke := &DynaKube{}
+		require.NoError(t, spoke.ConvertFrom(src))
+		spoke.Spec.ClassicFullStack.PriorityClassName = "low-priority"
+		delete(spoke.Status.OneAgent.Instances, "node1")
+
+		dst := &v1beta1.DynaKube{}
+		require.NoError(t, spoke.ConvertTo(dst))
+
+		assert.Equal(t, "low-priority", dst.Spec.OneAgent.ClassicFullStack.PriorityClassName)
+		assert.Equal(t, src.Spec.OneAgent.ClassicFullStack.HostConfigOverrides, dst.Spec.OneAgent.ClassicFullStack.HostConfigOverrides)
+		assert.Empty(t, dst.Status.OneAgent.Instances)
+		assert.Equal(t, map[string]string{"team": "platform"}, src.Annotations)
+	})
+}
@@ -136,22 +136,6 @@ func (in *CodeModulesSpec) DeepCopy() *CodeModulesSpec {
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataIngestSpec) DeepCopyInto(out *DataIngestSpec) {
-	*out = *in
-	in.CapabilityProperties.DeepCopyInto(&out.CapabilityProperties)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataIngestSpec.
-func (in *DataIngestSpec) DeepCopy() *DataIngestSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(DataIngestSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommunicationHostStatus) DeepCopyInto(out *CommunicationHostStatus) {
 	*out = *in
@@ -187,6 +171,22 @@ func (in *ConnectionInfoStatus) DeepCopy() *ConnectionInfoStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataIngestSpec) DeepCopyInto(out *DataIngestSpec) {
+	*out = *in
+	in.CapabilityProperties.DeepCopyInto(&out.CapabilityProperties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataIngestSpec.
+func (in *DataIngestSpec) DeepCopy() *DataIngestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataIngestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DynaKube) DeepCopyInto(out *DynaKube) {
 	*out = *in
@@ -0,0 +1,88 @@
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types for the components deployed for a DynaKube
+const (
+	// OneAgentReadyConditionType identifies the condition telling whether all OneAgent pods are ready
+	OneAgentReadyConditionType string = "OneAgentReady"
+
+	// WebhookReadyConditionType identifies the condition telling whether the webhook injecting code modules is ready
+	WebhookReadyConditionType string = "WebhookReady"
+
+	// CSIProvisionerReadyConditionType identifies the condition telling whether the CSI driver providing code modules
+	// is ready on all nodes
+	CSIProvisionerReadyConditionType string = "CSIProvisionerReady"
+
+	// IstioConfiguredConditionType identifies the condition telling whether the Istio objects for the communication with
+	// Dynatrace are up-to-date
+	IstioConfiguredConditionType string = "IstioConfigured"
+
+	// PullSecretReadyConditionType identifies the condition telling whether the pull secret for the Dynatrace registry is
+	// up-to-date
+	PullSecretReadyConditionType string = "PullSecretReady"
+
+	activeGateReadyConditionTypePrefix = "ActiveGateReady-"
+)
+
+// Possible reasons for the component conditions
+const (
+	// ReasonReady is set when the component is deployed and ready
+	ReasonReady string = "Ready"
+
+	// ReasonRolloutInProgress is set when the component is deployed, but not all of its pods are ready yet
+	ReasonRolloutInProgress string = "RolloutInProgress"
+
+	// ReasonNotDeployed is set when the objects of the component can't be found
+	ReasonNotDeployed string = "NotDeployed"
+
+	// ReasonReconcileFailed is set when the objects of the component couldn't be reconciled
+	ReasonReconcileFailed string = "ReconcileFailed"
+)
+
+// ActiveGateReadyConditionType returns the type of the condition telling whether the StatefulSet of the ActiveGate
+// capability with the given module name is ready.
+func ActiveGateReadyConditionType(moduleName string) string {
+	return activeGateReadyConditionTypePrefix + moduleName
+}
+
+// SetCondition adds or updates the condition and returns true if it has changed.
+func (dk *DynaKubeStatus) SetCondition(condition metav1.Condition) bool {
+	c := meta.FindStatusCondition(dk.Conditions, condition.Type)
+	if c != nil && c.Reason == condition.Reason && c.Message == condition.Message && c.Status == condition.Status {
+		return false
+	}
+
+	meta.SetStatusCondition(&dk.Conditions, condition)
+	return true
+}
+
+// RemoveCondition removes the condition of the given type and returns true if it was set.
+func (dk *DynaKubeStatus) RemoveCondition(conditionType string) bool {
+	if meta.FindStatusCondition(dk.Conditions, conditionType) == nil {
+		return false
+	}
+
+	meta.RemoveStatusCondition(&dk.Conditions, conditionType)
+	return true
+}
+
+// PhaseFromConditions determines the phase of the DynaKube from its conditions. The phase is Error if any condition
+// failed for another reason than a component still being rolled out, Deploying if any condition is not met yet, and
+// Running otherwise.
+func (dk *DynaKubeStatus) PhaseFromConditions() DynaKubePhaseType {
+	phase := Running
+	for _, c := range dk.Conditions {
+		if c.Status == metav1.ConditionTrue {
+			continue
+		}
+		if c.Status == metav1.ConditionFalse && c.Reason != ReasonRolloutInProgress && c.Reason != ReasonNotDeployed {
+			return Error
+		}
+		phase = Deploying
+	}
+	return phase
+}
@@ -0,0 +1,69 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCondition(t *testing.T) {
+	status := DynaKubeStatus{}
+	condition := metav1.Condition{
+		Type:    OneAgentReadyConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonRolloutInProgress,
+		Message: "1 of 2 OneAgent pods are ready",
+	}
+
+	assert.True(t, status.SetCondition(condition))
+	assert.False(t, status.SetCondition(condition))
+
+	condition.Message = "0 of 2 OneAgent pods are ready"
+	assert.True(t, status.SetCondition(condition))
+	assert.Len(t, status.Conditions, 1)
+
+	assert.True(t, status.RemoveCondition(OneAgentReadyConditionType))
+	assert.False(t, status.RemoveCondition(OneAgentReadyConditionType))
+	assert.Empty(t, status.Conditions)
+}
+
+func TestPhaseFromConditions(t *testing.T) {
+	newStatus := func(conditions ...metav1.Condition) DynaKubeStatus {
+		status := DynaKubeStatus{}
+		for _, c := range conditions {
+			status.SetCondition(c)
+		}
+		return status
+	}
+	ready := metav1.Condition{Type: APITokenConditionType, Status: metav1.ConditionTrue, Reason: ReasonTokenReady}
+	rollingOut := metav1.Condition{Type: OneAgentReadyConditionType, Status: metav1.ConditionFalse, Reason: ReasonRolloutInProgress}
+	notDeployed := metav1.Condition{Type: WebhookReadyConditionType, Status: metav1.ConditionFalse, Reason: ReasonNotDeployed}
+	failed := metav1.Condition{Type: PullSecretReadyConditionType, Status: metav1.ConditionFalse, Reason: ReasonReconcileFailed}
+	unknown := metav1.Condition{Type: IstioConfiguredConditionType, Status: metav1.ConditionUnknown, Reason: "Unknown"}
+
+	t.Run(`running if all conditions are met`, func(t *testing.T) {
+		status := newStatus(ready)
+		assert.Equal(t, Running, status.PhaseFromConditions())
+
+		status = newStatus()
+		assert.Equal(t, Running, status.PhaseFromConditions())
+	})
+	t.Run(`deploying while components are rolled out`, func(t *testing.T) {
+		status := newStatus(ready, rollingOut)
+		assert.Equal(t, Deploying, status.PhaseFromConditions())
+
+		status = newStatus(ready, notDeployed)
+		assert.Equal(t, Deploying, status.PhaseFromConditions())
+
+		status = newStatus(ready, unknown)
+		assert.Equal(t, Deploying, status.PhaseFromConditions())
+	})
+	t.Run(`error if any condition failed`, func(t *testing.T) {
+		status := newStatus(ready, rollingOut, failed)
+		assert.Equal(t, Error, status.PhaseFromConditions())
+
+		status = newStatus(metav1.Condition{Type: PaaSTokenConditionType, Status: metav1.ConditionFalse, Reason: ReasonTokenUnauthorized})
+		assert.Equal(t, Error, status.PhaseFromConditions())
+	})
+}
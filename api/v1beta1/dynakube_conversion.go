@@ -0,0 +1,4 @@
+package v1beta1
+
+// Hub marks v1beta1 as the version all other DynaKube versions are converted from and to.
+func (*DynaKube) Hub() {}
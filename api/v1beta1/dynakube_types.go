@@ -0,0 +1,693 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	OperatorName = "dynatrace-operator"
+)
+
+// DynaKubeSpec defines the desired state of DynaKube
+// +k8s:openapi-gen=true
+type DynaKubeSpec struct {
+	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+
+	// Location of the Dynatrace API to connect to, including your specific environment ID
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="API URL",order=1,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	APIURL string `json:"apiUrl"`
+
+	// Credentials for the DynaKube to connect back to Dynatrace.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="API and PaaS Tokens",order=2,xDescriptors="urn:alm:descriptor:io.kubernetes:Secret"
+	Tokens string `json:"tokens,omitempty"`
+
+	// Optional: Pull secret for your private registry
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom PullSecret",order=8,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:Secret"}
+	CustomPullSecret string `json:"customPullSecret,omitempty"`
+
+	// Disable certificate validation checks for installer download and API communication
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Skip Certificate Check",order=3,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	SkipCertCheck bool `json:"skipCertCheck,omitempty"`
+
+	// Optional: Set custom proxy settings either directly or from a secret with the field 'proxy'
+	Proxy *DynaKubeProxy `json:"proxy,omitempty"`
+
+	// Optional: Adds custom RootCAs from a configmap
+	// This property only affects certificates used to communicate with the Dynatrace API.
+	// The property is not applied to the ActiveGate
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Trusted CAs",order=6,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ConfigMap"}
+	TrustedCAs string `json:"trustedCAs,omitempty"`
+
+	// Optional: Sets Network Zone for OneAgent and ActiveGate pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Network Zone",order=7,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	NetworkZone string `json:"networkZone,omitempty"`
+
+	// If enabled, Istio on the cluster will be configured automatically to allow access to the Dynatrace environment
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enable Istio automatic management",order=9,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	EnableIstio bool `json:"enableIstio,omitempty"`
+
+	// Optional: Timeout in seconds for requests to the Dynatrace API. Defaults to 30 seconds.
+	// OneAgent downloads are not affected by this timeout.
+	// +kubebuilder:validation:Minimum=1
+	APIRequestTimeoutSeconds *uint16 `json:"apiRequestTimeoutSeconds,omitempty"`
+
+	// Optional: Restricts rolling out new OneAgent and ActiveGate versions to maintenance windows. Versions found outside
+	// of a maintenance window are kept as pending in the status until the next one starts.
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
+	// General configuration about ActiveGate instances
+	ActiveGate ActiveGateSpec `json:"activeGate,omitempty"`
+
+	// General configuration about OneAgent instances
+	// Only one of the OneAgent modes can be configured at a time
+	OneAgent OneAgentSpec `json:"oneAgent,omitempty"`
+
+	//  Configuration for Routing
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Routing"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	RoutingSpec RoutingSpec `json:"routing,omitempty"`
+
+	//  Configuration for Data Ingest
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Data Ingest"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	DataIngestSpec DataIngestSpec `json:"dataIngest,omitempty"`
+
+	//  Configuration for Kubernetes Monitoring
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Kubernetes Monitoring"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	KubernetesMonitoringSpec KubernetesMonitoringSpec `json:"kubernetesMonitoring,omitempty"`
+}
+
+type MaintenanceWindowSpec struct {
+	// Cron expression for the start of the maintenance windows in UTC, with the fields minute, hour, day of month, month
+	// and day of week
+	// Example: "0 2 * * 6" for every Saturday at 02:00
+	Schedule string `json:"schedule"`
+
+	// Duration of each maintenance window
+	// Example: 2h30m
+	Duration metav1.Duration `json:"duration"`
+}
+
+type ActiveGateSpec struct {
+	// Optional: the ActiveGate container image. Defaults to the latest ActiveGate image provided by the Docker Registry
+	// implementation from the Dynatrace environment set as API URL.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image",order=10,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Image string `json:"image,omitempty"`
+
+	// Disable automatic restarts of OneAgent pods in case a new version is available
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Automatically update Agent"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:booleanSwitch"
+	AutoUpdate *bool `json:"autoUpdate,omitempty"`
+}
+
+// OneAgentSpec holds the configuration of the OneAgent monitoring mode.
+// The modes are mutually exclusive, at most one of them can be set.
+// +kubebuilder:validation:MaxProperties=1
+type OneAgentSpec struct {
+	// Optional: Deploys OneAgent with full-stack monitoring on the nodes, instrumenting applications from the host
+	ClassicFullStack *HostInjectSpec `json:"classicFullStack,omitempty"`
+
+	// Optional: Deploys OneAgent with infrastructure monitoring on the nodes
+	HostMonitoring *HostInjectSpec `json:"hostMonitoring,omitempty"`
+
+	// Optional: Deploys OneAgent with infrastructure monitoring on the nodes and injects code modules into pods
+	CloudNativeFullStack *CloudNativeFullStackSpec `json:"cloudNativeFullStack,omitempty"`
+
+	// Optional: Injects code modules into pods without deploying OneAgent on the nodes
+	ApplicationMonitoring *ApplicationMonitoringSpec `json:"applicationMonitoring,omitempty"`
+}
+
+type CloudNativeFullStackSpec struct {
+	HostInjectSpec   `json:",inline"`
+	AppInjectionSpec `json:",inline"`
+}
+
+type ApplicationMonitoringSpec struct {
+	AppInjectionSpec `json:",inline"`
+}
+
+type AppInjectionSpec struct {
+	// Optional: define resources requests and limits for the initContainer
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Init container resource requirements",order=14,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	InitResources corev1.ResourceRequirements `json:"initResources,omitempty"`
+
+	// Optional: use OneAgent binaries from volume
+	Volume corev1.VolumeSource `json:"volume,omitempty"`
+
+	// Optional: the version of the code modules provisioned by the CSI driver
+	// Defaults to latest
+	// Example: 1.213.0.20210301-123456
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Code modules version",order=15,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	CodeModulesVersion string `json:"codeModulesVersion,omitempty"`
+}
+
+type HostInjectSpec struct {
+	// Optional: If specified, indicates the OneAgent version to use
+	// Defaults to latest
+	// Example: {major.minor.release} - 1.200.0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OneAgent version",order=11,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Version string `json:"version,omitempty"`
+
+	// Optional: the Dynatrace installer container image
+	// Defaults to docker.io/dynatrace/oneagent:latest for Kubernetes and to registry.connect.redhat.com/dynatrace/oneagent for OpenShift
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image",order=12,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Image string `json:"image,omitempty"`
+
+	// Disable automatic restarts of OneAgent pods in case a new version is available
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Automatically update Agent",order=13,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	AutoUpdate *bool `json:"autoUpdate,omitempty"`
+
+	// Node selector to control the selection of nodes (optional)
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",order=17,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:Node"
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Optional: set tolerations for the OneAgent pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations",order=18,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Optional: Defines the time to wait until OneAgent pod is ready after update - default 300 sec
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Wait seconds until ready",order=19,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:number"}
+	WaitReadySeconds *uint16 `json:"waitReadySeconds,omitempty"`
+
+	// Optional: define resources requests and limits for single pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",order=20,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Optional: Arguments to the OneAgent installer
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OneAgent installer arguments",order=21,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +listType=set
+	Args []string `json:"args,omitempty"`
+
+	// Optional: List of environment variables to set for the installer
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OneAgent environment variable installer arguments",order=22,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Optional: If specified, indicates the pod's priority. Name must be defined by creating a PriorityClass object with that
+	// name. If not specified the setting will be removed from the DaemonSet.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority Class name",order=23,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:PriorityClass"}
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Optional: Sets DNS Policy for the OneAgent pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="DNS Policy",order=24,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// Optional: set custom Service Account Name used with OneAgent pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service Account name",order=25,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ServiceAccount"}
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Optional: Adds additional labels for the OneAgent pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels",order=26,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Optional: Runs the OneAgent Pods as unprivileged (Early Adopter)
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Use unprivileged mode",order=27,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:booleanSwitch"
+	UseUnprivilegedMode *bool `json:"useUnprivilegedMode,omitempty"`
+
+	// Defines if you want to use the immutable image or the installer
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Use immutable image",order=28,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:booleanSwitch"
+	UseImmutableImage bool `json:"useImmutableImage,omitempty"`
+
+	// Optional: Defines which OneAgent version is rolled out and whether it is rolled out to canary nodes first.
+	// Only used with the installer, defaults to following the latest version
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Version policy",order=29,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	VersionPolicy *VersionPolicySpec `json:"versionPolicy,omitempty"`
+
+	// Optional: Pod template which is merged into the OneAgent pod template built by the Operator, using the rules of a
+	// strategic merge patch. Allows to add annotations, sidecars or volumes and to tune the security context or probes,
+	// e.g. of the dynatrace-oneagent container. Labels selecting the OneAgent pods can't be overridden
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod template override",order=30,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	PodTemplateOverride *runtime.RawExtension `json:"podTemplateOverride,omitempty"`
+
+	HostConfig `json:",inline"`
+
+	// Optional: Configurations for the nodes matching the node selectors, e.g. to tolerate the taints of a GPU node pool
+	// or to use other resources or host groups per node pool. OneAgent is deployed to the nodes of each override by a
+	// separate DaemonSet. Nodes belong to the first override matching them, other nodes to the DaemonSet configured
+	// above. Fields set on the override replace the ones above, labels and host properties are merged
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host configuration overrides",order=35,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	HostConfigOverrides []HostConfigOverride `json:"hostConfigOverrides,omitempty"`
+}
+
+// OneAgentMonitoringMode is the monitoring mode of OneAgent on a host.
+// +kubebuilder:validation:Enum=fullstack;infra-only;discovery
+type OneAgentMonitoringMode string
+
+const (
+	MonitoringModeFullStack OneAgentMonitoringMode = "fullstack"
+	MonitoringModeInfraOnly OneAgentMonitoringMode = "infra-only"
+	MonitoringModeDiscovery OneAgentMonitoringMode = "discovery"
+)
+
+// HostConfig is the configuration of the hosts OneAgent is deployed to.
+type HostConfig struct {
+	// Optional: Host group the hosts are assigned to
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host group",order=31,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	HostGroup string `json:"hostGroup,omitempty"`
+
+	// Optional: Tags of the hosts, either a key or key=value
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host tags",order=32,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	HostTags []string `json:"hostTags,omitempty"`
+
+	// Optional: Custom properties of the hosts
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Host properties",order=33,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	HostProperties map[string]string `json:"hostProperties,omitempty"`
+
+	// Optional: Monitoring mode of OneAgent, one of fullstack, infra-only or discovery
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Monitoring mode",order=34,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	MonitoringMode OneAgentMonitoringMode `json:"monitoringMode,omitempty"`
+}
+
+// HostConfigOverride is the configuration of OneAgent for the nodes of a node pool.
+type HostConfigOverride struct {
+	// Name of the node pool, a separate OneAgent DaemonSet named after it is deployed to its nodes
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Nodes matching all labels of the node selector belong to the node pool
+	// +kubebuilder:validation:Required
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// Optional: Tolerations of the OneAgent pods on the nodes of the node pool
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Optional: Resource requirements of the OneAgent pods on the nodes of the node pool
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Optional: Priority class of the OneAgent pods on the nodes of the node pool
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Optional: Additional labels of the OneAgent pods on the nodes of the node pool
+	Labels map[string]string `json:"labels,omitempty"`
+
+	HostConfig `json:",inline"`
+}
+
+// VersionPolicyType is the strategy used to select the OneAgent version to roll out.
+type VersionPolicyType string
+
+const (
+	// VersionPolicyLatest follows the latest OneAgent version available for the environment, optionally restricted to the
+	// version range given by VersionPolicySpec.Version
+	VersionPolicyLatest VersionPolicyType = "Latest"
+
+	// VersionPolicyPinned keeps OneAgent on the version given by VersionPolicySpec.Version
+	VersionPolicyPinned VersionPolicyType = "Pinned"
+
+	// VersionPolicyLatestMinor follows the latest minor version of the major version given by VersionPolicySpec.Version
+	VersionPolicyLatestMinor VersionPolicyType = "LatestMinor"
+)
+
+type VersionPolicySpec struct {
+	// Optional: Strategy used to select the OneAgent version, one of Latest, Pinned or LatestMinor
+	// Defaults to Latest
+	// +kubebuilder:validation:Enum=Latest;Pinned;LatestMinor
+	Type VersionPolicyType `json:"type,omitempty"`
+
+	// Optional: The version for the Pinned policy, either exact, e.g. 1.215.162.20210507-082014, or short, e.g. 1.215,
+	// to pin the latest build starting with it, the major version for the LatestMinor policy, e.g. 1, or a version range
+	// restricting the versions of the Latest policy, e.g. >=1.210 <1.220
+	Version string `json:"version,omitempty"`
+
+	// Optional: Number of days which have to pass after a version has been released until it is rolled out
+	// Not used with the Pinned policy
+	// +kubebuilder:validation:Minimum=0
+	DelayDays int32 `json:"delayDays,omitempty"`
+
+	// Optional: If set, new versions are rolled out to the canary nodes first and only to the remaining nodes once
+	// all OneAgent pods on the canary nodes are ready
+	Canary *CanarySpec `json:"canary,omitempty"`
+}
+
+type CanarySpec struct {
+	// Node selector for the nodes to roll out new OneAgent versions to first. Nodes which don't get a OneAgent pod
+	// within waitReadySeconds, e.g. because of taints which aren't tolerated, are ignored
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+type DataIngestSpec struct {
+	CapabilityProperties `json:",inline"`
+}
+
+type RoutingSpec struct {
+	CapabilityProperties `json:",inline"`
+}
+
+type KubernetesMonitoringSpec struct {
+	CapabilityProperties `json:",inline"`
+}
+
+// CapabilityProperties is a struct which can be embedded by ActiveGate capabilities
+// Such as KubernetesMonitoring or Routing
+// It encapsulates common properties
+type CapabilityProperties struct {
+	// Enables Capability
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Capability",order=29,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:booleanSwitch"
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Amount of replicas for your DynaKube
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Replicas",order=30,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podCount"
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Optional: Set activation group for ActiveGate
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Activation group",order=31,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Group string `json:"group,omitempty"`
+
+	// Optional: Add a custom properties file by providing it as a value or reference it from a secret
+	// If referenced from a secret, make sure the key is called 'customProperties'
+	CustomProperties *DynaKubeValueSource `json:"customProperties,omitempty"`
+
+	// Optional: define resources requests and limits for single ActiveGate pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",order=34,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Optional: Node selector to control the selection of nodes
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",order=35,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:Node"
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Optional: set tolerations for the ActiveGatePods pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations",order=36,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Optional: Adds additional labels for the ActiveGate pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels",order=37,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Optional: Adds additional arguments for the ActiveGate instances
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Arguments",order=38,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	Args []string `json:"args,omitempty"`
+
+	// Optional: List of environment variables to set for the ActiveGate
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Environment variables",order=39,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Environment variables"
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:advanced,urn:alm:descriptor:com.tectonic.ui:text"
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Optional: set custom Service Account Name used with ActiveGate pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service Account name",order=40,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ServiceAccount"}
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+type DynaKubeValueSource struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom properties value",order=32,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Value string `json:"value,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom properties secret",order=33,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:Secret"}
+	ValueFrom string `json:"valueFrom,omitempty"`
+}
+
+type DynaKubeProxy struct {
+	Value     string `json:"value,omitempty"`
+	ValueFrom string `json:"valueFrom,omitempty"`
+
+	// Optional: Proxy used for requests to the Dynatrace API, e.g. by the Operator and to download OneAgent.
+	// Defaults to the proxy given by value or valueFrom
+	API *DynaKubeProxySource `json:"api,omitempty"`
+
+	// Optional: Proxy used by OneAgent and ActiveGate to connect to the Dynatrace communication endpoints.
+	// Defaults to the proxy given by value or valueFrom
+	Communication *DynaKubeProxySource `json:"communication,omitempty"`
+
+	// Optional: Hosts and domains which are connected to without proxy, e.g. .cluster.local
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// DynaKubeProxySource is a proxy URL given either directly or by a secret with the field 'proxy'
+type DynaKubeProxySource struct {
+	Value     string `json:"value,omitempty"`
+	ValueFrom string `json:"valueFrom,omitempty"`
+}
+
+// DynaKubeStatus defines the observed state of DynaKube
+// +k8s:openapi-gen=true
+type DynaKubeStatus struct {
+	// Defines the current state (Running, Updating, Error, ...)
+	Phase DynaKubePhaseType `json:"phase,omitempty"`
+
+	// UpdatedTimestamp indicates when the instance was last updated
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Last Updated"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:text"
+	UpdatedTimestamp metav1.Time `json:"updatedTimestamp,omitempty"`
+
+	// LastAPITokenProbeTimestamp tracks when the last request for the API token validity was sent
+	LastAPITokenProbeTimestamp *metav1.Time `json:"lastAPITokenProbeTimestamp,omitempty"`
+
+	// LastPaaSTokenProbeTimestamp tracks when the last request for the PaaS token validity was sent
+	LastPaaSTokenProbeTimestamp *metav1.Time `json:"lastPaaSTokenProbeTimestamp,omitempty"`
+
+	// Credentials used to connect back to Dynatrace.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="API and PaaS Tokens"
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:text"
+	Tokens string `json:"tokens,omitempty"`
+
+	// LastClusterVersionProbeTimestamp indicates when the cluster's version was last checked
+	LastClusterVersionProbeTimestamp *metav1.Time `json:"lastClusterVersionProbeTimestamp,omitempty"`
+
+	// KubeSystemUUID contains the UUID of the current Kubernetes cluster
+	KubeSystemUUID string `json:"kubeSystemUUID,omitempty"`
+
+	// ConnectionInfo caches information about the tenant and its communication hosts
+	ConnectionInfo ConnectionInfoStatus `json:"connectionInfo,omitempty"`
+
+	// CommunicationHostForClient caches a communication host specific to the api url.
+	CommunicationHostForClient CommunicationHostStatus `json:"communicationHostForClient,omitempty"`
+
+	// LatestAgentVersionUnixDefault caches the current agent version for unix and the default installer which is configured for the environment
+	LatestAgentVersionUnixDefault string `json:"latestAgentVersionUnixDefault,omitempty"`
+
+	// LatestAgentVersionUnixDefault caches the current agent version for unix and the PaaS installer which is configured for the environment
+	LatestAgentVersionUnixPaas string `json:"latestAgentVersionUnixPaas,omitempty"`
+
+	// Conditions includes status about the current state of the instance
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	ActiveGate ActiveGateStatus `json:"activeGate,omitempty"`
+
+	OneAgent OneAgentStatus `json:"oneAgent,omitempty"`
+}
+
+type ConnectionInfoStatus struct {
+	CommunicationHosts []CommunicationHostStatus `json:"communicationHosts,omitempty"`
+	TenantUUID         string                    `json:"tenantUUID,omitempty"`
+}
+
+type CommunicationHostStatus struct {
+	Protocol string `json:"protocol,omitempty"`
+	Host     string `json:"host,omitempty"`
+	Port     uint32 `json:"port,omitempty"`
+}
+
+type VersionStatus struct {
+	// ImageHash contains the last image hash seen.
+	ImageHash string `json:"imageHash,omitempty"`
+
+	// Version contains the version to be deployed.
+	Version string `json:"version,omitempty"`
+
+	// PendingVersion contains a version found outside of a maintenance window, which is rolled out in the next one.
+	PendingVersion string `json:"pendingVersion,omitempty"`
+
+	// LastUpdateProbeTimestamp defines the last timestamp when the querying for updates have been done
+	LastUpdateProbeTimestamp *metav1.Time `json:"lastUpdateProbeTimestamp,omitempty"`
+}
+
+type ActiveGateStatus struct {
+	VersionStatus `json:",inline"`
+}
+
+type OneAgentStatus struct {
+	VersionStatus `json:",inline"`
+
+	// UseImmutableImage is set when an immutable image is currently in use
+	UseImmutableImage bool `json:"useImmutableImage,omitempty"`
+
+	Instances map[string]OneAgentInstance `json:"instances,omitempty"`
+
+	// Architectures contains the status of the OneAgent pods per node architecture
+	Architectures map[string]OneAgentArchitectureStatus `json:"architectures,omitempty"`
+
+	// NodeGroups contains the status of the OneAgent pods per node pool of the host configuration overrides. Nodes not
+	// belonging to a node pool aren't included
+	NodeGroups map[string]OneAgentNodeGroupStatus `json:"nodeGroups,omitempty"`
+
+	// LastHostsRequestTimestamp indicates the last timestamp the Operator queried for hosts
+	LastHostsRequestTimestamp *metav1.Time `json:"lastHostsRequestTimestamp,omitempty"`
+
+	// Canary is set while a new version is only rolled out to the canary nodes
+	Canary *CanaryStatus `json:"canary,omitempty"`
+
+	// PreviousVersion is the version rolled out before the current one, which is restored if the OneAgent pods don't
+	// become ready with the current version
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	// RolloutStartedTimestamp indicates when the rollout of the current version started, unset once all OneAgent pods
+	// are ready
+	RolloutStartedTimestamp *metav1.Time `json:"rolloutStartedTimestamp,omitempty"`
+
+	// BlockedVersions lists the versions which have been rolled back because the OneAgent pods didn't become ready, and
+	// won't be rolled out again. To allow rolling them out again, set the annotation
+	// alpha.operator.dynatrace.com/feature-unblock-oneagent-versions on the DynaKube to a comma-separated list of
+	// versions, or to "all". The Operator removes the annotation once the versions have been unblocked.
+	BlockedVersions []string `json:"blockedVersions,omitempty"`
+}
+
+type CanaryStatus struct {
+	// Version is the version being rolled out to the canary nodes
+	Version string `json:"version"`
+
+	// StartedTimestamp indicates when the rollout to the canary nodes started
+	StartedTimestamp metav1.Time `json:"startedTimestamp"`
+}
+
+type OneAgentArchitectureStatus struct {
+	// DaemonSet is the name of the DaemonSet deploying OneAgent to the nodes with this architecture not belonging to a
+	// node group
+	DaemonSet string `json:"daemonSet,omitempty"`
+
+	// Pods is the number of OneAgent pods on nodes with this architecture
+	Pods int32 `json:"pods"`
+
+	// ReadyPods is the number of ready OneAgent pods on nodes with this architecture
+	ReadyPods int32 `json:"readyPods"`
+}
+
+type OneAgentNodeGroupStatus struct {
+	// DaemonSets are the names of the DaemonSets deploying OneAgent to the nodes of the node group
+	DaemonSets []string `json:"daemonSets,omitempty"`
+
+	// Pods is the number of OneAgent pods on the nodes of the node group
+	Pods int32 `json:"pods"`
+
+	// ReadyPods is the number of ready OneAgent pods on the nodes of the node group
+	ReadyPods int32 `json:"readyPods"`
+}
+
+type OneAgentInstance struct {
+	PodName   string `json:"podName,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// Architecture is the architecture of the node, e.g. amd64
+	Architecture string `json:"architecture,omitempty"`
+
+	// NodeGroup is the name of the node group of the node, unset if the node doesn't belong to a node group
+	NodeGroup string `json:"nodeGroup,omitempty"`
+
+	// Version is the version of the OneAgent running on the host, as reported by Dynatrace
+	Version string `json:"version,omitempty"`
+
+	// PodPhase is the phase of the OneAgent pod
+	PodPhase corev1.PodPhase `json:"podPhase,omitempty"`
+
+	// Ready is set when all containers of the OneAgent pod are ready
+	Ready bool `json:"ready"`
+
+	// RestartCount is the number of times the containers of the OneAgent pod have been restarted
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// LastTransitionTime is the last time the OneAgent pod became ready or not ready
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// EntityID is the id of the host in Dynatrace, set when the host is visible in Dynatrace
+	EntityID string `json:"entityId,omitempty"`
+
+	// VisibleInDynatrace is set when the host of the OneAgent pod has been found in Dynatrace
+	VisibleInDynatrace bool `json:"visibleInDynatrace"`
+}
+
+type DynaKubePhaseType string
+
+const (
+	Running   DynaKubePhaseType = "Running"
+	Deploying DynaKubePhaseType = "Deploying"
+	Error     DynaKubePhaseType = "Error"
+)
+
+// SetPhase sets the status phase on the DynaKube object
+func (dk *DynaKubeStatus) SetPhase(phase DynaKubePhaseType) bool {
+	upd := phase != dk.Phase
+	dk.Phase = phase
+	return upd
+}
+
+// SetPhaseOnError fills the phase with the Error value in case of any error
+func (dk *DynaKubeStatus) SetPhaseOnError(err error) bool {
+	if err != nil {
+		return dk.SetPhase(Error)
+	}
+	return false
+}
+
+const (
+	// APITokenConditionType identifies the API Token validity condition
+	APITokenConditionType string = "APIToken"
+
+	// PaaSTokenConditionType identifies the PaaS Token validity condition
+	PaaSTokenConditionType string = "PaaSToken"
+)
+
+// Possible reasons for ApiToken and PaaSToken conditions
+const (
+	// ReasonTokenReady is set when a token has passed verifications
+	ReasonTokenReady string = "TokenReady"
+
+	// ReasonTokenSecretNotFound is set when the referenced secret can't be found
+	ReasonTokenSecretNotFound string = "TokenSecretNotFound"
+
+	// ReasonTokenMissing is set when the field is missing on the secret
+	ReasonTokenMissing string = "TokenMissing"
+
+	// ReasonTokenUnauthorized is set when a token is unauthorized to query the Dynatrace API
+	ReasonTokenUnauthorized string = "TokenUnauthorized"
+
+	// ReasonTokenScopeMissing is set when the token is missing the required scope for the Dynatrace API
+	ReasonTokenScopeMissing string = "TokenScopeMissing"
+
+	// ReasonTokenError is set when an unknown error has been found when verifying the token
+	ReasonTokenError string = "TokenError"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DynaKube is the Schema for the DynaKube API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=dynakubes,scope=Namespaced,categories=dynatrace
+// +kubebuilder:printcolumn:name="ApiUrl",type=string,JSONPath=`.spec.apiUrl`
+// +kubebuilder:printcolumn:name="Tokens",type=string,JSONPath=`.status.tokens`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +operator-sdk:csv:customresourcedefinitions:displayName="Dynatrace DynaKube"
+// +operator-sdk:csv:customresourcedefinitions:resources={{StatefulSet,v1,},{DaemonSet,v1,},{Pod,v1,}}
+type DynaKube struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DynaKubeSpec   `json:"spec,omitempty"`
+	Status DynaKubeStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DynaKubeList contains a list of DynaKube
+type DynaKubeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DynaKube `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DynaKube{}, &DynaKubeList{})
+}
@@ -14,10 +14,12 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package v1alpha1
+package v1beta1
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -26,6 +28,9 @@ const (
 	annotationFeatureDisableHostsRequests            = annotationFeaturePrefix + "disable-hosts-requests"
 	annotationFeatureOneAgentMaxUnavailable          = annotationFeaturePrefix + "oneagent-max-unavailable"
 	annotationFeatureEnableWebhookReinvocationPolicy = annotationFeaturePrefix + "enable-webhook-reinvocation-policy"
+	annotationFeatureUseDynatraceAPIV2               = annotationFeaturePrefix + "use-dynatrace-api-v2"
+	annotationFeatureAllowOneAgentDowngrades         = annotationFeaturePrefix + "allow-oneagent-downgrades"
+	annotationFeatureUnblockOneAgentVersions         = annotationFeaturePrefix + "unblock-oneagent-versions"
 )
 
 // FeatureDisableActiveGateUpdates is a feature flag to disable ActiveGate updates.
@@ -53,6 +58,20 @@ func (dk *DynaKube) FeatureOneAgentMaxUnavailable() int {
 	return val
 }
 
+// ValidateFeatureOneAgentMaxUnavailable returns an error if the oneagent-max-unavailable feature flag is set, but is
+// not a positive integer.
+func (dk *DynaKube) ValidateFeatureOneAgentMaxUnavailable() error {
+	raw, ok := dk.Annotations[annotationFeatureOneAgentMaxUnavailable]
+	if !ok {
+		return nil
+	}
+
+	if val, err := strconv.Atoi(raw); err != nil || val < 1 {
+		return fmt.Errorf("annotation %s must be a positive integer, got '%s'", annotationFeatureOneAgentMaxUnavailable, raw)
+	}
+	return nil
+}
+
 // FeatureEnableWebhookReinvocationPolicy is a feature flag to enable instrumenting missing containers
 // by enabling reinvocation for webhook.
 func (dk *DynaKube) FeatureEnableWebhookReinvocationPolicy() bool {
@@ -63,3 +82,38 @@ func (dk *DynaKube) FeatureEnableWebhookReinvocationPolicy() bool {
 func (dk *DynaKube) GetFeatureEnableWebhookReinvocationPolicy() string {
 	return annotationFeatureEnableWebhookReinvocationPolicy
 }
+
+// FeatureUseDynatraceAPIV2 is a feature flag to use the v2 endpoints of the Dynatrace API where available, falling back
+// to v1 on clusters which don't provide them.
+func (dk *DynaKube) FeatureUseDynatraceAPIV2() bool {
+	return dk.Annotations[annotationFeatureUseDynatraceAPIV2] == "true"
+}
+
+// FeatureAllowOneAgentDowngrades is a feature flag to allow the Operator to move OneAgent and the code modules provisioned
+// by the CSI driver to an older version, e.g. to go back to a known-good version.
+func (dk *DynaKube) FeatureAllowOneAgentDowngrades() bool {
+	return dk.Annotations[annotationFeatureAllowOneAgentDowngrades] == "true"
+}
+
+// FeatureUnblockOneAgentVersions is a feature flag to roll out OneAgent versions again which have been blocked after a
+// rollback. It contains a comma-separated list of versions, or "all" to unblock every version. Returns false if the
+// annotation isn't set.
+func (dk *DynaKube) FeatureUnblockOneAgentVersions() ([]string, bool) {
+	raw, ok := dk.Annotations[annotationFeatureUnblockOneAgentVersions]
+	if !ok {
+		return nil, false
+	}
+
+	var versions []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions, true
+}
+
+// GetFeatureUnblockOneAgentVersions returns the annotation for FeatureUnblockOneAgentVersions
+func (dk *DynaKube) GetFeatureUnblockOneAgentVersions() string {
+	return annotationFeatureUnblockOneAgentVersions
+}
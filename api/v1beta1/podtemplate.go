@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Dynatrace LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// ApplyPodTemplateOverride merges the override into the pod template using the rules of a strategic merge patch, so
+// e.g. containers are merged by their name. The template is left unchanged if the override is nil or empty.
+func ApplyPodTemplateOverride(template *corev1.PodTemplateSpec, override *runtime.RawExtension) error {
+	if override == nil || len(override.Raw) == 0 {
+		return nil
+	}
+
+	original, err := json.Marshal(template)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	patched, err := strategicpatch.StrategicMergePatch(original, override.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return errors.WithMessage(err, "invalid pod template override")
+	}
+
+	var result corev1.PodTemplateSpec
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return errors.WithMessage(err, "invalid pod template override")
+	}
+	*template = result
+	return nil
+}
+
+// OneAgentArchitectures lists the node architectures OneAgent can be deployed to.
+var OneAgentArchitectures = []string{"amd64", "arm64", "ppc64le", "s390x"}
+
+// IsSupportedOneAgentArchitecture returns true if OneAgent can be deployed to nodes with the architecture.
+func IsSupportedOneAgentArchitecture(arch string) bool {
+	for _, supported := range OneAgentArchitectures {
+		if arch == supported {
+			return true
+		}
+	}
+	return false
+}
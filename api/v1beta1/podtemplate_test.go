@@ -0,0 +1,56 @@
+/*
+Copyright 2021 Dynatrace LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyPodTemplateOverride(t *testing.T) {
+	t.Run(`override is merged into the pod template`, func(t *testing.T) {
+		template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			HostNetwork: true,
+			Containers:  []corev1.Container{{Name: "dynatrace-oneagent", Image: "oneagent"}},
+		}}
+		require.NoError(t, ApplyPodTemplateOverride(&template, &runtime.RawExtension{Raw: []byte(`{
+			"spec": {"containers": [{"name": "dynatrace-oneagent", "imagePullPolicy": "Always"}, {"name": "sidecar"}]}
+		}`)}))
+
+		assert.True(t, template.Spec.HostNetwork)
+		require.Len(t, template.Spec.Containers, 2)
+		assert.Equal(t, "oneagent", template.Spec.Containers[0].Image)
+		assert.Equal(t, corev1.PullAlways, template.Spec.Containers[0].ImagePullPolicy)
+		assert.Equal(t, "sidecar", template.Spec.Containers[1].Name)
+	})
+	t.Run(`invalid override is rejected`, func(t *testing.T) {
+		err := ApplyPodTemplateOverride(&corev1.PodTemplateSpec{}, &runtime.RawExtension{Raw: []byte(`{"spec": {"containers": "sidecar"}}`)})
+		assert.Error(t, err)
+
+		err = ApplyPodTemplateOverride(&corev1.PodTemplateSpec{}, &runtime.RawExtension{Raw: []byte(`[]`)})
+		assert.Error(t, err)
+	})
+	t.Run(`template is unchanged without override`, func(t *testing.T) {
+		template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{HostNetwork: true}}
+		require.NoError(t, ApplyPodTemplateOverride(&template, nil))
+		assert.True(t, template.Spec.HostNetwork)
+	})
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2021 Dynatrace LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PullSecretSuffix is the suffix appended to the DynaKube name to n.
+	PullSecretSuffix = "-pull-secret"
+
+	// DefaultAPIRequestTimeout is the timeout for requests to the Dynatrace API if none is configured.
+	DefaultAPIRequestTimeout = 30 * time.Second
+
+	// DefaultOneAgentWaitReadySeconds is the time to wait for OneAgent pods to become ready after an update, if none is
+	// configured.
+	DefaultOneAgentWaitReadySeconds = 300
+)
+
+// NeedsActiveGate returns true when a feature requires ActiveGate instances.
+func (dk *DynaKube) NeedsActiveGate() bool {
+	return dk.Spec.KubernetesMonitoringSpec.Enabled || dk.Spec.RoutingSpec.Enabled
+}
+
+// NeedsOneAgent returns true when a feature requires OneAgent instances.
+func (dk *DynaKube) NeedsOneAgent() bool {
+	return dk.HostInjectSpec() != nil
+}
+
+// NeedsImmutableOneAgent returns true when a feature requires OneAgent instances running the immutable image.
+func (dk *DynaKube) NeedsImmutableOneAgent() bool {
+	hostInjectSpec := dk.HostInjectSpec()
+	return hostInjectSpec != nil && hostInjectSpec.UseImmutableImage
+}
+
+// NeedsAppInjection returns true when a feature requires code modules to be injected into pods.
+func (dk *DynaKube) NeedsAppInjection() bool {
+	return dk.AppInjectionSpec() != nil
+}
+
+// NeedsCSIDriver returns true when code modules are provided to pods through the CSI driver, which is the case if no
+// other volume has been configured for them.
+func (dk *DynaKube) NeedsCSIDriver() bool {
+	appInjectionSpec := dk.AppInjectionSpec()
+	return appInjectionSpec != nil &&
+		(appInjectionSpec.Volume == (corev1.VolumeSource{}) || appInjectionSpec.Volume.CSI != nil)
+}
+
+// ClassicFullStackMode returns true when OneAgent is deployed in classic full-stack mode.
+func (dk *DynaKube) ClassicFullStackMode() bool {
+	return dk.Spec.OneAgent.ClassicFullStack != nil
+}
+
+// HostMonitoringMode returns true when OneAgent is deployed for infrastructure monitoring only.
+func (dk *DynaKube) HostMonitoringMode() bool {
+	return dk.Spec.OneAgent.HostMonitoring != nil
+}
+
+// CloudNativeFullStackMode returns true when OneAgent is deployed for infrastructure monitoring with code modules injection.
+func (dk *DynaKube) CloudNativeFullStackMode() bool {
+	return dk.Spec.OneAgent.CloudNativeFullStack != nil
+}
+
+// ApplicationMonitoringMode returns true when code modules are injected without deploying OneAgent on the nodes.
+func (dk *DynaKube) ApplicationMonitoringMode() bool {
+	return dk.Spec.OneAgent.ApplicationMonitoring != nil
+}
+
+// HostInjectSpec returns the configuration of the OneAgent instances deployed on the nodes, or nil if the configured
+// mode doesn't deploy any.
+func (dk *DynaKube) HostInjectSpec() *HostInjectSpec {
+	switch {
+	case dk.ClassicFullStackMode():
+		return dk.Spec.OneAgent.ClassicFullStack
+	case dk.HostMonitoringMode():
+		return dk.Spec.OneAgent.HostMonitoring
+	case dk.CloudNativeFullStackMode():
+		return &dk.Spec.OneAgent.CloudNativeFullStack.HostInjectSpec
+	}
+	return nil
+}
+
+// AppInjectionSpec returns the configuration for injecting code modules into pods, or nil if the configured mode
+// doesn't inject them.
+func (dk *DynaKube) AppInjectionSpec() *AppInjectionSpec {
+	switch {
+	case dk.CloudNativeFullStackMode():
+		return &dk.Spec.OneAgent.CloudNativeFullStack.AppInjectionSpec
+	case dk.ApplicationMonitoringMode():
+		return &dk.Spec.OneAgent.ApplicationMonitoring.AppInjectionSpec
+	}
+	return nil
+}
+
+// CodeModulesVersion returns the version of the code modules to be provisioned by the CSI driver, which is the latest
+// version unless another one is configured.
+func (dk *DynaKube) CodeModulesVersion() string {
+	if appInjectionSpec := dk.AppInjectionSpec(); appInjectionSpec != nil && appInjectionSpec.CodeModulesVersion != "" {
+		return appInjectionSpec.CodeModulesVersion
+	}
+	return dk.Status.LatestAgentVersionUnixPaas
+}
+
+// ShouldAutoUpdateOneAgent returns true if the Operator should update OneAgent instances automatically.
+func (dk *DynaKube) ShouldAutoUpdateOneAgent() bool {
+	hostInjectSpec := dk.HostInjectSpec()
+	return hostInjectSpec == nil || hostInjectSpec.AutoUpdate == nil || *hostInjectSpec.AutoUpdate
+}
+
+// OneAgentVersionPolicy returns the version policy of the OneAgent instances deployed on the nodes, or nil if none is
+// configured.
+func (dk *DynaKube) OneAgentVersionPolicy() *VersionPolicySpec {
+	if hostInjectSpec := dk.HostInjectSpec(); hostInjectSpec != nil {
+		return hostInjectSpec.VersionPolicy
+	}
+	return nil
+}
+
+// OneAgentRolloutVersion returns the OneAgent version the DaemonSet is rolled out with. While a new version is rolled
+// out to the canary nodes, this is the version of the canary nodes.
+func (dk *DynaKube) OneAgentRolloutVersion() string {
+	if canary := dk.Status.OneAgent.Canary; canary != nil {
+		return canary.Version
+	}
+	return dk.Status.OneAgent.Version
+}
+
+// OneAgentWaitReadyDuration returns the time to wait for the OneAgent pods to become ready after an update.
+func (dk *DynaKube) OneAgentWaitReadyDuration() time.Duration {
+	if hostInjectSpec := dk.HostInjectSpec(); hostInjectSpec != nil && hostInjectSpec.WaitReadySeconds != nil {
+		return time.Duration(*hostInjectSpec.WaitReadySeconds) * time.Second
+	}
+	return DefaultOneAgentWaitReadySeconds * time.Second
+}
+
+// IsOneAgentVersionBlocked returns true if the version has been rolled back before and must not be rolled out again.
+func (dk *DynaKube) IsOneAgentVersionBlocked(version string) bool {
+	for _, v := range dk.Status.OneAgent.BlockedVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// APIProxy returns the proxy for requests to the Dynatrace API, or nil if none is configured.
+func (dk *DynaKube) APIProxy() *DynaKubeProxySource {
+	if p := dk.Spec.Proxy; p != nil {
+		return p.API.orDefault(p)
+	}
+	return nil
+}
+
+// CommunicationProxy returns the proxy for connections of OneAgent and ActiveGate to the communication endpoints, or nil
+// if none is configured.
+func (dk *DynaKube) CommunicationProxy() *DynaKubeProxySource {
+	if p := dk.Spec.Proxy; p != nil {
+		return p.Communication.orDefault(p)
+	}
+	return nil
+}
+
+// NoProxy returns the comma separated hosts and domains which are connected to without proxy.
+func (dk *DynaKube) NoProxy() string {
+	if dk.Spec.Proxy == nil {
+		return ""
+	}
+	return strings.Join(dk.Spec.Proxy.NoProxy, ",")
+}
+
+// orDefault returns the proxy source if it is set, otherwise the one given by value or valueFrom of the proxy, or nil if
+// neither is set.
+func (src *DynaKubeProxySource) orDefault(p *DynaKubeProxy) *DynaKubeProxySource {
+	if src != nil && (src.Value != "" || src.ValueFrom != "") {
+		return src
+	}
+	if p.Value != "" || p.ValueFrom != "" {
+		return &DynaKubeProxySource{Value: p.Value, ValueFrom: p.ValueFrom}
+	}
+	return nil
+}
+
+// PullSecret returns the name of the pull secret to be used for immutable images.
+func (dk *DynaKube) PullSecret() string {
+	if dk.Spec.CustomPullSecret != "" {
+		return dk.Spec.CustomPullSecret
+	}
+	return dk.Name + PullSecretSuffix
+}
+
+// ActiveGateImage returns the ActiveGate image to be used with the dk DynaKube instance.
+func (dk *DynaKube) ActiveGateImage() string {
+	if dk.Spec.ActiveGate.Image != "" {
+		return dk.Spec.ActiveGate.Image
+	}
+
+	if dk.Spec.APIURL == "" {
+		return ""
+	}
+
+	registry := buildImageRegistry(dk.Spec.APIURL)
+	return fmt.Sprintf("%s/linux/activegate:latest", registry)
+}
+
+// ImmutableOneAgentImage returns the immutable OneAgent image to be used with the dk DynaKube instance.
+func (dk *DynaKube) ImmutableOneAgentImage() string {
+	hostInjectSpec := dk.HostInjectSpec()
+	if hostInjectSpec != nil && hostInjectSpec.Image != "" {
+		return hostInjectSpec.Image
+	}
+
+	if dk.Spec.APIURL == "" {
+		return ""
+	}
+
+	tag := "latest"
+	if hostInjectSpec != nil && hostInjectSpec.Version != "" {
+		tag = hostInjectSpec.Version
+	}
+
+	registry := buildImageRegistry(dk.Spec.APIURL)
+	return fmt.Sprintf("%s/linux/oneagent:%s", registry, tag)
+}
+
+func buildImageRegistry(apiURL string) string {
+	registry := strings.TrimPrefix(apiURL, "https://")
+	registry = strings.TrimPrefix(registry, "http://")
+	registry = strings.TrimSuffix(registry, "/api")
+	return registry
+}
+
+// APIRequestTimeout returns the timeout for requests to the Dynatrace API.
+func (dk *DynaKube) APIRequestTimeout() time.Duration {
+	if seconds := dk.Spec.APIRequestTimeoutSeconds; seconds != nil && *seconds > 0 {
+		return time.Duration(*seconds) * time.Second
+	}
+	return DefaultAPIRequestTimeout
+}
+
+// Tokens returns the name of the Secret to be used for tokens.
+func (dk *DynaKube) Tokens() string {
+	if tkns := dk.Spec.Tokens; tkns != "" {
+		return tkns
+	}
+	return dk.Name
+}
+
+func (dk *DynaKube) CommunicationHostForClient() dtclient.CommunicationHost {
+	return dtclient.CommunicationHost(dk.Status.CommunicationHostForClient)
+}
+
+func (dk *DynaKube) ConnectionInfo() dtclient.ConnectionInfo {
+	return dtclient.ConnectionInfo{
+		CommunicationHosts: dk.CommunicationHosts(),
+		TenantUUID:         dk.Status.ConnectionInfo.TenantUUID,
+	}
+}
+
+func (dk *DynaKube) CommunicationHosts() []dtclient.CommunicationHost {
+	var communicationHosts []dtclient.CommunicationHost
+	for _, communicationHost := range dk.Status.ConnectionInfo.CommunicationHosts {
+		communicationHosts = append(communicationHosts, dtclient.CommunicationHost(communicationHost))
+	}
+	return communicationHosts
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021 Dynatrace LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testAPIURL = "http://test-endpoint/api"
+
+func TestActiveGateImage(t *testing.T) {
+	t.Run(`ActiveGateImage with no API URL`, func(t *testing.T) {
+		dk := DynaKube{}
+		assert.Equal(t, "", dk.ActiveGateImage())
+	})
+
+	t.Run(`ActiveGateImage with API URL`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL}}
+		assert.Equal(t, "test-endpoint/linux/activegate:latest", dk.ActiveGateImage())
+	})
+
+	t.Run(`ActiveGateImage with custom image`, func(t *testing.T) {
+		customImg := "registry/my/activegate:latest"
+		dk := DynaKube{Spec: DynaKubeSpec{ActiveGate: ActiveGateSpec{Image: customImg}}}
+		assert.Equal(t, customImg, dk.ActiveGateImage())
+	})
+}
+
+func TestOneAgentImage(t *testing.T) {
+	t.Run(`OneAgentImage with no API URL`, func(t *testing.T) {
+		dk := DynaKube{}
+		assert.Equal(t, "", dk.ImmutableOneAgentImage())
+	})
+
+	t.Run(`OneAgentImage with API URL`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL}}
+		assert.Equal(t, "test-endpoint/linux/oneagent:latest", dk.ImmutableOneAgentImage())
+	})
+
+	t.Run(`OneAgentImage with API URL and custom version`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL, OneAgent: OneAgentSpec{ClassicFullStack: &HostInjectSpec{Version: "1.234.5"}}}}
+		assert.Equal(t, "test-endpoint/linux/oneagent:1.234.5", dk.ImmutableOneAgentImage())
+	})
+
+	t.Run(`OneAgentImage with custom image`, func(t *testing.T) {
+		customImg := "registry/my/oneagent:latest"
+		dk := DynaKube{Spec: DynaKubeSpec{OneAgent: OneAgentSpec{HostMonitoring: &HostInjectSpec{Image: customImg}}}}
+		assert.Equal(t, customImg, dk.ImmutableOneAgentImage())
+	})
+}
+
+func TestTokens(t *testing.T) {
+	testName := "test-name"
+	testValue := "test-value"
+
+	t.Run(`GetTokensName returns custom token name`, func(t *testing.T) {
+		dk := DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName},
+			Spec:       DynaKubeSpec{Tokens: testValue},
+		}
+		assert.Equal(t, dk.Tokens(), testValue)
+	})
+	t.Run(`GetTokensName uses instance name as default value`, func(t *testing.T) {
+		dk := DynaKube{ObjectMeta: metav1.ObjectMeta{Name: testName}}
+		assert.Equal(t, dk.Tokens(), testName)
+	})
+}
+
+func TestAPIRequestTimeout(t *testing.T) {
+	dk := DynaKube{}
+	assert.Equal(t, DefaultAPIRequestTimeout, dk.APIRequestTimeout())
+
+	timeout := uint16(5)
+	dk.Spec.APIRequestTimeoutSeconds = &timeout
+	assert.Equal(t, 5*time.Second, dk.APIRequestTimeout())
+}
+
+func TestOneAgentModes(t *testing.T) {
+	t.Run(`no mode configured`, func(t *testing.T) {
+		dk := DynaKube{}
+		assert.False(t, dk.NeedsOneAgent())
+		assert.False(t, dk.NeedsAppInjection())
+		assert.Nil(t, dk.HostInjectSpec())
+		assert.Nil(t, dk.AppInjectionSpec())
+		assert.True(t, dk.ShouldAutoUpdateOneAgent())
+	})
+
+	t.Run(`classic full-stack`, func(t *testing.T) {
+		autoUpdate := false
+		dk := DynaKube{Spec: DynaKubeSpec{OneAgent: OneAgentSpec{
+			ClassicFullStack: &HostInjectSpec{AutoUpdate: &autoUpdate, UseImmutableImage: true},
+		}}}
+		assert.True(t, dk.ClassicFullStackMode())
+		assert.True(t, dk.NeedsOneAgent())
+		assert.True(t, dk.NeedsImmutableOneAgent())
+		assert.False(t, dk.NeedsAppInjection())
+		assert.False(t, dk.ShouldAutoUpdateOneAgent())
+		assert.Same(t, dk.Spec.OneAgent.ClassicFullStack, dk.HostInjectSpec())
+	})
+
+	t.Run(`cloud native full-stack`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{OneAgent: OneAgentSpec{
+			CloudNativeFullStack: &CloudNativeFullStackSpec{},
+		}}}
+		assert.True(t, dk.CloudNativeFullStackMode())
+		assert.True(t, dk.NeedsOneAgent())
+		assert.False(t, dk.NeedsImmutableOneAgent())
+		assert.True(t, dk.NeedsAppInjection())
+		assert.Same(t, &dk.Spec.OneAgent.CloudNativeFullStack.HostInjectSpec, dk.HostInjectSpec())
+		assert.Same(t, &dk.Spec.OneAgent.CloudNativeFullStack.AppInjectionSpec, dk.AppInjectionSpec())
+	})
+
+	t.Run(`application monitoring`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{OneAgent: OneAgentSpec{
+			ApplicationMonitoring: &ApplicationMonitoringSpec{},
+		}}}
+		assert.True(t, dk.ApplicationMonitoringMode())
+		assert.False(t, dk.NeedsOneAgent())
+		assert.True(t, dk.NeedsAppInjection())
+		assert.Nil(t, dk.HostInjectSpec())
+	})
+}
+
+func TestNeedsCSIDriver(t *testing.T) {
+	dk := DynaKube{}
+	assert.False(t, dk.NeedsCSIDriver())
+
+	dk.Spec.OneAgent.CloudNativeFullStack = &CloudNativeFullStackSpec{}
+	assert.True(t, dk.NeedsCSIDriver())
+
+	dk.Spec.OneAgent.CloudNativeFullStack.Volume = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	assert.False(t, dk.NeedsCSIDriver())
+
+	dk.Spec.OneAgent = OneAgentSpec{ApplicationMonitoring: &ApplicationMonitoringSpec{}}
+	assert.True(t, dk.NeedsCSIDriver())
+}
+
+func TestOneAgentRolloutVersion(t *testing.T) {
+	dk := DynaKube{}
+	assert.Nil(t, dk.OneAgentVersionPolicy())
+
+	dk.Spec.OneAgent.HostMonitoring = &HostInjectSpec{VersionPolicy: &VersionPolicySpec{Type: VersionPolicyPinned}}
+	if policy := dk.OneAgentVersionPolicy(); assert.NotNil(t, policy) {
+		assert.Equal(t, VersionPolicyPinned, policy.Type)
+	}
+
+	dk.Status.OneAgent.Version = "1.213.0.20210301-123456"
+	assert.Equal(t, "1.213.0.20210301-123456", dk.OneAgentRolloutVersion())
+
+	dk.Status.OneAgent.Canary = &CanaryStatus{Version: "1.215.0.20210415-123456"}
+	assert.Equal(t, "1.215.0.20210415-123456", dk.OneAgentRolloutVersion())
+}
+
+func TestCodeModulesVersion(t *testing.T) {
+	dk := DynaKube{Status: DynaKubeStatus{LatestAgentVersionUnixPaas: "1.215.0.20210415-123456"}}
+	assert.Equal(t, "1.215.0.20210415-123456", dk.CodeModulesVersion())
+
+	dk.Spec.OneAgent.ApplicationMonitoring = &ApplicationMonitoringSpec{}
+	assert.Equal(t, "1.215.0.20210415-123456", dk.CodeModulesVersion())
+
+	dk.Spec.OneAgent.ApplicationMonitoring.CodeModulesVersion = "1.213.0.20210301-123456"
+	assert.Equal(t, "1.213.0.20210301-123456", dk.CodeModulesVersion())
+}
+
+func TestProxies(t *testing.T) {
+	dk := DynaKube{}
+	assert.Nil(t, dk.APIProxy())
+	assert.Nil(t, dk.CommunicationProxy())
+	assert.Empty(t, dk.NoProxy())
+
+	dk.Spec.Proxy = &DynaKubeProxy{ValueFrom: "proxy-secret", NoProxy: []string{"localhost", ".cluster.local"}}
+	assert.Equal(t, &DynaKubeProxySource{ValueFrom: "proxy-secret"}, dk.APIProxy())
+	assert.Equal(t, &DynaKubeProxySource{ValueFrom: "proxy-secret"}, dk.CommunicationProxy())
+	assert.Equal(t, "localhost,.cluster.local", dk.NoProxy())
+
+	dk.Spec.Proxy.API = &DynaKubeProxySource{Value: "http://api-proxy:8080"}
+	dk.Spec.Proxy.Communication = &DynaKubeProxySource{}
+	assert.Equal(t, &DynaKubeProxySource{Value: "http://api-proxy:8080"}, dk.APIProxy())
+	assert.Equal(t, &DynaKubeProxySource{ValueFrom: "proxy-secret"}, dk.CommunicationProxy())
+
+	dk.Spec.Proxy.ValueFrom = ""
+	assert.Nil(t, dk.CommunicationProxy())
+}
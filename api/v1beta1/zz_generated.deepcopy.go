@@ -0,0 +1,834 @@
+// +build !ignore_autogenerated
+
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveGateSpec) DeepCopyInto(out *ActiveGateSpec) {
+	*out = *in
+	if in.AutoUpdate != nil {
+		in, out := &in.AutoUpdate, &out.AutoUpdate
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveGateSpec.
+func (in *ActiveGateSpec) DeepCopy() *ActiveGateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveGateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveGateStatus) DeepCopyInto(out *ActiveGateStatus) {
+	*out = *in
+	in.VersionStatus.DeepCopyInto(&out.VersionStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveGateStatus.
+func (in *ActiveGateStatus) DeepCopy() *ActiveGateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveGateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppInjectionSpec) DeepCopyInto(out *AppInjectionSpec) {
+	*out = *in
+	in.InitResources.DeepCopyInto(&out.InitResources)
+	in.Volume.DeepCopyInto(&out.Volume)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppInjectionSpec.
+func (in *AppInjectionSpec) DeepCopy() *AppInjectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppInjectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationMonitoringSpec) DeepCopyInto(out *ApplicationMonitoringSpec) {
+	*out = *in
+	in.AppInjectionSpec.DeepCopyInto(&out.AppInjectionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationMonitoringSpec.
+func (in *ApplicationMonitoringSpec) DeepCopy() *ApplicationMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
+func (in *CanarySpec) DeepCopy() *CanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	in.StartedTimestamp.DeepCopyInto(&out.StartedTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapabilityProperties) DeepCopyInto(out *CapabilityProperties) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CustomProperties != nil {
+		in, out := &in.CustomProperties, &out.CustomProperties
+		*out = new(DynaKubeValueSource)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapabilityProperties.
+func (in *CapabilityProperties) DeepCopy() *CapabilityProperties {
+	if in == nil {
+		return nil
+	}
+	out := new(CapabilityProperties)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudNativeFullStackSpec) DeepCopyInto(out *CloudNativeFullStackSpec) {
+	*out = *in
+	in.HostInjectSpec.DeepCopyInto(&out.HostInjectSpec)
+	in.AppInjectionSpec.DeepCopyInto(&out.AppInjectionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudNativeFullStackSpec.
+func (in *CloudNativeFullStackSpec) DeepCopy() *CloudNativeFullStackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudNativeFullStackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommunicationHostStatus) DeepCopyInto(out *CommunicationHostStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunicationHostStatus.
+func (in *CommunicationHostStatus) DeepCopy() *CommunicationHostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CommunicationHostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionInfoStatus) DeepCopyInto(out *ConnectionInfoStatus) {
+	*out = *in
+	if in.CommunicationHosts != nil {
+		in, out := &in.CommunicationHosts, &out.CommunicationHosts
+		*out = make([]CommunicationHostStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionInfoStatus.
+func (in *ConnectionInfoStatus) DeepCopy() *ConnectionInfoStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionInfoStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataIngestSpec) DeepCopyInto(out *DataIngestSpec) {
+	*out = *in
+	in.CapabilityProperties.DeepCopyInto(&out.CapabilityProperties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataIngestSpec.
+func (in *DataIngestSpec) DeepCopy() *DataIngestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataIngestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKube) DeepCopyInto(out *DynaKube) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKube.
+func (in *DynaKube) DeepCopy() *DynaKube {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKube)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynaKube) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeList) DeepCopyInto(out *DynaKubeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynaKube, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeList.
+func (in *DynaKubeList) DeepCopy() *DynaKubeList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynaKubeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeProxy) DeepCopyInto(out *DynaKubeProxy) {
+	*out = *in
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(DynaKubeProxySource)
+		**out = **in
+	}
+	if in.Communication != nil {
+		in, out := &in.Communication, &out.Communication
+		*out = new(DynaKubeProxySource)
+		**out = **in
+	}
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeProxy.
+func (in *DynaKubeProxy) DeepCopy() *DynaKubeProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeProxySource) DeepCopyInto(out *DynaKubeProxySource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeProxySource.
+func (in *DynaKubeProxySource) DeepCopy() *DynaKubeProxySource {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeProxySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeSpec) DeepCopyInto(out *DynaKubeSpec) {
+	*out = *in
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(DynaKubeProxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIRequestTimeoutSeconds != nil {
+		in, out := &in.APIRequestTimeoutSeconds, &out.APIRequestTimeoutSeconds
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowSpec)
+		**out = **in
+	}
+	in.ActiveGate.DeepCopyInto(&out.ActiveGate)
+	in.OneAgent.DeepCopyInto(&out.OneAgent)
+	in.RoutingSpec.DeepCopyInto(&out.RoutingSpec)
+	in.DataIngestSpec.DeepCopyInto(&out.DataIngestSpec)
+	in.KubernetesMonitoringSpec.DeepCopyInto(&out.KubernetesMonitoringSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeSpec.
+func (in *DynaKubeSpec) DeepCopy() *DynaKubeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeStatus) DeepCopyInto(out *DynaKubeStatus) {
+	*out = *in
+	in.UpdatedTimestamp.DeepCopyInto(&out.UpdatedTimestamp)
+	if in.LastAPITokenProbeTimestamp != nil {
+		in, out := &in.LastAPITokenProbeTimestamp, &out.LastAPITokenProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.LastPaaSTokenProbeTimestamp != nil {
+		in, out := &in.LastPaaSTokenProbeTimestamp, &out.LastPaaSTokenProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.LastClusterVersionProbeTimestamp != nil {
+		in, out := &in.LastClusterVersionProbeTimestamp, &out.LastClusterVersionProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+	in.ConnectionInfo.DeepCopyInto(&out.ConnectionInfo)
+	out.CommunicationHostForClient = in.CommunicationHostForClient
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.ActiveGate.DeepCopyInto(&out.ActiveGate)
+	in.OneAgent.DeepCopyInto(&out.OneAgent)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeStatus.
+func (in *DynaKubeStatus) DeepCopy() *DynaKubeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeValueSource) DeepCopyInto(out *DynaKubeValueSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeValueSource.
+func (in *DynaKubeValueSource) DeepCopy() *DynaKubeValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostConfig) DeepCopyInto(out *HostConfig) {
+	*out = *in
+	if in.HostTags != nil {
+		in, out := &in.HostTags, &out.HostTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostProperties != nil {
+		in, out := &in.HostProperties, &out.HostProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostConfig.
+func (in *HostConfig) DeepCopy() *HostConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HostConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostConfigOverride) DeepCopyInto(out *HostConfigOverride) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.HostConfig.DeepCopyInto(&out.HostConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostConfigOverride.
+func (in *HostConfigOverride) DeepCopy() *HostConfigOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(HostConfigOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostInjectSpec) DeepCopyInto(out *HostInjectSpec) {
+	*out = *in
+	if in.AutoUpdate != nil {
+		in, out := &in.AutoUpdate, &out.AutoUpdate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WaitReadySeconds != nil {
+		in, out := &in.WaitReadySeconds, &out.WaitReadySeconds
+		*out = new(uint16)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UseUnprivilegedMode != nil {
+		in, out := &in.UseUnprivilegedMode, &out.UseUnprivilegedMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VersionPolicy != nil {
+		in, out := &in.VersionPolicy, &out.VersionPolicy
+		*out = new(VersionPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplateOverride != nil {
+		in, out := &in.PodTemplateOverride, &out.PodTemplateOverride
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	in.HostConfig.DeepCopyInto(&out.HostConfig)
+	if in.HostConfigOverrides != nil {
+		in, out := &in.HostConfigOverrides, &out.HostConfigOverrides
+		*out = make([]HostConfigOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostInjectSpec.
+func (in *HostInjectSpec) DeepCopy() *HostInjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HostInjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesMonitoringSpec) DeepCopyInto(out *KubernetesMonitoringSpec) {
+	*out = *in
+	in.CapabilityProperties.DeepCopyInto(&out.CapabilityProperties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesMonitoringSpec.
+func (in *KubernetesMonitoringSpec) DeepCopy() *KubernetesMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneAgentArchitectureStatus) DeepCopyInto(out *OneAgentArchitectureStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentArchitectureStatus.
+func (in *OneAgentArchitectureStatus) DeepCopy() *OneAgentArchitectureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OneAgentArchitectureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneAgentInstance) DeepCopyInto(out *OneAgentInstance) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentInstance.
+func (in *OneAgentInstance) DeepCopy() *OneAgentInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(OneAgentInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneAgentNodeGroupStatus) DeepCopyInto(out *OneAgentNodeGroupStatus) {
+	*out = *in
+	if in.DaemonSets != nil {
+		in, out := &in.DaemonSets, &out.DaemonSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentNodeGroupStatus.
+func (in *OneAgentNodeGroupStatus) DeepCopy() *OneAgentNodeGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OneAgentNodeGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneAgentSpec) DeepCopyInto(out *OneAgentSpec) {
+	*out = *in
+	if in.ClassicFullStack != nil {
+		in, out := &in.ClassicFullStack, &out.ClassicFullStack
+		*out = new(HostInjectSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostMonitoring != nil {
+		in, out := &in.HostMonitoring, &out.HostMonitoring
+		*out = new(HostInjectSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudNativeFullStack != nil {
+		in, out := &in.CloudNativeFullStack, &out.CloudNativeFullStack
+		*out = new(CloudNativeFullStackSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ApplicationMonitoring != nil {
+		in, out := &in.ApplicationMonitoring, &out.ApplicationMonitoring
+		*out = new(ApplicationMonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentSpec.
+func (in *OneAgentSpec) DeepCopy() *OneAgentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OneAgentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneAgentStatus) DeepCopyInto(out *OneAgentStatus) {
+	*out = *in
+	in.VersionStatus.DeepCopyInto(&out.VersionStatus)
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make(map[string]OneAgentInstance, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make(map[string]OneAgentArchitectureStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeGroups != nil {
+		in, out := &in.NodeGroups, &out.NodeGroups
+		*out = make(map[string]OneAgentNodeGroupStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.LastHostsRequestTimestamp != nil {
+		in, out := &in.LastHostsRequestTimestamp, &out.LastHostsRequestTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutStartedTimestamp != nil {
+		in, out := &in.RolloutStartedTimestamp, &out.RolloutStartedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.BlockedVersions != nil {
+		in, out := &in.BlockedVersions, &out.BlockedVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentStatus.
+func (in *OneAgentStatus) DeepCopy() *OneAgentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OneAgentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingSpec) DeepCopyInto(out *RoutingSpec) {
+	*out = *in
+	in.CapabilityProperties.DeepCopyInto(&out.CapabilityProperties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingSpec.
+func (in *RoutingSpec) DeepCopy() *RoutingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionPolicySpec) DeepCopyInto(out *VersionPolicySpec) {
+	*out = *in
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionPolicySpec.
+func (in *VersionPolicySpec) DeepCopy() *VersionPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionStatus) DeepCopyInto(out *VersionStatus) {
+	*out = *in
+	if in.LastUpdateProbeTimestamp != nil {
+		in, out := &in.LastUpdateProbeTimestamp, &out.LastUpdateProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionStatus.
+func (in *VersionStatus) DeepCopy() *VersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
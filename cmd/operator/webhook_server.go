@@ -19,6 +19,7 @@ package main
 import (
 	"time"
 
+	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
 	"github.com/Dynatrace/dynatrace-operator/webhook/server"
 	"github.com/spf13/afero"
@@ -68,6 +69,10 @@ func startWebhookServer(ns string, cfg *rest.Config) (manager.Manager, error) {
 		return nil, err
 	}
 
+	if err := (&dynatracev1alpha1.DynaKube{}).SetupWebhookWithManager(mgr); err != nil {
+		return nil, err
+	}
+
 	return mgr, nil
 }
 
@@ -1,7 +1,7 @@
 package capability
 
 import (
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -26,7 +26,7 @@ type Configuration struct {
 type Capability interface {
 	GetModuleName() string
 	GetCapabilityName() string
-	GetProperties() *dynatracev1alpha1.CapabilityProperties
+	GetProperties() *dynatracev1beta1.CapabilityProperties
 	GetConfiguration() Configuration
 	GetInitContainersTemplates() []v1.Container
 	GetContainerVolumeMounts() []v1.VolumeMount
@@ -36,14 +36,14 @@ type Capability interface {
 type capabilityBase struct {
 	moduleName     string
 	capabilityName string
-	properties     *dynatracev1alpha1.CapabilityProperties
+	properties     *dynatracev1beta1.CapabilityProperties
 	Configuration
 	initContainersTemplates []v1.Container
 	containerVolumeMounts   []v1.VolumeMount
 	volumes                 []v1.Volume
 }
 
-func (c *capabilityBase) GetProperties() *dynatracev1alpha1.CapabilityProperties {
+func (c *capabilityBase) GetProperties() *dynatracev1beta1.CapabilityProperties {
 	return c.properties
 }
 
@@ -91,7 +91,7 @@ type DataIngestCapability struct {
 	capabilityBase
 }
 
-func NewKubeMonCapability(crProperties *dynatracev1alpha1.CapabilityProperties) *KubeMonCapability {
+func NewKubeMonCapability(crProperties *dynatracev1beta1.CapabilityProperties) *KubeMonCapability {
 	return &KubeMonCapability{
 		capabilityBase{
 			moduleName:     "kubemon",
@@ -132,7 +132,7 @@ func NewKubeMonCapability(crProperties *dynatracev1alpha1.CapabilityProperties)
 	}
 }
 
-func NewRoutingCapability(crProperties *dynatracev1alpha1.CapabilityProperties) *RoutingCapability {
+func NewRoutingCapability(crProperties *dynatracev1beta1.CapabilityProperties) *RoutingCapability {
 	return &RoutingCapability{
 		capabilityBase{
 			moduleName:     "routing",
@@ -148,7 +148,7 @@ func NewRoutingCapability(crProperties *dynatracev1alpha1.CapabilityProperties)
 	}
 }
 
-func NewDataIngestCapability(crProperties *dynatracev1alpha1.CapabilityProperties) *DataIngestCapability {
+func NewDataIngestCapability(crProperties *dynatracev1beta1.CapabilityProperties) *DataIngestCapability {
 	return &DataIngestCapability{
 		capabilityBase{
 			moduleName:     "data-ingest",
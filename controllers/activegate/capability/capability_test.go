@@ -4,27 +4,27 @@ import (
 	"reflect"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
 )
 
 func Test_capabilityBase_GetProperties(t *testing.T) {
-	props := &dynatracev1alpha1.CapabilityProperties{}
+	props := &dynatracev1beta1.CapabilityProperties{}
 
 	type fields struct {
-		properties *dynatracev1alpha1.CapabilityProperties
+		properties *dynatracev1beta1.CapabilityProperties
 	}
 	tests := []struct {
 		name   string
 		fields fields
-		want   *dynatracev1alpha1.CapabilityProperties
+		want   *dynatracev1beta1.CapabilityProperties
 	}{
 		{
 			name: "properties address is preserved",
 			fields: fields{
 				properties: props,
 			},
-			want: &dynatracev1alpha1.CapabilityProperties{},
+			want: &dynatracev1beta1.CapabilityProperties{},
 		},
 	}
 	for _, tt := range tests {
@@ -170,10 +170,10 @@ func TestCalculateStatefulSetName(t *testing.T) {
 }
 
 func TestNewKubeMonCapability(t *testing.T) {
-	props := &dynatracev1alpha1.CapabilityProperties{}
+	props := &dynatracev1beta1.CapabilityProperties{}
 
 	type args struct {
-		crProperties *dynatracev1alpha1.CapabilityProperties
+		crProperties *dynatracev1beta1.CapabilityProperties
 	}
 	tests := []struct {
 		name string
@@ -235,10 +235,10 @@ func TestNewKubeMonCapability(t *testing.T) {
 }
 
 func TestNewRoutingCapability(t *testing.T) {
-	props := &dynatracev1alpha1.CapabilityProperties{}
+	props := &dynatracev1beta1.CapabilityProperties{}
 
 	type args struct {
-		crProperties *dynatracev1alpha1.CapabilityProperties
+		crProperties *dynatracev1beta1.CapabilityProperties
 	}
 	tests := []struct {
 		name string
@@ -276,10 +276,10 @@ func TestNewRoutingCapability(t *testing.T) {
 }
 
 func TestNewMetricsCapability(t *testing.T) {
-	props := &dynatracev1alpha1.CapabilityProperties{}
+	props := &dynatracev1beta1.CapabilityProperties{}
 
 	type args struct {
-		crProperties *dynatracev1alpha1.CapabilityProperties
+		crProperties *dynatracev1beta1.CapabilityProperties
 	}
 	tests := []struct {
 		name string
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/internal/consts"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/internal/events"
@@ -17,6 +17,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -24,18 +25,21 @@ import (
 const (
 	containerPort   = 9999
 	dtDNSEntryPoint = "DT_DNS_ENTRY_POINT"
+
+	eventReasonServiceCreated = "ServiceCreated"
 )
 
 type Reconciler struct {
 	*sts.Reconciler
-	log logr.Logger
+	log      logr.Logger
+	recorder record.EventRecorder
 	capability.Capability
 }
 
-func NewReconciler(capability capability.Capability, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, log logr.Logger,
-	instance *dynatracev1alpha1.DynaKube, imageVersionProvider dtversion.ImageVersionProvider) *Reconciler {
+func NewReconciler(capability capability.Capability, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, log logr.Logger,
+	instance *dynatracev1beta1.DynaKube, imageVersionProvider dtversion.ImageVersionProvider) *Reconciler {
 	baseReconciler := sts.NewReconciler(
-		clt, apiReader, scheme, log, instance, imageVersionProvider, capability)
+		clt, apiReader, scheme, recorder, log, instance, imageVersionProvider, capability)
 
 	if capability.GetConfiguration().SetDnsEntryPoint {
 		baseReconciler.AddOnAfterStatefulSetCreateListener(addDNSEntryPoint(instance, capability.GetModuleName()))
@@ -52,6 +56,7 @@ func NewReconciler(capability capability.Capability, clt client.Client, apiReade
 	return &Reconciler{
 		Reconciler: baseReconciler,
 		log:        log,
+		recorder:   recorder,
 		Capability: capability,
 	}
 }
@@ -62,7 +67,7 @@ func setReadinessProbePort() events.StatefulSetEvent {
 	}
 }
 
-func setCommunicationsPort(_ *dynatracev1alpha1.DynaKube) events.StatefulSetEvent {
+func setCommunicationsPort(_ *dynatracev1beta1.DynaKube) events.StatefulSetEvent {
 	return func(sts *appsv1.StatefulSet) {
 		sts.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
 			{
@@ -77,7 +82,7 @@ func (r *Reconciler) calculateStatefulSetName() string {
 	return capability.CalculateStatefulSetName(r.Capability, r.Instance.Name)
 }
 
-func addDNSEntryPoint(instance *dynatracev1alpha1.DynaKube, moduleName string) events.StatefulSetEvent {
+func addDNSEntryPoint(instance *dynatracev1beta1.DynaKube, moduleName string) events.StatefulSetEvent {
 	return func(sts *appsv1.StatefulSet) {
 		sts.Spec.Template.Spec.Containers[0].Env = append(sts.Spec.Template.Spec.Containers[0].Env,
 			corev1.EnvVar{
@@ -87,7 +92,7 @@ func addDNSEntryPoint(instance *dynatracev1alpha1.DynaKube, moduleName string) e
 	}
 }
 
-func buildDNSEntryPoint(instance *dynatracev1alpha1.DynaKube, moduleName string) string {
+func buildDNSEntryPoint(instance *dynatracev1beta1.DynaKube, moduleName string) string {
 	return fmt.Sprintf("https://%s/communication", buildServiceHostName(instance.Name, moduleName))
 }
 
@@ -114,8 +119,11 @@ func (r *Reconciler) createServiceIfNotExists() (bool, error) {
 			return false, errors.WithStack(err)
 		}
 
-		err = r.Create(context.TODO(), service)
-		return true, errors.WithStack(err)
+		if err = r.Create(context.TODO(), service); err != nil {
+			return false, errors.WithStack(err)
+		}
+		r.recorder.Eventf(r.Instance, corev1.EventTypeNormal, eventReasonServiceCreated, "Created Service %s", service.Name)
+		return true, nil
 	}
 	return false, errors.WithStack(err)
 }
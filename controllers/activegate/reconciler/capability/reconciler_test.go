@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/internal/consts"
 	rsfs "github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
@@ -19,6 +19,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -30,7 +31,7 @@ const (
 )
 
 var metricsCapability = capability.NewDataIngestCapability(
-	&v1alpha1.CapabilityProperties{
+	&v1beta1.CapabilityProperties{
 		Enabled: true,
 	},
 )
@@ -50,7 +51,7 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 			},
 		}).
 		Build()
-	instance := &v1alpha1.DynaKube{
+	instance := &v1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: testNamespace,
 		}}
@@ -58,7 +59,7 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 		return dtversion.ImageVersion{}, nil
 	}
 
-	r := NewReconciler(metricsCapability, clt, clt, scheme.Scheme, log, instance, imgVerProvider)
+	r := NewReconciler(metricsCapability, clt, clt, scheme.Scheme, &record.FakeRecorder{}, log, instance, imgVerProvider)
 	require.NotNil(t, r)
 	require.NotNil(t, r.Client)
 	require.NotNil(t, r.Instance)
@@ -70,7 +71,7 @@ func TestReconcile(t *testing.T) {
 	t.Run(`reconcile custom properties`, func(t *testing.T) {
 		r := createDefaultReconciler(t)
 
-		metricsCapability.GetProperties().CustomProperties = &v1alpha1.DynaKubeValueSource{
+		metricsCapability.GetProperties().CustomProperties = &v1beta1.DynaKubeValueSource{
 			Value: testValue,
 		}
 		_, err := r.Reconcile()
@@ -129,7 +130,7 @@ func TestReconcile(t *testing.T) {
 		assert.NotNil(t, statefulSet)
 		assert.NoError(t, err)
 
-		r.Instance.Spec.Proxy = &v1alpha1.DynaKubeProxy{Value: testValue}
+		r.Instance.Spec.Proxy = &v1beta1.DynaKubeProxy{Value: testValue}
 		update, err = r.Reconcile()
 
 		assert.True(t, update)
@@ -204,7 +205,7 @@ func TestReconciler_calculateStatefulSetName(t *testing.T) {
 			name: "instance and module names are defined",
 			fields: fields{
 				Reconciler: &rsfs.Reconciler{
-					Instance: &v1alpha1.DynaKube{
+					Instance: &v1beta1.DynaKube{
 						ObjectMeta: metav1.ObjectMeta{
 							Name: "instanceName",
 						},
@@ -218,7 +219,7 @@ func TestReconciler_calculateStatefulSetName(t *testing.T) {
 			name: "empty instance name",
 			fields: fields{
 				Reconciler: &rsfs.Reconciler{
-					Instance: &v1alpha1.DynaKube{
+					Instance: &v1beta1.DynaKube{
 						ObjectMeta: metav1.ObjectMeta{
 							Name: "",
 						},
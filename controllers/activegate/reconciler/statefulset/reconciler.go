@@ -2,11 +2,12 @@ package statefulset
 
 import (
 	"context"
+	"fmt"
 	"hash/fnv"
 	"reflect"
 	"strconv"
 
-	"github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/internal/events"
 	"github.com/Dynatrace/dynatrace-operator/controllers/customproperties"
@@ -17,30 +18,39 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+const (
+	eventReasonStatefulSetCreated = "StatefulSetCreated"
+	eventReasonStatefulSetUpdated = "StatefulSetUpdated"
+	eventReasonStatefulSetDeleted = "StatefulSetDeleted"
+)
+
 type Reconciler struct {
 	client.Client
-	Instance                         *v1alpha1.DynaKube
+	Instance                         *v1beta1.DynaKube
 	apiReader                        client.Reader
 	scheme                           *runtime.Scheme
+	recorder                         record.EventRecorder
 	log                              logr.Logger
 	imageVersionProvider             dtversion.ImageVersionProvider
 	feature                          string
 	capabilityName                   string
 	serviceAccountOwner              string
-	capability                       *v1alpha1.CapabilityProperties
+	capability                       *v1beta1.CapabilityProperties
 	onAfterStatefulSetCreateListener []events.StatefulSetEvent
 	initContainersTemplates          []corev1.Container
 	containerVolumeMounts            []corev1.VolumeMount
 	volumes                          []corev1.Volume
 }
 
-func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, log logr.Logger,
-	instance *v1alpha1.DynaKube, imageVersionProvider dtversion.ImageVersionProvider, capability capability.Capability) *Reconciler {
+func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, log logr.Logger,
+	instance *v1beta1.DynaKube, imageVersionProvider dtversion.ImageVersionProvider, capability capability.Capability) *Reconciler {
 
 	serviceAccountOwner := capability.GetConfiguration().ServiceAccountOwner
 	if serviceAccountOwner == "" {
@@ -51,6 +61,7 @@ func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.S
 		Client:                           clt,
 		apiReader:                        apiReader,
 		scheme:                           scheme,
+		recorder:                         recorder,
 		log:                              log,
 		Instance:                         instance,
 		imageVersionProvider:             imageVersionProvider,
@@ -72,7 +83,7 @@ func (r *Reconciler) AddOnAfterStatefulSetCreateListener(event events.StatefulSe
 func (r *Reconciler) Reconcile() (update bool, err error) {
 	if r.capability.CustomProperties != nil {
 		err = customproperties.
-			NewReconciler(r, r.Instance, r.log, r.serviceAccountOwner, *r.capability.CustomProperties, r.scheme).
+			NewReconciler(r, r.Instance, r.log, r.serviceAccountOwner, *r.capability.CustomProperties, r.scheme, r.recorder).
 			Reconcile()
 		if err != nil {
 			r.log.Error(err, "could not reconcile custom properties")
@@ -113,7 +124,34 @@ func (r *Reconciler) manageStatefulSet() (bool, error) {
 		return updated, errors.WithStack(err)
 	}
 
-	return false, nil
+	return r.reconcileReadyCondition(desiredSts)
+}
+
+// reconcileReadyCondition sets the ActiveGateReady condition of the capability from the number of ready replicas.
+func (r *Reconciler) reconcileReadyCondition(desiredSts *appsv1.StatefulSet) (bool, error) {
+	currentSts, err := r.getStatefulSet(desiredSts)
+	if err != nil {
+		return false, err
+	}
+
+	var desired int32 = 1
+	if currentSts.Spec.Replicas != nil {
+		desired = *currentSts.Spec.Replicas
+	}
+	ready := currentSts.Status.ReadyReplicas
+
+	condition := metav1.Condition{
+		Type:    v1beta1.ActiveGateReadyConditionType(r.feature),
+		Status:  metav1.ConditionTrue,
+		Reason:  v1beta1.ReasonReady,
+		Message: fmt.Sprintf("All %d ActiveGate pods are ready", ready),
+	}
+	if ready < desired {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1beta1.ReasonRolloutInProgress
+		condition.Message = fmt.Sprintf("%d of %d ActiveGate pods are ready", ready, desired)
+	}
+	return r.Instance.Status.SetCondition(condition), nil
 }
 
 func (r *Reconciler) buildDesiredStatefulSet() (*appsv1.StatefulSet, error) {
@@ -148,7 +186,11 @@ func (r *Reconciler) createStatefulSetIfNotExists(desiredSts *appsv1.StatefulSet
 	_, err := r.getStatefulSet(desiredSts)
 	if err != nil && k8serrors.IsNotFound(errors.Cause(err)) {
 		r.log.Info("creating new stateful set for " + r.feature)
-		return true, r.Create(context.TODO(), desiredSts)
+		if err = r.Create(context.TODO(), desiredSts); err != nil {
+			return false, err
+		}
+		r.recorder.Eventf(r.Instance, corev1.EventTypeNormal, eventReasonStatefulSetCreated, "Created StatefulSet %s", desiredSts.Name)
+		return true, nil
 	}
 	return false, err
 }
@@ -166,6 +208,7 @@ func (r *Reconciler) updateStatefulSetIfOutdated(desiredSts *appsv1.StatefulSet)
 	if err = r.Update(context.TODO(), desiredSts); err != nil {
 		return false, err
 	}
+	r.recorder.Eventf(r.Instance, corev1.EventTypeNormal, eventReasonStatefulSetUpdated, "Updated StatefulSet %s", desiredSts.Name)
 	return true, err
 }
 
@@ -180,6 +223,7 @@ func (r *Reconciler) deleteStatefulSetIfOldLabelsAreUsed(desiredSts *appsv1.Stat
 		if err = r.Delete(context.TODO(), desiredSts); err != nil {
 			return false, err
 		}
+		r.recorder.Eventf(r.Instance, corev1.EventTypeNormal, eventReasonStatefulSetDeleted, "Deleted StatefulSet %s to recreate it with new labels", desiredSts.Name)
 		return true, nil
 	}
 
@@ -205,7 +249,7 @@ func (r *Reconciler) calculateCustomPropertyHash() (string, error) {
 	return strconv.FormatUint(uint64(hash.Sum32()), 10), nil
 }
 
-func (r *Reconciler) getDataFromCustomProperty(customProperties *v1alpha1.DynaKubeValueSource) (string, error) {
+func (r *Reconciler) getDataFromCustomProperty(customProperties *v1beta1.DynaKubeValueSource) (string, error) {
 	if customProperties.ValueFrom != "" {
 		namespace := r.Instance.Namespace
 		var secret corev1.Secret
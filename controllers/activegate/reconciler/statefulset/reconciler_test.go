@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/controllers/customproperties"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtversion"
@@ -17,7 +17,9 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -38,7 +40,7 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 			},
 		}).
 		Build()
-	instance := &dynatracev1alpha1.DynaKube{
+	instance := &dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: testNamespace,
 		}}
@@ -48,7 +50,7 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 
 	capability.NewRoutingCapability(&instance.Spec.RoutingSpec.CapabilityProperties)
 
-	r := NewReconciler(clt, clt, scheme.Scheme, log, instance, imgVerProvider,
+	r := NewReconciler(clt, clt, scheme.Scheme, &record.FakeRecorder{}, log, instance, imgVerProvider,
 		capability.NewRoutingCapability(&instance.Spec.RoutingSpec.CapabilityProperties))
 	require.NotNil(t, r)
 	require.NotNil(t, r.Client)
@@ -63,7 +65,7 @@ func createDefaultReconciler(t *testing.T) *Reconciler {
 func TestReconcile(t *testing.T) {
 	t.Run(`reconcile custom properties`, func(t *testing.T) {
 		r := createDefaultReconciler(t)
-		r.Instance.Spec.RoutingSpec.CustomProperties = &dynatracev1alpha1.DynaKubeValueSource{
+		r.Instance.Spec.RoutingSpec.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{
 			Value: testValue,
 		}
 		_, err := r.Reconcile()
@@ -103,7 +105,7 @@ func TestReconcile(t *testing.T) {
 		assert.NotNil(t, statefulSet)
 		assert.NoError(t, err)
 
-		r.Instance.Spec.Proxy = &dynatracev1alpha1.DynaKubeProxy{Value: testValue}
+		r.Instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
 		update, err = r.Reconcile()
 
 		assert.True(t, update)
@@ -124,6 +126,39 @@ func TestReconcile(t *testing.T) {
 		}
 		assert.True(t, found)
 	})
+	t.Run(`set ready condition`, func(t *testing.T) {
+		r := createDefaultReconciler(t)
+		conditionType := dynatracev1beta1.ActiveGateReadyConditionType(r.feature)
+
+		_, err := r.Reconcile()
+		require.NoError(t, err)
+
+		update, err := r.Reconcile()
+		require.NoError(t, err)
+		assert.True(t, update)
+
+		condition := meta.FindStatusCondition(r.Instance.Status.Conditions, conditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, condition.Reason)
+		assert.Equal(t, "0 of 1 ActiveGate pods are ready", condition.Message)
+
+		statefulSet := &appsv1.StatefulSet{}
+		err = r.Get(context.TODO(), client.ObjectKey{Name: r.Instance.Name + "-" + r.feature, Namespace: r.Instance.Namespace}, statefulSet)
+		require.NoError(t, err)
+
+		statefulSet.Status.ReadyReplicas = 1
+		require.NoError(t, r.Update(context.TODO(), statefulSet))
+
+		update, err = r.Reconcile()
+		require.NoError(t, err)
+		assert.True(t, update)
+		assert.True(t, meta.IsStatusConditionTrue(r.Instance.Status.Conditions, conditionType))
+
+		update, err = r.Reconcile()
+		require.NoError(t, err)
+		assert.False(t, update)
+	})
 }
 
 func TestReconcile_GetStatefulSet(t *testing.T) {
@@ -181,7 +216,7 @@ func TestReconcile_UpdateStatefulSetIfOutdated(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, updated)
 
-	r.Instance.Spec.Proxy = &dynatracev1alpha1.DynaKubeProxy{Value: testValue}
+	r.Instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
 	desiredSts, err = r.buildDesiredStatefulSet()
 	require.NoError(t, err)
 
@@ -209,7 +244,7 @@ func TestReconcile_DeleteStatefulSetIfOldLabelsAreUsed(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, deleted)
 
-	r.Instance.Spec.Proxy = &dynatracev1alpha1.DynaKubeProxy{Value: testValue}
+	r.Instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
 	desiredSts, err = r.buildDesiredStatefulSet()
 	require.NoError(t, err)
 	correctLabels := desiredSts.Labels
@@ -229,12 +264,12 @@ func TestReconcile_GetCustomPropertyHash(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, hash)
 
-	r.Instance.Spec.RoutingSpec.CustomProperties = &dynatracev1alpha1.DynaKubeValueSource{Value: testValue}
+	r.Instance.Spec.RoutingSpec.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{Value: testValue}
 	hash, err = r.calculateCustomPropertyHash()
 	assert.NoError(t, err)
 	assert.NotEmpty(t, hash)
 
-	r.Instance.Spec.RoutingSpec.CustomProperties = &dynatracev1alpha1.DynaKubeValueSource{ValueFrom: testName}
+	r.Instance.Spec.RoutingSpec.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{ValueFrom: testName}
 	hash, err = r.calculateCustomPropertyHash()
 	assert.Error(t, err)
 	assert.Empty(t, hash)
@@ -6,7 +6,7 @@ import (
 	"hash/fnv"
 	"strconv"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/internal/events"
 	"github.com/Dynatrace/dynatrace-operator/controllers/customproperties"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtpullsecret"
@@ -27,7 +27,6 @@ const (
 	kubernetesBetaArch = "beta.kubernetes.io/arch"
 	kubernetesBetaOS   = "beta.kubernetes.io/os"
 
-	amd64 = "amd64"
 	linux = "linux"
 
 	AnnotationTemplateHash    = "internal.operator.dynatrace.com/template-hash"
@@ -46,8 +45,8 @@ const (
 )
 
 type statefulSetProperties struct {
-	*dynatracev1alpha1.DynaKube
-	*dynatracev1alpha1.CapabilityProperties
+	*dynatracev1beta1.DynaKube
+	*dynatracev1beta1.CapabilityProperties
 	customPropertiesHash    string
 	kubeSystemUID           types.UID
 	feature                 string
@@ -59,7 +58,7 @@ type statefulSetProperties struct {
 	volumes                 []corev1.Volume
 }
 
-func NewStatefulSetProperties(instance *dynatracev1alpha1.DynaKube, capabilityProperties *dynatracev1alpha1.CapabilityProperties,
+func NewStatefulSetProperties(instance *dynatracev1beta1.DynaKube, capabilityProperties *dynatracev1beta1.CapabilityProperties,
 	kubeSystemUID types.UID, customPropertiesHash string, feature string, capabilityName string, serviceAccountOwner string,
 	initContainers []corev1.Container, containerVolumeMounts []corev1.VolumeMount, volumes []corev1.Volume) *statefulSetProperties {
 	if serviceAccountOwner == "" {
@@ -152,7 +151,7 @@ func buildInitContainers(stsProperties *statefulSetProperties) []corev1.Containe
 
 func buildContainer(stsProperties *statefulSetProperties) corev1.Container {
 	return corev1.Container{
-		Name:            dynatracev1alpha1.OperatorName,
+		Name:            dynatracev1beta1.OperatorName,
 		Image:           stsProperties.DynaKube.ActiveGateImage(),
 		Resources:       stsProperties.CapabilityProperties.Resources,
 		ImagePullPolicy: corev1.PullAlways,
@@ -229,8 +228,8 @@ func buildEnvs(stsProperties *statefulSetProperties) []corev1.EnvVar {
 	}
 	envs = append(envs, stsProperties.Env...)
 
-	if !isProxyNilOrEmpty(stsProperties.Spec.Proxy) {
-		envs = append(envs, buildProxyEnv(stsProperties.Spec.Proxy))
+	if proxy := stsProperties.CommunicationProxy(); proxy != nil {
+		envs = append(envs, buildProxyEnv(proxy))
 	}
 	if stsProperties.Group != "" {
 		envs = append(envs, corev1.EnvVar{Name: DTGroup, Value: stsProperties.Group})
@@ -242,7 +241,7 @@ func buildEnvs(stsProperties *statefulSetProperties) []corev1.EnvVar {
 	return envs
 }
 
-func buildProxyEnv(proxy *dynatracev1alpha1.DynaKubeProxy) corev1.EnvVar {
+func buildProxyEnv(proxy *dynatracev1beta1.DynaKubeProxySource) corev1.EnvVar {
 	if proxy.ValueFrom != "" {
 		return corev1.EnvVar{
 			Name: DTInternalProxy,
@@ -268,12 +267,13 @@ func determineServiceAccountName(stsProperties *statefulSetProperties) string {
 	return stsProperties.ServiceAccountName
 }
 
+// buildKubernetesExpression selects Linux nodes with an architecture OneAgent can be deployed to
 func buildKubernetesExpression(archKey string, osKey string) []corev1.NodeSelectorRequirement {
 	return []corev1.NodeSelectorRequirement{
 		{
 			Key:      archKey,
 			Operator: corev1.NodeSelectorOpIn,
-			Values:   []string{amd64},
+			Values:   append([]string{}, dynatracev1beta1.OneAgentArchitectures...),
 		},
 		{
 			Key:      osKey,
@@ -283,16 +283,12 @@ func buildKubernetesExpression(archKey string, osKey string) []corev1.NodeSelect
 	}
 }
 
-func isCustomPropertiesNilOrEmpty(customProperties *dynatracev1alpha1.DynaKubeValueSource) bool {
+func isCustomPropertiesNilOrEmpty(customProperties *dynatracev1beta1.DynaKubeValueSource) bool {
 	return customProperties == nil ||
 		(customProperties.Value == "" &&
 			customProperties.ValueFrom == "")
 }
 
-func isProxyNilOrEmpty(proxy *dynatracev1alpha1.DynaKubeProxy) bool {
-	return proxy == nil || (proxy.Value == "" && proxy.ValueFrom == "")
-}
-
 func generateStatefulSetHash(sts *appsv1.StatefulSet) (string, error) {
 	data, err := json.Marshal(sts)
 	if err != nil {
@@ -3,7 +3,7 @@ package statefulset
 import (
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/customproperties"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtpullsecret"
 	"github.com/Dynatrace/dynatrace-operator/deploymentmetadata"
@@ -26,7 +26,7 @@ const (
 )
 
 func TestNewStatefulSetBuilder(t *testing.T) {
-	stsBuilder := NewStatefulSetProperties(&dynatracev1alpha1.DynaKube{}, &dynatracev1alpha1.CapabilityProperties{},
+	stsBuilder := NewStatefulSetProperties(&dynatracev1beta1.DynaKube{}, &dynatracev1beta1.CapabilityProperties{},
 		testUID, testValue, "", "", "", nil, nil, nil)
 	assert.NotNil(t, stsBuilder)
 	assert.NotNil(t, stsBuilder.DynaKube)
@@ -99,7 +99,7 @@ func TestStatefulSet_TemplateSpec(t *testing.T) {
 			{
 				Key:      kubernetesBetaArch,
 				Operator: corev1.NodeSelectorOpIn,
-				Values:   []string{amd64},
+				Values:   []string{"amd64", "arm64", "ppc64le", "s390x"},
 			},
 			{
 				Key:      kubernetesBetaOS,
@@ -112,7 +112,7 @@ func TestStatefulSet_TemplateSpec(t *testing.T) {
 			{
 				Key:      kubernetesArch,
 				Operator: corev1.NodeSelectorOpIn,
-				Values:   []string{amd64},
+				Values:   []string{"amd64", "arm64", "ppc64le", "s390x"},
 			},
 			{
 				Key:      kubernetesOS,
@@ -132,7 +132,7 @@ func TestStatefulSet_Container(t *testing.T) {
 	container := buildContainer(NewStatefulSetProperties(instance, capabilityProperties,
 		"", "", "", "", "", nil, nil, nil))
 
-	assert.Equal(t, dynatracev1alpha1.OperatorName, container.Name)
+	assert.Equal(t, dynatracev1beta1.OperatorName, container.Name)
 	assert.Equal(t, instance.ActiveGateImage(), container.Image)
 	assert.Empty(t, container.Resources)
 	assert.Equal(t, corev1.PullAlways, container.ImagePullPolicy)
@@ -153,7 +153,7 @@ func TestStatefulSet_Volumes(t *testing.T) {
 		assert.Empty(t, volumes)
 	})
 	t.Run(`custom properties from value string`, func(t *testing.T) {
-		capabilityProperties.CustomProperties = &dynatracev1alpha1.DynaKubeValueSource{
+		capabilityProperties.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{
 			Value: testValue,
 		}
 		volumes := buildVolumes(NewStatefulSetProperties(instance, capabilityProperties,
@@ -172,7 +172,7 @@ func TestStatefulSet_Volumes(t *testing.T) {
 		}, customPropertiesVolume.Secret.Items)
 	})
 	t.Run(`custom properties from valueFrom`, func(t *testing.T) {
-		capabilityProperties.CustomProperties = &dynatracev1alpha1.DynaKubeValueSource{
+		capabilityProperties.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{
 			ValueFrom: testKey,
 		}
 		volumes := buildVolumes(NewStatefulSetProperties(instance, capabilityProperties,
@@ -209,7 +209,7 @@ func TestStatefulSet_Env(t *testing.T) {
 		}, envVars)
 	})
 	t.Run(`with proxy from value`, func(t *testing.T) {
-		instance.Spec.Proxy = &dynatracev1alpha1.DynaKubeProxy{Value: testValue}
+		instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
 		envVars := buildEnvs(NewStatefulSetProperties(instance, capabilityProperties,
 			"", "", "", "", "", nil, nil, nil))
 
@@ -219,7 +219,7 @@ func TestStatefulSet_Env(t *testing.T) {
 		})
 	})
 	t.Run(`with proxy from value source`, func(t *testing.T) {
-		instance.Spec.Proxy = &dynatracev1alpha1.DynaKubeProxy{ValueFrom: testName}
+		instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{ValueFrom: testName}
 		envVars := buildEnvs(NewStatefulSetProperties(instance, capabilityProperties,
 			"", "", "", "", "", nil, nil, nil))
 
@@ -232,6 +232,19 @@ func TestStatefulSet_Env(t *testing.T) {
 			}
 		}
 	})
+	t.Run(`with communication proxy`, func(t *testing.T) {
+		instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{
+			Value:         testValue,
+			Communication: &dynatracev1beta1.DynaKubeProxySource{Value: "http://communication-proxy:8080"},
+		}
+		envVars := buildEnvs(NewStatefulSetProperties(instance, capabilityProperties,
+			"", "", "", "", "", nil, nil, nil))
+
+		assert.Contains(t, envVars, corev1.EnvVar{
+			Name:  DTInternalProxy,
+			Value: "http://communication-proxy:8080",
+		})
+	})
 	t.Run(`with networkzone`, func(t *testing.T) {
 		instance := buildTestInstance()
 		instance.Spec.NetworkZone = testName
@@ -272,7 +285,7 @@ func TestStatefulSet_VolumeMounts(t *testing.T) {
 		assert.Empty(t, volumeMounts)
 	})
 	t.Run(`with custom properties`, func(t *testing.T) {
-		capabilityProperties.CustomProperties = &dynatracev1alpha1.DynaKubeValueSource{Value: testValue}
+		capabilityProperties.CustomProperties = &dynatracev1beta1.DynaKubeValueSource{Value: testValue}
 		volumeMounts := buildVolumeMounts(NewStatefulSetProperties(instance, capabilityProperties,
 			"", "", "", "", "", nil, nil, nil))
 
@@ -314,17 +327,17 @@ func TestStatefulSet_Resources(t *testing.T) {
 	assert.True(t, quantityMemoryRequest.Equal(container.Resources.Requests[corev1.ResourceMemory]))
 }
 
-func buildTestInstance() *dynatracev1alpha1.DynaKube {
+func buildTestInstance() *dynatracev1beta1.DynaKube {
 	replicas := int32(3)
 
-	return &dynatracev1alpha1.DynaKube{
+	return &dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      testName,
 			Namespace: testNamespace,
 		},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			RoutingSpec: dynatracev1alpha1.RoutingSpec{
-				CapabilityProperties: dynatracev1alpha1.CapabilityProperties{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			RoutingSpec: dynatracev1beta1.RoutingSpec{
+				CapabilityProperties: dynatracev1beta1.CapabilityProperties{
 					Replicas:    &replicas,
 					Tolerations: []corev1.Toleration{{Value: testValue}},
 					NodeSelector: map[string]string{
@@ -20,6 +20,7 @@ import "time"
 
 const (
 	AgentConfDir          = "agent/conf"
+	DaemonSetName         = "dynatrace-oneagent-csi-driver"
 	DataPath              = "data"
 	DatastorageDir        = "datastorage"
 	DriverName            = "csi.oneagent.dynatrace.com"
@@ -6,7 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/Dynatrace/dynatrace-operator/webhook"
@@ -111,7 +111,7 @@ func TestCSIDriverServer_NewBindConfig(t *testing.T) {
 	t.Run(`create correct bind config`, func(t *testing.T) {
 		clt := fake.NewClient(
 			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{webhook.LabelInstance: dkName}}},
-			&dynatracev1alpha1.DynaKube{
+			&dynatracev1beta1.DynaKube{
 				ObjectMeta: metav1.ObjectMeta{Name: dkName},
 			},
 		)
@@ -1,9 +1,11 @@
 package csigc
 
 import (
+	"os"
 	"path/filepath"
 
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/controllers/dtversion"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -26,11 +28,15 @@ func (gc *CSIGarbageCollector) runBinaryGarbageCollection(tenantUUID string, lat
 		return errors.WithStack(err)
 	}
 
+	installedVersion := gc.getInstalledVersion(fs, tenantUUID, logger)
+
 	for _, fileInfo := range versionReferences {
 		version := fileInfo.Name()
 		references := filepath.Join(versionReferencesBase, version)
 
-		shouldDelete := isNotLatestVersion(version, latestVersion, logger) &&
+		shouldDelete := isVersion(version, logger) &&
+			isNotLatestVersion(version, latestVersion, logger) &&
+			isNotInstalledVersion(version, installedVersion, logger) &&
 			shouldDeleteVersion(fs, references, logger.WithValues("version", version))
 
 		if shouldDelete {
@@ -44,6 +50,17 @@ func (gc *CSIGarbageCollector) runBinaryGarbageCollection(tenantUUID string, lat
 	return nil
 }
 
+// getInstalledVersion returns the version currently provisioned for new pods, which differs from the latest version if
+// another version has been configured, e.g. after a downgrade.
+func (gc *CSIGarbageCollector) getInstalledVersion(fs *afero.Afero, tenantUUID string, logger logr.Logger) string {
+	versionFile := filepath.Join(gc.opts.RootDir, dtcsi.DataPath, tenantUUID, dtcsi.VersionDir)
+	b, err := fs.ReadFile(versionFile)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Error(err, "failed to query installed version", "path", versionFile)
+	}
+	return string(b)
+}
+
 func shouldDeleteVersion(fs *afero.Afero, references string, logger logr.Logger) bool {
 	podReferences, err := fs.ReadDir(references)
 	if err != nil {
@@ -58,6 +75,16 @@ func shouldDeleteVersion(fs *afero.Afero, references string, logger logr.Logger)
 	return true
 }
 
+// isVersion prevents deleting directories which don't belong to an agent version.
+func isVersion(version string, logger logr.Logger) bool {
+	if _, err := dtversion.ParseVersion(version); err != nil {
+		logger.Info("skipped, not a version", "name", version)
+		return false
+	}
+
+	return true
+}
+
 func isNotLatestVersion(version string, latestVersion string, logger logr.Logger) bool {
 	if version == latestVersion {
 		logger.Info("skipped, is latest")
@@ -67,6 +94,15 @@ func isNotLatestVersion(version string, latestVersion string, logger logr.Logger
 	return true
 }
 
+func isNotInstalledVersion(version string, installedVersion string, logger logr.Logger) bool {
+	if version == installedVersion {
+		logger.Info("skipped, is installed", "version", version)
+		return false
+	}
+
+	return true
+}
+
 func removeUnusedVersion(fs *afero.Afero, binaryPath string, references string, logger logr.Logger) {
 	if err := fs.RemoveAll(binaryPath); err != nil {
 		logger.Info("delete failed", "path", binaryPath)
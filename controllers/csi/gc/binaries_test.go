@@ -59,6 +59,29 @@ func TestBinaryGarbageCollector_removesUnused(t *testing.T) {
 	gc.assertVersionNotExists(t, version_1, version_3)
 }
 
+func TestBinaryGarbageCollector_ignoresInstalled(t *testing.T) {
+	gc := newMockGarbageCollector()
+	gc.mockUnusedVersions(version_1, version_2, version_3)
+	_ = afero.WriteFile(gc.fs, filepath.Join(rootDir, dtcsi.DataPath, tenantUUID, dtcsi.VersionDir), []byte(version_1), 0644)
+
+	err := gc.runBinaryGarbageCollection(tenantUUID, version_3)
+
+	assert.NoError(t, err)
+	gc.assertVersionExists(t, version_1, version_3)
+	gc.assertVersionNotExists(t, version_2)
+}
+
+func TestBinaryGarbageCollector_ignoresNonVersions(t *testing.T) {
+	gc := newMockGarbageCollector()
+	gc.mockUnusedVersions(version_1, "lost+found")
+
+	err := gc.runBinaryGarbageCollection(tenantUUID, version_2)
+
+	assert.NoError(t, err)
+	gc.assertVersionNotExists(t, version_1)
+	gc.assertVersionExists(t, "lost+found")
+}
+
 func TestBinaryGarbageCollector_ignoresUsed(t *testing.T) {
 	gc := newMockGarbageCollector()
 	gc.mockUsedVersions(version_1, version_2, version_3)
@@ -3,7 +3,7 @@ package csigc
 import (
 	"context"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
@@ -39,7 +39,7 @@ func NewReconciler(client client.Client, opts dtcsi.CSIOptions) *CSIGarbageColle
 
 func (gc *CSIGarbageCollector) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&dynatracev1alpha1.DynaKube{}).
+		For(&dynatracev1beta1.DynaKube{}).
 		Complete(gc)
 }
 
@@ -49,7 +49,7 @@ func (gc *CSIGarbageCollector) Reconcile(ctx context.Context, request reconcile.
 	gc.logger.Info("running OneAgent garbage collection", "namespace", request.Namespace, "name", request.Name)
 	reconcileResult := reconcile.Result{RequeueAfter: gc.opts.GCInterval}
 
-	var dk dynatracev1alpha1.DynaKube
+	var dk dynatracev1beta1.DynaKube
 	if err := gc.client.Get(ctx, request.NamespacedName, &dk); err != nil {
 		if k8serrors.IsNotFound(err) {
 			gc.logger.Error(err, "given DynaKube object not found")
@@ -72,16 +72,16 @@ func (gc *CSIGarbageCollector) Reconcile(ctx context.Context, request reconcile.
 		return reconcileResult, nil
 	}
 
-	ci, err := dtc.GetConnectionInfo()
+	ci, err := dtc.GetConnectionInfo(ctx)
 	if err != nil {
 		gc.logger.Error(err, "failed to fetch connection info")
-		return reconcileResult, nil
+		return gc.resultFor(err), nil
 	}
 
-	ver, err := dtc.GetLatestAgentVersion(dtclient.OsUnix, dtclient.InstallerTypePaaS)
+	ver, err := dtc.GetLatestAgentVersion(ctx, dtclient.OsUnix, dtclient.InstallerTypePaaS)
 	if err != nil {
 		gc.logger.Error(err, "failed to query OneAgent version")
-		return reconcileResult, nil
+		return gc.resultFor(err), nil
 	}
 
 	gc.logger.Info("running binary garbage collection")
@@ -92,3 +92,12 @@ func (gc *CSIGarbageCollector) Reconcile(ctx context.Context, request reconcile.
 
 	return reconcileResult, nil
 }
+
+// resultFor returns the reconcile result after a failed Dynatrace API request, which is the regular garbage collection
+// interval unless the API asked for a shorter delay before retrying.
+func (gc *CSIGarbageCollector) resultFor(err error) reconcile.Result {
+	if retryAfter, ok := dtclient.RetryAfter(err); ok && retryAfter < gc.opts.GCInterval {
+		return reconcile.Result{RequeueAfter: retryAfter}
+	}
+	return reconcile.Result{RequeueAfter: gc.opts.GCInterval}
+}
@@ -2,6 +2,7 @@ package csiprovisioner
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -19,23 +20,24 @@ type installAgentConfig struct {
 	logger    logr.Logger
 	dtc       dtclient.Client
 	arch      string
+	version   string
 	targetDir string
 	fs        afero.Fs
 }
 
-func newInstallAgentConfig(logger logr.Logger, dtc dtclient.Client, arch, targetDir string) *installAgentConfig {
+func newInstallAgentConfig(logger logr.Logger, dtc dtclient.Client, arch, version, targetDir string) *installAgentConfig {
 	return &installAgentConfig{
 		logger:    logger,
 		dtc:       dtc,
 		arch:      arch,
+		version:   version,
 		targetDir: targetDir,
 		fs:        afero.NewOsFs(),
 	}
 }
 
-func installAgent(installAgentCfg *installAgentConfig) error {
+func installAgent(ctx context.Context, installAgentCfg *installAgentConfig) error {
 	logger := installAgentCfg.logger
-	dtc := installAgentCfg.dtc
 	arch := installAgentCfg.arch
 	targetDir := installAgentCfg.targetDir
 	fs := installAgentCfg.fs
@@ -51,11 +53,11 @@ func installAgent(installAgentCfg *installAgentConfig) error {
 		}
 	}()
 
-	logger.Info("Downloading OneAgent package", "architecture", arch)
+	logger.Info("Downloading OneAgent package", "architecture", arch, "version", installAgentCfg.version)
 
-	r, err := dtc.GetLatestAgent(dtclient.OsUnix, dtclient.InstallerTypePaaS, dtclient.FlavorMultidistro, arch)
+	r, err := downloadAgent(ctx, installAgentCfg)
 	if err != nil {
-		return fmt.Errorf("failed to fetch latest OneAgent version: %w", err)
+		return err
 	}
 	defer func(r io.ReadCloser) { _ = r.Close() }(r)
 
@@ -94,6 +96,26 @@ func installAgent(installAgentCfg *installAgentConfig) error {
 	return nil
 }
 
+// downloadAgent downloads the OneAgent package with the configured version, or the latest one if no version is set.
+func downloadAgent(ctx context.Context, installAgentCfg *installAgentConfig) (io.ReadCloser, error) {
+	dtc := installAgentCfg.dtc
+	arch := installAgentCfg.arch
+
+	if installAgentCfg.version == "" {
+		r, err := dtc.GetLatestAgent(ctx, dtclient.OsUnix, dtclient.InstallerTypePaaS, dtclient.FlavorMultidistro, arch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch latest OneAgent version: %w", err)
+		}
+		return r, nil
+	}
+
+	r, err := dtc.GetAgent(ctx, dtclient.OsUnix, dtclient.InstallerTypePaaS, dtclient.FlavorMultidistro, arch, installAgentCfg.version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OneAgent version %s: %w", installAgentCfg.version, err)
+	}
+	return r, nil
+}
+
 func unzip(r *zip.Reader, installAgentCfg *installAgentConfig) error {
 	outDir := installAgentCfg.targetDir
 	logger := installAgentCfg.logger
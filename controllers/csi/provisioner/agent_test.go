@@ -2,6 +2,7 @@ package csiprovisioner
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -43,7 +44,7 @@ func TestOneAgentProvisioner_InstallAgent(t *testing.T) {
 			fs: fs,
 		}
 
-		err := installAgent(installAgentCfg)
+		err := installAgent(context.TODO(), installAgentCfg)
 		assert.EqualError(t, err, "failed to create temporary file for download: "+errorMsg)
 	})
 	t.Run(`error when downloading latest agent`, func(t *testing.T) {
@@ -60,9 +61,27 @@ func TestOneAgentProvisioner_InstallAgent(t *testing.T) {
 			logger: log,
 		}
 
-		err := installAgent(installAgentCfg)
+		err := installAgent(context.TODO(), installAgentCfg)
 		assert.EqualError(t, err, "failed to fetch latest OneAgent version: "+errorMsg)
 	})
+	t.Run(`error when downloading agent version`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.
+			On("GetAgent",
+				dtclient.OsUnix, dtclient.InstallerTypePaaS,
+				mock.AnythingOfType("string"), mock.AnythingOfType("string"), agentVersion).
+			Return(ioutil.NopCloser(strings.NewReader("")), fmt.Errorf(errorMsg))
+		installAgentCfg := &installAgentConfig{
+			fs:      fs,
+			dtc:     dtc,
+			logger:  log,
+			version: agentVersion,
+		}
+
+		err := installAgent(context.TODO(), installAgentCfg)
+		assert.EqualError(t, err, "failed to fetch OneAgent version "+agentVersion+": "+errorMsg)
+	})
 	t.Run(`error unzipping file`, func(t *testing.T) {
 		fs := afero.NewMemMapFs()
 		zipFile := setupTestZip(t, fs)
@@ -83,7 +102,7 @@ func TestOneAgentProvisioner_InstallAgent(t *testing.T) {
 			logger: log,
 		}
 
-		err = installAgent(installAgentCfg)
+		err = installAgent(context.TODO(), installAgentCfg)
 		assert.EqualError(t, err, "failed to unzip file: illegal file path: test.txt")
 	})
 	t.Run(`downloading and unzipping agent`, func(t *testing.T) {
@@ -107,7 +126,7 @@ func TestOneAgentProvisioner_InstallAgent(t *testing.T) {
 			targetDir: testDir,
 		}
 
-		err = installAgent(installAgentCfg)
+		err = installAgent(context.TODO(), installAgentCfg)
 		assert.NoError(t, err)
 
 		for _, dir := range []string{
@@ -24,8 +24,9 @@ import (
 	"runtime"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/controllers/dtversion"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/logger"
@@ -62,7 +63,7 @@ func NewReconciler(mgr manager.Manager, opts dtcsi.CSIOptions) *OneAgentProvisio
 
 func (r *OneAgentProvisioner) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&dynatracev1alpha1.DynaKube{}).
+		For(&dynatracev1beta1.DynaKube{}).
 		Complete(r)
 }
 
@@ -105,14 +106,18 @@ func (r *OneAgentProvisioner) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, err
 	}
 
-	if err = r.updateAgent(dk, dtc, envDir, rlog); err != nil {
+	if err = r.updateAgent(ctx, dk, dtc, envDir, rlog); err != nil {
+		if retryAfter, ok := dtclient.RetryAfter(err); ok {
+			rlog.Info("Request limit for Dynatrace API reached! Postponing OneAgent download", "retryAfter", retryAfter)
+			return reconcile.Result{RequeueAfter: retryAfter}, nil
+		}
 		return reconcile.Result{}, err
 	}
 
 	return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 }
 
-func buildDtc(r *OneAgentProvisioner, ctx context.Context, dk *dynatracev1alpha1.DynaKube) (dtclient.Client, error) {
+func buildDtc(r *OneAgentProvisioner, ctx context.Context, dk *dynatracev1beta1.DynaKube) (dtclient.Client, error) {
 	var tkns corev1.Secret
 	if err := r.client.Get(ctx, client.ObjectKey{Name: dk.Tokens(), Namespace: dk.Namespace}, &tkns); err != nil {
 		return nil, fmt.Errorf("failed to query tokens: %w", err)
@@ -126,26 +131,27 @@ func buildDtc(r *OneAgentProvisioner, ctx context.Context, dk *dynatracev1alpha1
 	return dtc, nil
 }
 
-func getCodeModule(ctx context.Context, clt client.Client, namespacedName types.NamespacedName) (*dynatracev1alpha1.DynaKube, error) {
-	var dk dynatracev1alpha1.DynaKube
+func getCodeModule(ctx context.Context, clt client.Client, namespacedName types.NamespacedName) (*dynatracev1beta1.DynaKube, error) {
+	var dk dynatracev1beta1.DynaKube
 	if err := clt.Get(ctx, namespacedName, &dk); err != nil {
 		return nil, err
 	}
 
-	if !dk.Spec.CodeModules.Enabled || hasInvalidCSIVolumeSource(dk) {
+	if !dk.NeedsAppInjection() || hasInvalidCSIVolumeSource(dk) {
 		return nil, nil
 	}
 
 	return &dk, nil
 }
 
-func hasInvalidCSIVolumeSource(dk dynatracev1alpha1.DynaKube) bool {
-	return dk.Spec.CodeModules.Volume.CSI == nil || dk.Spec.CodeModules.Volume.CSI.Driver != dtcsi.DriverName
+func hasInvalidCSIVolumeSource(dk dynatracev1beta1.DynaKube) bool {
+	appInjectionSpec := dk.AppInjectionSpec()
+	return appInjectionSpec == nil || appInjectionSpec.Volume.CSI == nil || appInjectionSpec.Volume.CSI.Driver != dtcsi.DriverName
 }
 
-func (r *OneAgentProvisioner) updateAgent(dk *dynatracev1alpha1.DynaKube, dtc dtclient.Client, envDir string, logger logr.Logger) error {
+func (r *OneAgentProvisioner) updateAgent(ctx context.Context, dk *dynatracev1beta1.DynaKube, dtc dtclient.Client, envDir string, logger logr.Logger) error {
 	versionFile := filepath.Join(envDir, dtcsi.VersionDir)
-	ver := dk.Status.LatestAgentVersionUnixPaas
+	ver := dk.CodeModulesVersion()
 
 	var oldVer string
 	if b, err := afero.ReadFile(r.fs, versionFile); err != nil && !os.IsNotExist(err) {
@@ -154,8 +160,13 @@ func (r *OneAgentProvisioner) updateAgent(dk *dynatracev1alpha1.DynaKube, dtc dt
 		oldVer = string(b)
 	}
 
+	if ver != oldVer && isDowngrade(oldVer, ver) && !dk.FeatureAllowOneAgentDowngrades() {
+		logger.Info("Not downgrading OneAgent, downgrades aren't allowed", "installedVersion", oldVer, "version", ver)
+		return nil
+	}
+
 	if ver != oldVer {
-		if err := r.installAgentVersion(ver, envDir, dtc, logger); err != nil {
+		if err := r.installAgentVersion(ctx, ver, envDir, dtc, logger); err != nil {
 			return err
 		}
 	}
@@ -163,11 +174,25 @@ func (r *OneAgentProvisioner) updateAgent(dk *dynatracev1alpha1.DynaKube, dtc dt
 	return nil
 }
 
-func (r *OneAgentProvisioner) installAgentVersion(version string, envDir string, dtc dtclient.Client, logger logr.Logger) error {
+// isDowngrade returns true if ver is older than the installed version oldVer. Versions which can't be compared are not
+// considered a downgrade.
+func isDowngrade(oldVer string, ver string) bool {
+	oldInfo, err := dtversion.ParseVersion(oldVer)
+	if err != nil {
+		return false
+	}
+	info, err := dtversion.ParseVersion(ver)
+	if err != nil {
+		return false
+	}
+	return dtversion.CompareVersions(oldInfo, info) > 0
+}
+
+func (r *OneAgentProvisioner) installAgentVersion(ctx context.Context, version string, envDir string, dtc dtclient.Client, logger logr.Logger) error {
 	versionFile := filepath.Join(envDir, dtcsi.VersionDir)
-	arch := dtclient.ArchX86
-	if runtime.GOARCH == "arm64" {
-		arch = dtclient.ArchARM
+	arch := dtclient.InstallerArch(runtime.GOARCH)
+	if arch == "" {
+		return fmt.Errorf("OneAgent isn't available for architecture %s", runtime.GOARCH)
 	}
 
 	gcDir := filepath.Join(envDir, dtcsi.GarbageCollectionPath, version)
@@ -179,9 +204,9 @@ func (r *OneAgentProvisioner) installAgentVersion(version string, envDir string,
 	targetDir := filepath.Join(envDir, "bin", version)
 
 	if _, err := r.fs.Stat(targetDir); os.IsNotExist(err) {
-		installAgentCfg := newInstallAgentConfig(logger, dtc, arch, targetDir)
+		installAgentCfg := newInstallAgentConfig(logger, dtc, arch, version, targetDir)
 
-		if err := installAgent(installAgentCfg); err != nil {
+		if err := installAgent(ctx, installAgentCfg); err != nil {
 			if err := r.fs.RemoveAll(targetDir); err != nil {
 				logger.Error(err, "failed to delete target directory", "path", targetDir)
 			}
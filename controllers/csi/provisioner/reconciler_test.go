@@ -10,7 +10,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
@@ -75,11 +75,9 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 	t.Run(`code modules disabled`, func(t *testing.T) {
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: v1alpha1.CodeModulesSpec{
-							Enabled: false,
-						},
+				&v1beta1.DynaKube{
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: v1beta1.OneAgentSpec{},
 					},
 				},
 			),
@@ -93,12 +91,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 	t.Run(`csi driver disabled`, func(t *testing.T) {
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: v1alpha1.CodeModulesSpec{
-							Enabled: true,
-							Volume: v1.VolumeSource{
-								CSI: nil,
+				&v1beta1.DynaKube{
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: v1beta1.OneAgentSpec{
+							ApplicationMonitoring: &v1beta1.ApplicationMonitoringSpec{
+								AppInjectionSpec: v1beta1.AppInjectionSpec{
+									Volume: v1.VolumeSource{
+										CSI: nil,
+									},
+								},
 							},
 						},
 					},
@@ -114,15 +115,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 	t.Run(`no tokens`, func(t *testing.T) {
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 					},
@@ -138,15 +139,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 	t.Run(`error when creating dynatrace client`, func(t *testing.T) {
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 					},
@@ -157,7 +158,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return nil, fmt.Errorf(errorMsg)
 			},
 		}
@@ -173,12 +174,12 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
 				},
 				&v1.Secret{
@@ -187,7 +188,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 		}
@@ -207,15 +208,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 		}, nil)
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 					},
@@ -226,7 +227,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 			fs: errorfs,
@@ -247,15 +248,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 		}, nil)
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 					},
@@ -266,7 +267,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 			fs: errorFs,
@@ -288,15 +289,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 			mock.AnythingOfType("string")).Return("", fmt.Errorf(errorMsg))
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 					},
@@ -307,7 +308,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 			fs: memFs,
@@ -357,15 +358,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 			mock.AnythingOfType("string")).Return(agentVersion, nil)
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 					},
@@ -376,7 +377,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 			fs: errorFs,
@@ -424,15 +425,15 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 			mock.AnythingOfType("string")).Return(agentVersion, nil)
 		r := &OneAgentProvisioner{
 			client: fake.NewClient(
-				&v1alpha1.DynaKube{
+				&v1beta1.DynaKube{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: dkName,
 					},
-					Spec: v1alpha1.DynaKubeSpec{
-						CodeModules: buildValidCodeModulesSpec(t),
+					Spec: v1beta1.DynaKubeSpec{
+						OneAgent: buildValidCodeModulesSpec(t),
 					},
-					Status: v1alpha1.DynaKubeStatus{
-						ConnectionInfo: v1alpha1.ConnectionInfoStatus{
+					Status: v1beta1.DynaKubeStatus{
+						ConnectionInfo: v1beta1.ConnectionInfoStatus{
 							TenantUUID: tenantUUID,
 						},
 						LatestAgentVersionUnixPaas: agentVersion,
@@ -444,7 +445,7 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 					},
 				},
 			),
-			dtcBuildFunc: func(rtc client.Client, instance *v1alpha1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
+			dtcBuildFunc: func(rtc client.Client, instance *v1beta1.DynaKube, secret *v1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 			fs: memFs,
@@ -478,25 +479,84 @@ func TestOneAgentProvisioner_Reconcile(t *testing.T) {
 	})
 }
 
+func TestOneAgentProvisioner_UpdateAgent_Downgrade(t *testing.T) {
+	const (
+		oldVersion = "1.213.0.20210301-123456"
+		newVersion = "1.215.0.20210415-123456"
+	)
+	envDir := filepath.Join(dtcsi.DataPath, tenantUUID)
+	versionFile := filepath.Join(envDir, dtcsi.VersionDir)
+
+	newDynaKube := func(annotations map[string]string) *v1beta1.DynaKube {
+		oneAgent := buildValidCodeModulesSpec(t)
+		oneAgent.ApplicationMonitoring.CodeModulesVersion = oldVersion
+		return &v1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: dkName, Annotations: annotations},
+			Spec:       v1beta1.DynaKubeSpec{OneAgent: oneAgent},
+			Status:     v1beta1.DynaKubeStatus{LatestAgentVersionUnixPaas: newVersion},
+		}
+	}
+	newFs := func(t *testing.T) afero.Fs {
+		memFs := afero.NewMemMapFs()
+		require.NoError(t, memFs.MkdirAll(filepath.Join(envDir, "bin", oldVersion), 0755))
+		require.NoError(t, afero.WriteFile(memFs, versionFile, []byte(newVersion), 0644))
+		return memFs
+	}
+
+	t.Run(`downgrades are refused by default`, func(t *testing.T) {
+		memFs := newFs(t)
+		r := &OneAgentProvisioner{fs: memFs}
+
+		err := r.updateAgent(context.TODO(), newDynaKube(nil), &dtclient.MockDynatraceClient{}, envDir, log)
+		require.NoError(t, err)
+
+		data, err := afero.ReadFile(memFs, versionFile)
+		require.NoError(t, err)
+		assert.Equal(t, newVersion, string(data))
+	})
+	t.Run(`kept binaries are used for allowed downgrades`, func(t *testing.T) {
+		memFs := newFs(t)
+		r := &OneAgentProvisioner{fs: memFs}
+		dk := newDynaKube(map[string]string{"alpha.operator.dynatrace.com/feature-allow-oneagent-downgrades": "true"})
+
+		err := r.updateAgent(context.TODO(), dk, &dtclient.MockDynatraceClient{}, envDir, log)
+		require.NoError(t, err)
+
+		data, err := afero.ReadFile(memFs, versionFile)
+		require.NoError(t, err)
+		assert.Equal(t, oldVersion, string(data))
+
+		exists, err := afero.DirExists(memFs, filepath.Join(envDir, dtcsi.GarbageCollectionPath, oldVersion))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
 func TestHasInvalidCSIVolumeSource(t *testing.T) {
-	dk := v1alpha1.DynaKube{}
+	dk := v1beta1.DynaKube{}
+	assert.True(t, hasInvalidCSIVolumeSource(dk))
+
+	dk.Spec.OneAgent.ApplicationMonitoring = &v1beta1.ApplicationMonitoringSpec{}
 	assert.True(t, hasInvalidCSIVolumeSource(dk))
 
-	dk.Spec.CodeModules.Volume.CSI = &v1.CSIVolumeSource{
+	dk.Spec.OneAgent.ApplicationMonitoring.Volume.CSI = &v1.CSIVolumeSource{
 		Driver: invalidDriverName,
 	}
 	assert.True(t, hasInvalidCSIVolumeSource(dk))
 
-	dk.Spec.CodeModules.Volume.CSI.Driver = dtcsi.DriverName
+	dk.Spec.OneAgent.ApplicationMonitoring.Volume.CSI.Driver = dtcsi.DriverName
 	assert.False(t, hasInvalidCSIVolumeSource(dk))
 }
 
-func buildValidCodeModulesSpec(_ *testing.T) v1alpha1.CodeModulesSpec {
-	return v1alpha1.CodeModulesSpec{
-		Enabled: true,
-		Volume: v1.VolumeSource{
-			CSI: &v1.CSIVolumeSource{
-				Driver: dtcsi.DriverName,
+func buildValidCodeModulesSpec(_ *testing.T) v1beta1.OneAgentSpec {
+	return v1beta1.OneAgentSpec{
+		ApplicationMonitoring: &v1beta1.ApplicationMonitoringSpec{
+			AppInjectionSpec: v1beta1.AppInjectionSpec{
+				Volume: v1.VolumeSource{
+					CSI: &v1.CSIVolumeSource{
+						Driver: dtcsi.DriverName,
+					},
+				},
 			},
 		},
 	}
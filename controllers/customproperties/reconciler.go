@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -21,22 +22,27 @@ const (
 	DataPath   = "custom.properties"
 	VolumeName = "custom-properties"
 	MountPath  = "/var/lib/dynatrace/gateway/config_template/custom.properties"
+
+	eventReasonCustomPropertiesCreated = "CustomPropertiesCreated"
+	eventReasonCustomPropertiesUpdated = "CustomPropertiesUpdated"
 )
 
 type Reconciler struct {
 	client.Client
 	scheme                    *runtime.Scheme
+	recorder                  record.EventRecorder
 	log                       logr.Logger
-	customPropertiesSource    dynatracev1alpha1.DynaKubeValueSource
+	customPropertiesSource    dynatracev1beta1.DynaKubeValueSource
 	customPropertiesOwnerName string
-	instance                  *dynatracev1alpha1.DynaKube
+	instance                  *dynatracev1beta1.DynaKube
 }
 
-func NewReconciler(clt client.Client, instance *dynatracev1alpha1.DynaKube, log logr.Logger, customPropertiesOwnerName string, customPropertiesSource dynatracev1alpha1.DynaKubeValueSource, scheme *runtime.Scheme) *Reconciler {
+func NewReconciler(clt client.Client, instance *dynatracev1beta1.DynaKube, log logr.Logger, customPropertiesOwnerName string, customPropertiesSource dynatracev1beta1.DynaKubeValueSource, scheme *runtime.Scheme, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
 		Client:                    clt,
 		instance:                  instance,
 		scheme:                    scheme,
+		recorder:                  recorder,
 		log:                       log,
 		customPropertiesSource:    customPropertiesSource,
 		customPropertiesOwnerName: customPropertiesOwnerName,
@@ -93,7 +99,11 @@ func (r *Reconciler) isOutdated(customProperties *corev1.Secret) bool {
 
 func (r *Reconciler) updateCustomProperties(customProperties *corev1.Secret) error {
 	customProperties.Data[DataKey] = []byte(r.customPropertiesSource.Value)
-	return r.Update(context.TODO(), customProperties)
+	if err := r.Update(context.TODO(), customProperties); err != nil {
+		return errors.WithStack(err)
+	}
+	r.recorder.Eventf(r.instance, corev1.EventTypeNormal, eventReasonCustomPropertiesUpdated, "Updated custom properties secret %s", customProperties.Name)
+	return nil
 }
 
 func (r *Reconciler) createCustomProperties() error {
@@ -107,7 +117,11 @@ func (r *Reconciler) createCustomProperties() error {
 		return errors.WithStack(err)
 	}
 
-	return r.Create(context.TODO(), customPropertiesSecret)
+	if err = r.Create(context.TODO(), customPropertiesSecret); err != nil {
+		return errors.WithStack(err)
+	}
+	r.recorder.Eventf(r.instance, corev1.EventTypeNormal, eventReasonCustomPropertiesCreated, "Created custom properties secret %s", customPropertiesSecret.Name)
+	return nil
 }
 
 func (r *Reconciler) buildCustomPropertiesSecret(secretName string, data string) *corev1.Secret {
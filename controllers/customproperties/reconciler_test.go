@@ -4,12 +4,13 @@ import (
 	"context"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -23,19 +24,20 @@ const (
 
 func TestReconciler_Reconcile(t *testing.T) {
 	t.Run(`Reconile works with minimal setup`, func(t *testing.T) {
-		r := NewReconciler(nil, nil, nil, "", dynatracev1alpha1.DynaKubeValueSource{}, nil)
+		r := NewReconciler(nil, nil, nil, "", dynatracev1beta1.DynaKubeValueSource{}, nil, nil)
 		err := r.Reconcile()
 		assert.NoError(t, err)
 	})
 	t.Run(`Reconile creates custom properties secret`, func(t *testing.T) {
-		valueSource := dynatracev1alpha1.DynaKubeValueSource{Value: testValue}
-		instance := &dynatracev1alpha1.DynaKube{
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue}
+		instance := &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      testName,
 				Namespace: testNamespace,
 			}}
 		fakeClient := fake.NewClient(instance)
-		r := NewReconciler(fakeClient, instance, nil, testOwner, valueSource, scheme.Scheme)
+		recorder := record.NewFakeRecorder(1)
+		r := NewReconciler(fakeClient, instance, nil, testOwner, valueSource, scheme.Scheme, recorder)
 		err := r.Reconcile()
 
 		assert.NoError(t, err)
@@ -48,16 +50,17 @@ func TestReconciler_Reconcile(t *testing.T) {
 		assert.NotEmpty(t, customPropertiesSecret.Data)
 		assert.Contains(t, customPropertiesSecret.Data, DataKey)
 		assert.Equal(t, customPropertiesSecret.Data[DataKey], []byte(testValue))
+		assert.Contains(t, <-recorder.Events, "Normal CustomPropertiesCreated")
 	})
 	t.Run(`Reconcile updates custom properties only if data changed`, func(t *testing.T) {
-		valueSource := dynatracev1alpha1.DynaKubeValueSource{Value: testValue}
-		instance := &dynatracev1alpha1.DynaKube{
+		valueSource := dynatracev1beta1.DynaKubeValueSource{Value: testValue}
+		instance := &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      testName,
 				Namespace: testNamespace,
 			}}
 		fakeClient := fake.NewClient(instance)
-		r := NewReconciler(fakeClient, instance, nil, testOwner, valueSource, scheme.Scheme)
+		r := NewReconciler(fakeClient, instance, nil, testOwner, valueSource, scheme.Scheme, &record.FakeRecorder{})
 		err := r.Reconcile()
 
 		assert.NoError(t, err)
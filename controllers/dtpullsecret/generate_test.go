@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,13 +37,13 @@ func TestGetImageRegistryFromAPIURL(t *testing.T) {
 }
 
 func TestReconciler_GenerateData(t *testing.T) {
-	instance := &v1alpha1.DynaKube{
-		Spec: v1alpha1.DynaKubeSpec{
+	instance := &v1beta1.DynaKube{
+		Spec: v1beta1.DynaKubeSpec{
 			APIURL: testApiUrl,
 		},
-		Status: v1alpha1.DynaKubeStatus{
-			ConnectionInfo: v1alpha1.ConnectionInfoStatus{
-				CommunicationHosts: []v1alpha1.CommunicationHostStatus{
+		Status: v1beta1.DynaKubeStatus{
+			ConnectionInfo: v1beta1.ConnectionInfoStatus{
+				CommunicationHosts: []v1beta1.CommunicationHostStatus{
 					{
 						Protocol: testProtocol,
 						Host:     testHost,
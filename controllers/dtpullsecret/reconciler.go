@@ -5,35 +5,41 @@ import (
 	"fmt"
 	"reflect"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
 	PullSecretSuffix = "-pull-secret"
+
+	eventReasonPullSecretCreated = "PullSecretCreated"
+	eventReasonPullSecretUpdated = "PullSecretUpdated"
 )
 
 type Reconciler struct {
 	client.Client
 	apiReader client.Reader
-	instance  *dynatracev1alpha1.DynaKube
+	instance  *dynatracev1beta1.DynaKube
 	log       logr.Logger
 	token     *corev1.Secret
 	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
 }
 
-func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, instance *dynatracev1alpha1.DynaKube, log logr.Logger, token *corev1.Secret) *Reconciler {
+func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, instance *dynatracev1beta1.DynaKube, log logr.Logger, token *corev1.Secret) *Reconciler {
 	return &Reconciler{
 		Client:    clt,
 		apiReader: apiReader,
 		scheme:    scheme,
+		recorder:  recorder,
 		instance:  instance,
 		log:       log,
 		token:     token,
@@ -94,6 +100,7 @@ func (r *Reconciler) createPullSecret(pullSecretData map[string][]byte) (*corev1
 	if err != nil {
 		return nil, fmt.Errorf("failed to create secret '%s': %w", extendWithPullSecretSuffix(r.instance.Name), err)
 	}
+	r.recorder.Eventf(r.instance, corev1.EventTypeNormal, eventReasonPullSecretCreated, "Created pull secret %s", pullSecret.Name)
 	return pullSecret, nil
 }
 
@@ -103,6 +110,7 @@ func (r *Reconciler) updatePullSecret(pullSecret *corev1.Secret, desiredPullSecr
 	if err := r.Update(context.TODO(), pullSecret); err != nil {
 		return fmt.Errorf("failed to update secret %s: %w", pullSecret.Name, err)
 	}
+	r.recorder.Eventf(r.instance, corev1.EventTypeNormal, eventReasonPullSecretUpdated, "Updated pull secret %s", pullSecret.Name)
 	return nil
 }
 
@@ -110,7 +118,7 @@ func isPullSecretEqual(currentSecret *corev1.Secret, desired map[string][]byte)
 	return reflect.DeepEqual(desired, currentSecret.Data)
 }
 
-func BuildPullSecret(instance *dynatracev1alpha1.DynaKube, pullSecretData map[string][]byte) *corev1.Secret {
+func BuildPullSecret(instance *dynatracev1beta1.DynaKube, pullSecretData map[string][]byte) *corev1.Secret {
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      extendWithPullSecretSuffix(instance.Name),
@@ -4,13 +4,14 @@ import (
 	"context"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -23,7 +24,7 @@ const (
 func TestReconciler_Reconcile(t *testing.T) {
 	t.Run(`Reconcile works with minimal setup`, func(t *testing.T) {
 		mockDTC := &dtclient.MockDynatraceClient{}
-		instance := &dynatracev1alpha1.DynaKube{
+		instance := &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: testNamespace,
 				Name:      testName,
@@ -36,7 +37,7 @@ func TestReconciler_Reconcile(t *testing.T) {
 			Data: map[string][]byte{dtclient.DynatracePaasToken: []byte(testPaasToken)},
 		}
 		fakeClient := fake.NewClient()
-		r := NewReconciler(fakeClient, fakeClient, scheme.Scheme, instance, logf.Log, secret)
+		r := NewReconciler(fakeClient, fakeClient, scheme.Scheme, &record.FakeRecorder{}, instance, logf.Log, secret)
 
 		mockDTC.
 			On("GetConnectionInfo").
@@ -58,37 +59,37 @@ func TestReconciler_Reconcile(t *testing.T) {
 		assert.NotEmpty(t, pullSecret.Data[".dockerconfigjson"])
 	})
 	t.Run(`Reconcile does not reconcile with custom pull secret`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{
+		instance := &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: testNamespace,
 				Name:      testName,
 			},
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				CustomPullSecret: testValue,
 			}}
-		r := NewReconciler(nil, nil, nil, instance, nil, nil)
+		r := NewReconciler(nil, nil, nil, nil, instance, nil, nil)
 		err := r.Reconcile()
 
 		assert.NoError(t, err)
 	})
 	t.Run(`Reconcile creates correct docker config`, func(t *testing.T) {
 		expectedJSON := `{"auths":{"test-endpoint.com":{"username":"test-name","password":"test-value","auth":"dGVzdC1uYW1lOnRlc3QtdmFsdWU="}}}`
-		instance := &dynatracev1alpha1.DynaKube{
+		instance := &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: testNamespace,
 				Name:      testName,
 			},
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL: testEndpoint,
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				ConnectionInfo: dynatracev1alpha1.ConnectionInfoStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				ConnectionInfo: dynatracev1beta1.ConnectionInfoStatus{
 					TenantUUID: testName,
 				},
 			},
 		}
 		fakeClient := fake.NewClient()
-		r := NewReconciler(fakeClient, fakeClient, scheme.Scheme, instance, logf.Log,
+		r := NewReconciler(fakeClient, fakeClient, scheme.Scheme, &record.FakeRecorder{}, instance, logf.Log,
 			&corev1.Secret{
 				Data: map[string][]byte{
 					dtclient.DynatracePaasToken: []byte(testValue),
@@ -113,22 +114,22 @@ func TestReconciler_Reconcile(t *testing.T) {
 	})
 	t.Run(`Reconcile update secret if data changed`, func(t *testing.T) {
 		expectedJSON := `{"auths":{"test-endpoint.com":{"username":"test-name","password":"test-value","auth":"dGVzdC1uYW1lOnRlc3QtdmFsdWU="}}}`
-		instance := &dynatracev1alpha1.DynaKube{
+		instance := &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: testNamespace,
 				Name:      testName,
 			},
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL: testEndpoint,
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				ConnectionInfo: dynatracev1alpha1.ConnectionInfoStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				ConnectionInfo: dynatracev1beta1.ConnectionInfoStatus{
 					TenantUUID: testName,
 				},
 			},
 		}
 		fakeClient := fake.NewClient()
-		r := NewReconciler(fakeClient, fakeClient, scheme.Scheme, instance, logf.Log,
+		r := NewReconciler(fakeClient, fakeClient, scheme.Scheme, &record.FakeRecorder{}, instance, logf.Log,
 			&corev1.Secret{
 				Data: map[string][]byte{
 					dtclient.DynatracePaasToken: []byte(testValue),
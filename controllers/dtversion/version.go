@@ -0,0 +1,259 @@
+package dtversion
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Version is a parsed version of a Dynatrace component. Besides full versions like 1.203.0.20200908-220956, short forms
+// like 1.203 or 1.203.0 and pre-release versions like 1.203.0-rc.1 are supported.
+//
+// Omitted components are treated as 0 when versions are compared, and a version without timestamp is older than the
+// same version with one. Pre-release versions are older than the corresponding release.
+type Version struct {
+	major      int
+	minor      int
+	release    int
+	timestamp  string
+	preRelease string
+
+	// precision is the number of components given, from 1 for the major version only to 4 if the timestamp is given.
+	precision int
+}
+
+var fullVersionRegex = regexp.MustCompile(`^v?(\d+)(?:\.(\d+)(?:\.(\d+)(?:\.(\d+-\d+))?)?)?(?:-([0-9A-Za-z.-]+))?$`)
+
+// ParseVersion parses a version, see Version for the supported formats.
+func ParseVersion(versionString string) (Version, error) {
+	match := fullVersionRegex.FindStringSubmatch(versionString)
+	if match == nil {
+		return Version{}, errors.Errorf("version malformed: %s", versionString)
+	}
+
+	v := Version{timestamp: match[4], preRelease: match[5], precision: 1}
+	for i, target := range []*int{&v.major, &v.minor, &v.release} {
+		if match[i+1] == "" {
+			break
+		}
+
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return Version{}, errors.Errorf("version malformed: %s", versionString)
+		}
+		*target = n
+		v.precision = i + 1
+	}
+
+	if v.timestamp != "" {
+		v.precision = 4
+	}
+	return v, nil
+}
+
+// CompareVersions returns 0 if a == b, n > 0 if a > b and n < 0 if a < b.
+func CompareVersions(a Version, b Version) int {
+	if a.major != b.major {
+		return a.major - b.major
+	}
+
+	if a.minor != b.minor {
+		return a.minor - b.minor
+	}
+
+	if a.release != b.release {
+		return a.release - b.release
+	}
+
+	if comp := comparePreReleases(a.preRelease, b.preRelease); comp != 0 {
+		return comp
+	}
+
+	return strings.Compare(a.timestamp, b.timestamp)
+}
+
+// NeedsUpgradeRaw parses prev and curr, and returns true when curr is a newer version than prev, or false if they are
+// the same. In case curr is older than prev an error is returned. See Version for the supported formats.
+func NeedsUpgradeRaw(prev string, curr string) (bool, error) {
+	parsedPrev, err := ParseVersion(prev)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed to parse version")
+	}
+
+	parsedCurr, err := ParseVersion(curr)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed to parse version")
+	}
+
+	comp := CompareVersions(parsedPrev, parsedCurr)
+	if comp > 0 {
+		return false, errors.Errorf("trying to downgrade from '%s' to '%s'", parsedPrev, parsedCurr)
+	}
+
+	return comp < 0, nil
+}
+
+// comparePreReleases compares the pre-release identifiers following the rules of semantic versioning, where an empty
+// pre-release denotes a release.
+func comparePreReleases(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return aNum - bNum
+			}
+		case aErr == nil:
+			// numeric identifiers have lower precedence than alphanumeric ones
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if comp := strings.Compare(aIDs[i], bIDs[i]); comp != 0 {
+				return comp
+			}
+		}
+	}
+	return len(aIDs) - len(bIDs)
+}
+
+// Major returns the major version.
+func (v Version) Major() int {
+	return v.major
+}
+
+// BuildTime returns the time the version has been built at, as given by its timestamp.
+func (v Version) BuildTime() (time.Time, error) {
+	if v.timestamp == "" {
+		return time.Time{}, errors.Errorf("version %s has no timestamp", v)
+	}
+	return time.Parse("20060102-150405", v.timestamp)
+}
+
+// hasPrefix returns true if v starts with all components given by prefix, e.g. 1.203.0.20200908-220956 starts with
+// 1.203.
+func (v Version) hasPrefix(prefix Version) bool {
+	if prefix.preRelease != "" {
+		return CompareVersions(v, prefix) == 0
+	}
+
+	vComponents := []string{strconv.Itoa(v.major), strconv.Itoa(v.minor), strconv.Itoa(v.release), v.timestamp}
+	prefixComponents := []string{strconv.Itoa(prefix.major), strconv.Itoa(prefix.minor), strconv.Itoa(prefix.release), prefix.timestamp}
+	for i := 0; i < prefix.precision; i++ {
+		if vComponents[i] != prefixComponents[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (v Version) String() string {
+	s := strconv.Itoa(v.major)
+	if v.precision >= 2 {
+		s += fmt.Sprintf(".%d", v.minor)
+	}
+	if v.precision >= 3 {
+		s += fmt.Sprintf(".%d", v.release)
+	}
+	if v.precision >= 4 {
+		s += "." + v.timestamp
+	}
+	if v.preRelease != "" {
+		s += "-" + v.preRelease
+	}
+	return s
+}
+
+// Range is a set of space separated constraints which all have to be satisfied by a version, e.g. ">=1.210 <1.220".
+//
+// The supported operators are =, !=, >, >=, < and <=. A constraint without operator is the same as =. Short versions
+// stand for all versions starting with them, so "1.210" matches 1.210.4.20210301-123456, and "<=1.220" includes all
+// 1.220 versions while "<1.220" excludes them.
+type Range struct {
+	expr        string
+	constraints []versionConstraint
+}
+
+type versionConstraint struct {
+	operator string
+	version  Version
+}
+
+var rangeOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ParseRange parses a range expression, see Range for the supported syntax.
+func ParseRange(expr string) (Range, error) {
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return Range{}, errors.New("version range is empty")
+	}
+
+	r := Range{expr: expr}
+	for _, field := range fields {
+		operator := "="
+		for _, op := range rangeOperators {
+			if strings.HasPrefix(field, op) {
+				operator = op
+				field = strings.TrimPrefix(field, op)
+				break
+			}
+		}
+
+		v, err := ParseVersion(field)
+		if err != nil {
+			return Range{}, errors.WithMessagef(err, "invalid version range '%s'", expr)
+		}
+		r.constraints = append(r.constraints, versionConstraint{operator: operator, version: v})
+	}
+	return r, nil
+}
+
+// Contains returns true if v satisfies all constraints of the range.
+func (r Range) Contains(v Version) bool {
+	for _, c := range r.constraints {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Range) String() string {
+	return r.expr
+}
+
+func (c versionConstraint) matches(v Version) bool {
+	prefix := v.hasPrefix(c.version)
+	comp := CompareVersions(v, c.version)
+
+	switch c.operator {
+	case "!=":
+		return !prefix
+	case ">":
+		return comp > 0 && !prefix
+	case ">=":
+		return comp >= 0 || prefix
+	case "<":
+		return comp < 0 && !prefix
+	case "<=":
+		return comp <= 0 || prefix
+	}
+	return prefix
+}
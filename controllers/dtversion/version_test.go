@@ -0,0 +1,141 @@
+package dtversion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Run("supported formats", func(t *testing.T) {
+		for _, s := range []string{
+			"1",
+			"1.203",
+			"1.203.0",
+			"1.203.0.20200908-220956",
+			"1.203.0-rc.1",
+			"1.203.0.20200908-220956-rc.1",
+		} {
+			version, err := ParseVersion(s)
+			if assert.NoError(t, err, s) {
+				assert.Equal(t, s, version.String())
+			}
+		}
+
+		version, err := ParseVersion("v1.203.0")
+		require.NoError(t, err)
+		assert.Equal(t, "1.203.0", version.String())
+	})
+	t.Run("malformed versions", func(t *testing.T) {
+		for _, s := range []string{"", "abc", "a.bcd.e", "1.203.x", "2.003.x.20200908-220956", "1.203.0.20200908", "1..0", "1.203-"} {
+			_, err := ParseVersion(s)
+			assert.Error(t, err, s)
+		}
+	})
+	t.Run("build time", func(t *testing.T) {
+		version, err := ParseVersion("1.203.0.20200908-220956")
+		require.NoError(t, err)
+		assert.Equal(t, 1, version.Major())
+
+		buildTime, err := version.BuildTime()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 9, 8, 22, 9, 56, 0, time.UTC), buildTime)
+
+		version, err = ParseVersion("1.203.0")
+		require.NoError(t, err)
+		_, err = version.BuildTime()
+		assert.Error(t, err)
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	compare := func(a, b string) int {
+		parsedA, err := ParseVersion(a)
+		require.NoError(t, err)
+		parsedB, err := ParseVersion(b)
+		require.NoError(t, err)
+		return CompareVersions(parsedA, parsedB)
+	}
+
+	assert.Equal(t, 0, compare("1.203.0.20200908-220956", "1.203.0.20200908-220956"))
+	assert.Equal(t, 0, compare("1.203", "1.203.0"))
+
+	assert.Less(t, compare("1.203", "1.204.0.20200908-220956"), 0)
+	assert.Less(t, compare("1.203.0", "1.203.0.20200908-220956"), 0)
+	assert.Less(t, compare("1.203.0.20200908-220956", "1.203.0.20210908-220956"), 0)
+	assert.Less(t, compare("1.203.0-rc.1", "1.203.0"), 0)
+	assert.Less(t, compare("1.203.0-rc.1", "1.203.0-rc.2"), 0)
+	assert.Less(t, compare("1.203.0-rc.2", "1.203.0-rc.10"), 0)
+	assert.Less(t, compare("1.203.0-1", "1.203.0-alpha"), 0)
+	assert.Less(t, compare("1.203.0-alpha", "1.203.0-alpha.1"), 0)
+
+	assert.Greater(t, compare("2", "1.300.5"), 0)
+	assert.Greater(t, compare("1.203.1", "1.203.0.20200908-220956"), 0)
+}
+
+func TestNeedsUpgradeRaw(t *testing.T) {
+	res, err := NeedsUpgradeRaw("1.203.0.20200908-220956", "1.203.0.20210908-220956") // Upgrade
+	assert.True(t, res)
+	assert.NoError(t, err)
+
+	_, err = NeedsUpgradeRaw("1.203.1.20210908-220956", "1.203.0.20200908-220956") // Downgrade
+	assert.Error(t, err)
+
+	res, err = NeedsUpgradeRaw("1.203.0.20200908-220956", "1.203.0.20200908-220956") // Same versions
+	assert.False(t, res)
+	assert.NoError(t, err)
+}
+
+func TestParseRange(t *testing.T) {
+	contains := func(t *testing.T, r Range, s string) bool {
+		version, err := ParseVersion(s)
+		require.NoError(t, err)
+		return r.Contains(version)
+	}
+
+	t.Run("bounded range", func(t *testing.T) {
+		r, err := ParseRange(">=1.210 <1.220")
+		require.NoError(t, err)
+
+		assert.True(t, contains(t, r, "1.210.0.20210301-123456"))
+		assert.True(t, contains(t, r, "1.219.9.20210301-123456"))
+		assert.False(t, contains(t, r, "1.209.5.20210301-123456"))
+		assert.False(t, contains(t, r, "1.220.0.20210301-123456"))
+		assert.False(t, contains(t, r, "1.220"))
+	})
+	t.Run("short versions match all versions starting with them", func(t *testing.T) {
+		r, err := ParseRange("1.210")
+		require.NoError(t, err)
+		assert.True(t, contains(t, r, "1.210.4.20210301-123456"))
+		assert.False(t, contains(t, r, "1.211.0.20210301-123456"))
+
+		r, err = ParseRange("<=1.220")
+		require.NoError(t, err)
+		assert.True(t, contains(t, r, "1.220.4.20210301-123456"))
+		assert.False(t, contains(t, r, "1.221.0"))
+
+		r, err = ParseRange(">1.220")
+		require.NoError(t, err)
+		assert.False(t, contains(t, r, "1.220.4.20210301-123456"))
+		assert.True(t, contains(t, r, "1.221.0"))
+
+		r, err = ParseRange("!=1.215")
+		require.NoError(t, err)
+		assert.False(t, contains(t, r, "1.215.1"))
+		assert.True(t, contains(t, r, "1.216.0"))
+	})
+	t.Run("exact versions", func(t *testing.T) {
+		r, err := ParseRange("=1.215.0.20210415-123456")
+		require.NoError(t, err)
+		assert.True(t, contains(t, r, "1.215.0.20210415-123456"))
+		assert.False(t, contains(t, r, "1.215.0.20210416-123456"))
+	})
+	t.Run("invalid ranges", func(t *testing.T) {
+		for _, expr := range []string{"", "  ", ">=abc", ">=1.210 <", "~1.210"} {
+			_, err := ParseRange(expr)
+			assert.Error(t, err, expr)
+		}
+	})
+}
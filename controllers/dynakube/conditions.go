@@ -0,0 +1,101 @@
+package dynakube
+
+import (
+	"context"
+	"fmt"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/webhook"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileWebhookCondition sets the WebhookReady condition if code modules are injected, or removes it otherwise.
+func (r *ReconcileDynaKube) reconcileWebhookCondition(ctx context.Context, instance *dynatracev1beta1.DynaKube) (bool, error) {
+	if !instance.NeedsAppInjection() {
+		return instance.Status.RemoveCondition(dynatracev1beta1.WebhookReadyConditionType), nil
+	}
+
+	var deployment appsv1.Deployment
+	err := r.client.Get(ctx, client.ObjectKey{Name: webhook.DeploymentName, Namespace: instance.Namespace}, &deployment)
+	if k8serrors.IsNotFound(err) {
+		return instance.Status.SetCondition(notDeployedCondition(dynatracev1beta1.WebhookReadyConditionType, "Deployment", webhook.DeploymentName)), nil
+	} else if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	var desired int32 = 1
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	return instance.Status.SetCondition(
+		readinessCondition(dynatracev1beta1.WebhookReadyConditionType, "webhook", deployment.Status.ReadyReplicas, desired)), nil
+}
+
+// reconcileCSIProvisionerCondition sets the CSIProvisionerReady condition if code modules are provided through the CSI
+// driver, or removes it otherwise.
+func (r *ReconcileDynaKube) reconcileCSIProvisionerCondition(ctx context.Context, instance *dynatracev1beta1.DynaKube) (bool, error) {
+	if !instance.NeedsCSIDriver() {
+		return instance.Status.RemoveCondition(dynatracev1beta1.CSIProvisionerReadyConditionType), nil
+	}
+
+	var ds appsv1.DaemonSet
+	err := r.client.Get(ctx, client.ObjectKey{Name: dtcsi.DaemonSetName, Namespace: instance.Namespace}, &ds)
+	if k8serrors.IsNotFound(err) {
+		return instance.Status.SetCondition(notDeployedCondition(dynatracev1beta1.CSIProvisionerReadyConditionType, "DaemonSet", dtcsi.DaemonSetName)), nil
+	} else if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return instance.Status.SetCondition(
+		readinessCondition(dynatracev1beta1.CSIProvisionerReadyConditionType, "CSI driver", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)), nil
+}
+
+func notDeployedCondition(conditionType string, kind string, name string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonNotDeployed,
+		Message: fmt.Sprintf("%s %s not found", kind, name),
+	}
+}
+
+func readinessCondition(conditionType string, component string, ready int32, desired int32) metav1.Condition {
+	if ready < desired {
+		return metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  dynatracev1beta1.ReasonRolloutInProgress,
+			Message: fmt.Sprintf("%d of %d %s pods are ready", ready, desired, component),
+		}
+	}
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReady,
+		Message: fmt.Sprintf("All %d %s pods are ready", ready, component),
+	}
+}
+
+// reconcileResultCondition sets the condition of a component which has been reconciled by the Operator, depending on
+// whether reconciling it failed.
+func reconcileResultCondition(instance *dynatracev1beta1.DynaKube, conditionType string, err error, readyMessage string) bool {
+	if err != nil {
+		return instance.Status.SetCondition(metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  dynatracev1beta1.ReasonReconcileFailed,
+			Message: err.Error(),
+		})
+	}
+	return instance.Status.SetCondition(metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReady,
+		Message: readyMessage,
+	})
+}
@@ -0,0 +1,117 @@
+package dynakube
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/Dynatrace/dynatrace-operator/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileWebhookCondition(t *testing.T) {
+	newInstance := func() *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{}},
+			},
+		}
+	}
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: webhook.DeploymentName, Namespace: testNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+
+	t.Run(`webhook not deployed`, func(t *testing.T) {
+		instance := newInstance()
+		r := &ReconcileDynaKube{client: fake.NewClient()}
+
+		upd, err := r.reconcileWebhookCondition(context.TODO(), instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.WebhookReadyConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonNotDeployed, condition.Reason)
+		assert.Equal(t, "Deployment dynatrace-webhook not found", condition.Message)
+	})
+	t.Run(`webhook pods not ready`, func(t *testing.T) {
+		instance := newInstance()
+		r := &ReconcileDynaKube{client: fake.NewClient(deployment.DeepCopy())}
+
+		upd, err := r.reconcileWebhookCondition(context.TODO(), instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.WebhookReadyConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, condition.Reason)
+		assert.Equal(t, "1 of 2 webhook pods are ready", condition.Message)
+	})
+	t.Run(`webhook ready`, func(t *testing.T) {
+		instance := newInstance()
+		ready := deployment.DeepCopy()
+		ready.Status.ReadyReplicas = 2
+		r := &ReconcileDynaKube{client: fake.NewClient(ready)}
+
+		_, err := r.reconcileWebhookCondition(context.TODO(), instance)
+		require.NoError(t, err)
+		assert.True(t, meta.IsStatusConditionTrue(instance.Status.Conditions, dynatracev1beta1.WebhookReadyConditionType))
+	})
+	t.Run(`condition removed if code modules aren't injected`, func(t *testing.T) {
+		instance := newInstance()
+		r := &ReconcileDynaKube{client: fake.NewClient()}
+
+		_, err := r.reconcileWebhookCondition(context.TODO(), instance)
+		require.NoError(t, err)
+
+		instance.Spec.OneAgent = dynatracev1beta1.OneAgentSpec{ClassicFullStack: &dynatracev1beta1.HostInjectSpec{}}
+		upd, err := r.reconcileWebhookCondition(context.TODO(), instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Nil(t, meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.WebhookReadyConditionType))
+	})
+}
+
+func TestReconcileCSIProvisionerCondition(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{}},
+		},
+	}
+	r := &ReconcileDynaKube{client: fake.NewClient(&appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: dtcsi.DaemonSetName, Namespace: testNamespace},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3},
+	})}
+
+	upd, err := r.reconcileCSIProvisionerCondition(context.TODO(), instance)
+	require.NoError(t, err)
+	assert.True(t, upd)
+
+	condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.CSIProvisionerReadyConditionType)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "All 3 CSI driver pods are ready", condition.Message)
+}
+
+func TestReconcileResultCondition(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{}
+
+	assert.True(t, reconcileResultCondition(instance, dynatracev1beta1.PullSecretReadyConditionType, errors.New("forbidden"), "ready"))
+	assert.Equal(t, dynatracev1beta1.Error, instance.Status.PhaseFromConditions())
+
+	assert.True(t, reconcileResultCondition(instance, dynatracev1beta1.PullSecretReadyConditionType, nil, "ready"))
+	assert.False(t, reconcileResultCondition(instance, dynatracev1beta1.PullSecretReadyConditionType, nil, "ready"))
+	assert.Equal(t, dynatracev1beta1.Running, instance.Status.PhaseFromConditions())
+}
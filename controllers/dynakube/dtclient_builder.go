@@ -13,8 +13,9 @@ and are candidates to be made into a library:
 import (
 	"context"
 	"fmt"
+	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/pkg/errors"
@@ -27,7 +28,7 @@ type options struct {
 }
 
 // BuildDynatraceClient creates a new Dynatrace client using the settings configured on the given instance.
-func BuildDynatraceClient(rtc client.Client, instance *dynatracev1alpha1.DynaKube, secret *corev1.Secret) (dtclient.Client, error) {
+func BuildDynatraceClient(rtc client.Client, instance *dynatracev1beta1.DynaKube, secret *corev1.Secret) (dtclient.Client, error) {
 	if instance == nil {
 		return nil, fmt.Errorf("could not build dynatrace client: instance is nil")
 	}
@@ -39,11 +40,14 @@ func BuildDynatraceClient(rtc client.Client, instance *dynatracev1alpha1.DynaKub
 	}
 
 	opts := newOptions()
+	opts.appendDynaKube(instance.Name)
+	opts.appendTimeout(instance.APIRequestTimeout())
 	opts.appendCertCheck(&spec)
 	opts.appendNetworkZone(&spec)
 	opts.appendDisableHostsRequests(instance.FeatureDisableHostsRequests())
+	opts.appendUseAPIV2(instance.FeatureUseDynatraceAPIV2())
 
-	err = opts.appendProxySettings(rtc, &spec, namespace)
+	err = opts.appendProxySettings(rtc, instance.APIProxy(), namespace)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -64,18 +68,26 @@ func newOptions() *options {
 
 // StaticDynatraceClient creates a DynatraceClientFunc always returning c.
 func StaticDynatraceClient(c dtclient.Client) DynatraceClientFunc {
-	return func(rtc client.Client, instance *dynatracev1alpha1.DynaKube, secret *corev1.Secret) (dtclient.Client, error) {
+	return func(rtc client.Client, instance *dynatracev1beta1.DynaKube, secret *corev1.Secret) (dtclient.Client, error) {
 		return c, nil
 	}
 }
 
-func (opts *options) appendNetworkZone(spec *dynatracev1alpha1.DynaKubeSpec) {
+func (opts *options) appendDynaKube(name string) {
+	opts.Opts = append(opts.Opts, dtclient.DynaKube(name))
+}
+
+func (opts *options) appendTimeout(timeout time.Duration) {
+	opts.Opts = append(opts.Opts, dtclient.Timeout(timeout))
+}
+
+func (opts *options) appendNetworkZone(spec *dynatracev1beta1.DynaKubeSpec) {
 	if spec.NetworkZone != "" {
 		opts.Opts = append(opts.Opts, dtclient.NetworkZone(spec.NetworkZone))
 	}
 }
 
-func (opts *options) appendCertCheck(spec *dynatracev1alpha1.DynaKubeSpec) {
+func (opts *options) appendCertCheck(spec *dynatracev1beta1.DynaKubeSpec) {
 	opts.Opts = append(opts.Opts, dtclient.SkipCertificateValidation(spec.SkipCertCheck))
 }
 
@@ -83,8 +95,12 @@ func (opts *options) appendDisableHostsRequests(disableHostsRequests bool) {
 	opts.Opts = append(opts.Opts, dtclient.DisableHostsRequests(disableHostsRequests))
 }
 
-func (opts *options) appendProxySettings(rtc client.Client, spec *dynatracev1alpha1.DynaKubeSpec, namespace string) error {
-	if p := spec.Proxy; p != nil {
+func (opts *options) appendUseAPIV2(useAPIV2 bool) {
+	opts.Opts = append(opts.Opts, dtclient.UseAPIV2(useAPIV2))
+}
+
+func (opts *options) appendProxySettings(rtc client.Client, p *dynatracev1beta1.DynaKubeProxySource, namespace string) error {
+	if p != nil {
 		if p.ValueFrom != "" {
 			proxySecret := &corev1.Secret{}
 			err := rtc.Get(context.TODO(), client.ObjectKey{Name: p.ValueFrom, Namespace: namespace}, proxySecret)
@@ -104,7 +120,7 @@ func (opts *options) appendProxySettings(rtc client.Client, spec *dynatracev1alp
 	return nil
 }
 
-func (opts *options) appendTrustedCerts(rtc client.Client, spec *dynatracev1alpha1.DynaKubeSpec, namespace string) error {
+func (opts *options) appendTrustedCerts(rtc client.Client, spec *dynatracev1beta1.DynaKubeSpec, namespace string) error {
 	if spec.TrustedCAs != "" {
 		certs := &corev1.ConfigMap{}
 		if err := rtc.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: spec.TrustedCAs}, certs); err != nil {
@@ -3,7 +3,7 @@ package dynakube
 import (
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
@@ -31,8 +31,8 @@ func TestBuildDynatraceClient(t *testing.T) {
 				dtclient.DynatraceApiToken:  []byte(testValue),
 				dtclient.DynatracePaasToken: []byte(testValueAlternative),
 			}}
-		instance := &dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+		instance := &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL: testEndpoint,
 			}}
 		fakeClient := fake.NewClient(instance, &secret)
@@ -55,8 +55,8 @@ func TestBuildDynatraceClient(t *testing.T) {
 			Data: map[string][]byte{
 				//Simulate missing values
 			}}
-		instance := &dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+		instance := &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL: testEndpoint,
 			}}
 		fakeClient := fake.NewClient(instance, &secret)
@@ -78,10 +78,10 @@ func TestBuildDynatraceClient(t *testing.T) {
 				dtclient.DynatraceApiToken:  []byte(testValue),
 				dtclient.DynatracePaasToken: []byte(testValueAlternative),
 			}}
-		instance := &dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+		instance := &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL: testEndpoint,
-				Proxy: &dynatracev1alpha1.DynaKubeProxy{
+				Proxy: &dynatracev1beta1.DynaKubeProxy{
 					ValueFrom: testKey,
 				}}}
 		fakeClient := fake.NewClient(instance, &secret)
@@ -100,8 +100,8 @@ func TestBuildDynatraceClient(t *testing.T) {
 				dtclient.DynatraceApiToken:  []byte(testValue),
 				dtclient.DynatracePaasToken: []byte(testValueAlternative),
 			}}
-		instance := &dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+		instance := &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL:     testEndpoint,
 				TrustedCAs: testKey,
 			}}
@@ -121,11 +121,11 @@ func TestOptions(t *testing.T) {
 		assert.NotNil(t, options)
 		assert.Empty(t, options.Opts)
 
-		options.appendNetworkZone(&dynatracev1alpha1.DynaKubeSpec{})
+		options.appendNetworkZone(&dynatracev1beta1.DynaKubeSpec{})
 
 		assert.Empty(t, options.Opts)
 
-		options.appendNetworkZone(&dynatracev1alpha1.DynaKubeSpec{
+		options.appendNetworkZone(&dynatracev1beta1.DynaKubeSpec{
 			NetworkZone: testValue,
 		})
 
@@ -137,7 +137,7 @@ func TestOptions(t *testing.T) {
 		assert.NotNil(t, options)
 		assert.Empty(t, options.Opts)
 
-		options.appendCertCheck(&dynatracev1alpha1.DynaKubeSpec{})
+		options.appendCertCheck(&dynatracev1beta1.DynaKubeSpec{})
 
 		assert.NotNil(t, options)
 		// appendCertCheck uses default value of property to append to,
@@ -145,27 +145,30 @@ func TestOptions(t *testing.T) {
 		assert.NotEmpty(t, options.Opts)
 
 		options = newOptions()
-		options.appendCertCheck(&dynatracev1alpha1.DynaKubeSpec{
+		options.appendCertCheck(&dynatracev1beta1.DynaKubeSpec{
 			SkipCertCheck: true,
 		})
 
 		assert.NotNil(t, options)
 		assert.NotEmpty(t, options.Opts)
 	})
+	t.Run(`Test append use api v2`, func(t *testing.T) {
+		options := newOptions()
+		options.appendUseAPIV2(true)
+
+		assert.NotEmpty(t, options.Opts)
+	})
 	t.Run(`Test append proxy settings`, func(t *testing.T) {
 		options := newOptions()
 
 		assert.NotNil(t, options)
 		assert.Empty(t, options.Opts)
 
-		err := options.appendProxySettings(nil, &dynatracev1alpha1.DynaKubeSpec{}, "")
+		err := options.appendProxySettings(nil, nil, "")
 		assert.NoError(t, err)
 		assert.Empty(t, options.Opts)
 
-		err = options.appendProxySettings(nil, &dynatracev1alpha1.DynaKubeSpec{
-			Proxy: &dynatracev1alpha1.DynaKubeProxy{
-				Value: testValue,
-			}}, "")
+		err = options.appendProxySettings(nil, &dynatracev1beta1.DynaKubeProxySource{Value: testValue}, "")
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, options.Opts)
@@ -181,10 +184,7 @@ func TestOptions(t *testing.T) {
 				},
 			})
 		options = newOptions()
-		err = options.appendProxySettings(fakeClient, &dynatracev1alpha1.DynaKubeSpec{
-			Proxy: &dynatracev1alpha1.DynaKubeProxy{
-				ValueFrom: testName,
-			}}, testNamespace)
+		err = options.appendProxySettings(fakeClient, &dynatracev1beta1.DynaKubeProxySource{ValueFrom: testName}, testNamespace)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, options.Opts)
@@ -192,10 +192,7 @@ func TestOptions(t *testing.T) {
 	t.Run(`AppendProxySettings handles missing or malformed secret`, func(t *testing.T) {
 		fakeClient := fake.NewClient()
 		options := newOptions()
-		err := options.appendProxySettings(fakeClient, &dynatracev1alpha1.DynaKubeSpec{
-			Proxy: &dynatracev1alpha1.DynaKubeProxy{
-				ValueFrom: testName,
-			}}, testNamespace)
+		err := options.appendProxySettings(fakeClient, &dynatracev1beta1.DynaKubeProxySource{ValueFrom: testName}, testNamespace)
 
 		assert.Error(t, err)
 		assert.Empty(t, options.Opts)
@@ -209,10 +206,7 @@ func TestOptions(t *testing.T) {
 				Data: map[string][]byte{},
 			})
 		options = newOptions()
-		err = options.appendProxySettings(fakeClient, &dynatracev1alpha1.DynaKubeSpec{
-			Proxy: &dynatracev1alpha1.DynaKubeProxy{
-				ValueFrom: testName,
-			}}, testNamespace)
+		err = options.appendProxySettings(fakeClient, &dynatracev1beta1.DynaKubeProxySource{ValueFrom: testName}, testNamespace)
 
 		assert.Error(t, err)
 		assert.Empty(t, options.Opts)
@@ -223,7 +217,7 @@ func TestOptions(t *testing.T) {
 		assert.NotNil(t, options)
 		assert.Empty(t, options.Opts)
 
-		err := options.appendTrustedCerts(nil, &dynatracev1alpha1.DynaKubeSpec{}, "")
+		err := options.appendTrustedCerts(nil, &dynatracev1beta1.DynaKubeSpec{}, "")
 
 		assert.NoError(t, err)
 		assert.Empty(t, options.Opts)
@@ -237,7 +231,7 @@ func TestOptions(t *testing.T) {
 				Data: map[string]string{
 					Certificates: testValue,
 				}})
-		err = options.appendTrustedCerts(fakeClient, &dynatracev1alpha1.DynaKubeSpec{
+		err = options.appendTrustedCerts(fakeClient, &dynatracev1beta1.DynaKubeSpec{
 			TrustedCAs: testName,
 		}, testNamespace)
 
@@ -251,7 +245,7 @@ func TestOptions(t *testing.T) {
 		assert.Empty(t, options.Opts)
 
 		fakeClient := fake.NewClient()
-		err := options.appendTrustedCerts(fakeClient, &dynatracev1alpha1.DynaKubeSpec{
+		err := options.appendTrustedCerts(fakeClient, &dynatracev1beta1.DynaKubeSpec{
 			TrustedCAs: testName,
 		}, testNamespace)
 
@@ -265,7 +259,7 @@ func TestOptions(t *testing.T) {
 					Namespace: testNamespace,
 				},
 				Data: map[string]string{}})
-		err = options.appendTrustedCerts(fakeClient, &dynatracev1alpha1.DynaKubeSpec{
+		err = options.appendTrustedCerts(fakeClient, &dynatracev1beta1.DynaKubeSpec{
 			TrustedCAs: testName,
 		}, testNamespace)
 
@@ -8,21 +8,23 @@ import (
 	"strings"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type DynatraceClientReconciler struct {
 	Client              client.Client
 	DynatraceClientFunc DynatraceClientFunc
-	Now                 metav1.Time
-	UpdatePaaSToken     bool
-	UpdateAPIToken      bool
+	// Recorder is optional, if set, a Warning event is recorded on the DynaKube whenever a token turns invalid.
+	Recorder        record.EventRecorder
+	Now             metav1.Time
+	UpdatePaaSToken bool
+	UpdateAPIToken  bool
 }
 
 type tokenConfig struct {
@@ -31,7 +33,7 @@ type tokenConfig struct {
 	Timestamp         **metav1.Time
 }
 
-func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dynatracev1alpha1.DynaKube) (dtclient.Client, bool, error) {
+func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dynatracev1beta1.DynaKube) (dtclient.Client, bool, error) {
 	now := r.Now
 	if now.IsZero() {
 		now = metav1.Now()
@@ -50,7 +52,7 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 
 	if r.UpdatePaaSToken {
 		tokens = append(tokens, &tokenConfig{
-			Type:      dynatracev1alpha1.PaaSTokenConditionType,
+			Type:      dynatracev1beta1.PaaSTokenConditionType,
 			Key:       dtclient.DynatracePaasToken,
 			Scope:     dtclient.TokenScopeInstallerDownload,
 			Timestamp: &sts.LastPaaSTokenProbeTimestamp,
@@ -59,7 +61,7 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 
 	if r.UpdateAPIToken {
 		tokens = append(tokens, &tokenConfig{
-			Type:      dynatracev1alpha1.APITokenConditionType,
+			Type:      dynatracev1beta1.APITokenConditionType,
 			Key:       dtclient.DynatraceApiToken,
 			Scope:     dtclient.TokenScopeDataExport,
 			Timestamp: &sts.LastAPITokenProbeTimestamp,
@@ -82,10 +84,10 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 		message := fmt.Sprintf("Secret '%s' not found", secretKey)
 
 		for _, t := range tokens {
-			updateCR = setCondition(&sts.Conditions, metav1.Condition{
+			updateCR = r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenSecretNotFound,
+				Reason:  dynatracev1beta1.ReasonTokenSecretNotFound,
 				Message: message,
 			}) || updateCR
 		}
@@ -100,10 +102,10 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 	for _, t := range tokens {
 		v := secret.Data[t.Key]
 		if len(v) == 0 {
-			updateCR = setCondition(&sts.Conditions, metav1.Condition{
+			updateCR = r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenMissing,
+				Reason:  dynatracev1beta1.ReasonTokenMissing,
 				Message: fmt.Sprintf("Token %s on secret %s missing", t.Key, secretKey),
 			}) || updateCR
 			valid = false
@@ -120,10 +122,10 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 		message := fmt.Sprintf("Failed to create Dynatrace API Client: %s", err)
 
 		for _, t := range tokens {
-			updateCR = setCondition(&sts.Conditions, metav1.Condition{
+			updateCR = r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenError,
+				Reason:  dynatracev1beta1.ReasonTokenError,
 				Message: message,
 			}) || updateCR
 		}
@@ -133,10 +135,10 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 
 	for _, t := range tokens {
 		if strings.TrimSpace(t.Value) != t.Value {
-			updateCR = setCondition(&sts.Conditions, metav1.Condition{
+			updateCR = r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenUnauthorized,
+				Reason:  dynatracev1beta1.ReasonTokenUnauthorized,
 				Message: fmt.Sprintf("Token on secret %s has leading and/or trailing spaces", secretKey),
 			}) || updateCR
 			continue
@@ -151,43 +153,43 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 		nowCopy := now
 		*t.Timestamp = &nowCopy
 		updateCR = true
-		ss, err := dtc.GetTokenScopes(t.Value)
+		ss, err := dtc.GetTokenScopes(ctx, t.Value)
 
 		var serr dtclient.ServerError
 		if ok := errors.As(err, &serr); ok && serr.Code == http.StatusUnauthorized {
-			setCondition(&sts.Conditions, metav1.Condition{
+			r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenUnauthorized,
+				Reason:  dynatracev1beta1.ReasonTokenUnauthorized,
 				Message: fmt.Sprintf("Token on secret %s unauthorized", secretKey),
 			})
 			continue
 		}
 
 		if err != nil {
-			setCondition(&sts.Conditions, metav1.Condition{
+			r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenError,
+				Reason:  dynatracev1beta1.ReasonTokenError,
 				Message: fmt.Sprintf("error when querying token on secret %s: %v", secretKey, err),
 			})
 			continue
 		}
 
 		if !ss.Contains(t.Scope) {
-			setCondition(&sts.Conditions, metav1.Condition{
+			r.setCondition(instance, metav1.Condition{
 				Type:    t.Type,
 				Status:  metav1.ConditionFalse,
-				Reason:  dynatracev1alpha1.ReasonTokenScopeMissing,
+				Reason:  dynatracev1beta1.ReasonTokenScopeMissing,
 				Message: fmt.Sprintf("Token on secret %s missing scope %s", secretKey, t.Scope),
 			})
 			continue
 		}
 
-		setCondition(&sts.Conditions, metav1.Condition{
+		r.setCondition(instance, metav1.Condition{
 			Type:    t.Type,
 			Status:  metav1.ConditionTrue,
-			Reason:  dynatracev1alpha1.ReasonTokenReady,
+			Reason:  dynatracev1beta1.ReasonTokenReady,
 			Message: "Ready",
 		})
 	}
@@ -195,12 +197,13 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 	return dtc, updateCR, nil
 }
 
-func setCondition(conditions *[]metav1.Condition, condition metav1.Condition) bool {
-	c := meta.FindStatusCondition(*conditions, condition.Type)
-	if c != nil && c.Reason == condition.Reason && c.Message == condition.Message && c.Status == condition.Status {
+func (r *DynatraceClientReconciler) setCondition(instance *dynatracev1beta1.DynaKube, condition metav1.Condition) bool {
+	if !instance.Status.SetCondition(condition) {
 		return false
 	}
 
-	meta.SetStatusCondition(conditions, condition)
+	if r.Recorder != nil && condition.Status == metav1.ConditionFalse {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
 	return true
 }
@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
@@ -15,14 +15,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 	namespace := "dynatrace"
 	dynaKube := "dynakube"
-	base := dynatracev1alpha1.DynaKube{
+	base := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: dynaKube, Namespace: namespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 			Tokens: dynaKube,
 		},
@@ -32,6 +33,7 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		deepCopy := base.DeepCopy()
 		c := fake.NewClient()
 		dtcMock := &dtclient.MockDynatraceClient{}
+		recorder := record.NewFakeRecorder(10)
 
 		rec := &DynatraceClientReconciler{
 			Client:              c,
@@ -39,6 +41,7 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 			UpdatePaaSToken:     true,
 			UpdateAPIToken:      true,
 			Now:                 metav1.Now(),
+			Recorder:            recorder,
 		}
 
 		dtc, ucr, err := rec.Reconcile(context.TODO(), deepCopy)
@@ -46,10 +49,12 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.True(t, ucr)
 		assert.Error(t, err)
 
-		AssertCondition(t, deepCopy, dynatracev1alpha1.PaaSTokenConditionType, false, dynatracev1alpha1.ReasonTokenSecretNotFound,
+		AssertCondition(t, deepCopy, dynatracev1beta1.PaaSTokenConditionType, false, dynatracev1beta1.ReasonTokenSecretNotFound,
 			"Secret 'dynatrace:dynakube' not found")
-		AssertCondition(t, deepCopy, dynatracev1alpha1.APITokenConditionType, false, dynatracev1alpha1.ReasonTokenSecretNotFound,
+		AssertCondition(t, deepCopy, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenSecretNotFound,
 			"Secret 'dynatrace:dynakube' not found")
+		assert.Len(t, recorder.Events, 2)
+		assert.Equal(t, "Warning TokenSecretNotFound Secret 'dynatrace:dynakube' not found", <-recorder.Events)
 
 		mock.AssertExpectationsForObjects(t, dtcMock)
 	})
@@ -72,9 +77,9 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.True(t, ucr)
 		assert.Error(t, err)
 
-		AssertCondition(t, dk, dynatracev1alpha1.PaaSTokenConditionType, false, dynatracev1alpha1.ReasonTokenMissing,
+		AssertCondition(t, dk, dynatracev1beta1.PaaSTokenConditionType, false, dynatracev1beta1.ReasonTokenMissing,
 			"Token paasToken on secret dynatrace:dynakube missing")
-		AssertCondition(t, dk, dynatracev1alpha1.APITokenConditionType, false, dynatracev1alpha1.ReasonTokenMissing,
+		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenMissing,
 			"Token apiToken on secret dynatrace:dynakube missing")
 
 		mock.AssertExpectationsForObjects(t, dtcMock)
@@ -101,9 +106,9 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.True(t, ucr)
 		assert.NoError(t, err)
 
-		AssertCondition(t, dk, dynatracev1alpha1.PaaSTokenConditionType, false, dynatracev1alpha1.ReasonTokenUnauthorized,
+		AssertCondition(t, dk, dynatracev1beta1.PaaSTokenConditionType, false, dynatracev1beta1.ReasonTokenUnauthorized,
 			"Token on secret dynatrace:dynakube unauthorized")
-		AssertCondition(t, dk, dynatracev1alpha1.APITokenConditionType, false, dynatracev1alpha1.ReasonTokenError,
+		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenError,
 			"error when querying token on secret dynatrace:dynakube: random error")
 
 		mock.AssertExpectationsForObjects(t, dtcMock)
@@ -129,9 +134,9 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.True(t, ucr)
 		assert.NoError(t, err)
 
-		AssertCondition(t, dk, dynatracev1alpha1.PaaSTokenConditionType, false, dynatracev1alpha1.ReasonTokenScopeMissing,
+		AssertCondition(t, dk, dynatracev1beta1.PaaSTokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing,
 			"Token on secret dynatrace:dynakube missing scope InstallerDownload")
-		AssertCondition(t, dk, dynatracev1alpha1.APITokenConditionType, false, dynatracev1alpha1.ReasonTokenUnauthorized,
+		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenUnauthorized,
 			"Token on secret dynatrace:dynakube has leading and/or trailing spaces")
 
 		mock.AssertExpectationsForObjects(t, dtcMock)
@@ -158,8 +163,8 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.True(t, ucr)
 		assert.NoError(t, err)
 
-		AssertCondition(t, dk, dynatracev1alpha1.PaaSTokenConditionType, true, dynatracev1alpha1.ReasonTokenReady, "Ready")
-		AssertCondition(t, dk, dynatracev1alpha1.APITokenConditionType, true, dynatracev1alpha1.ReasonTokenReady, "Ready")
+		AssertCondition(t, dk, dynatracev1beta1.PaaSTokenConditionType, true, dynatracev1beta1.ReasonTokenReady, "Ready")
+		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, true, dynatracev1beta1.ReasonTokenReady, "Ready")
 
 		mock.AssertExpectationsForObjects(t, dtcMock)
 	})
@@ -171,26 +176,26 @@ func TestReconcileDynatraceClient_MigrateConditions(t *testing.T) {
 
 	namespace := "dynatrace"
 	dynaKubeName := "dynakube"
-	dk := dynatracev1alpha1.DynaKube{
+	dk := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: dynaKubeName, Namespace: namespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 			Tokens: dynaKubeName,
 		},
-		Status: dynatracev1alpha1.DynaKubeStatus{
+		Status: dynatracev1beta1.DynaKubeStatus{
 			LastAPITokenProbeTimestamp:  &lastProbe,
 			LastPaaSTokenProbeTimestamp: &lastProbe,
 			Conditions: []metav1.Condition{
 				{
-					Type:    dynatracev1alpha1.APITokenConditionType,
+					Type:    dynatracev1beta1.APITokenConditionType,
 					Status:  metav1.ConditionTrue,
-					Reason:  dynatracev1alpha1.ReasonTokenReady,
+					Reason:  dynatracev1beta1.ReasonTokenReady,
 					Message: "Ready",
 				},
 				{
-					Type:    dynatracev1alpha1.PaaSTokenConditionType,
+					Type:    dynatracev1beta1.PaaSTokenConditionType,
 					Status:  metav1.ConditionTrue,
-					Reason:  dynatracev1alpha1.ReasonTokenReady,
+					Reason:  dynatracev1beta1.ReasonTokenReady,
 					Message: "Ready",
 				},
 			},
@@ -225,26 +230,26 @@ func TestReconcileDynatraceClient_ProbeRequests(t *testing.T) {
 
 	namespace := "dynatrace"
 	oaName := "dynakube"
-	base := dynatracev1alpha1.DynaKube{
+	base := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: oaName, Namespace: namespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 			Tokens: oaName,
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				Enabled: true,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 			},
 		},
 	}
 	meta.SetStatusCondition(&base.Status.Conditions, metav1.Condition{
-		Type:    dynatracev1alpha1.APITokenConditionType,
+		Type:    dynatracev1beta1.APITokenConditionType,
 		Status:  metav1.ConditionTrue,
-		Reason:  dynatracev1alpha1.ReasonTokenReady,
+		Reason:  dynatracev1beta1.ReasonTokenReady,
 		Message: "Ready",
 	})
 	meta.SetStatusCondition(&base.Status.Conditions, metav1.Condition{
-		Type:    dynatracev1alpha1.PaaSTokenConditionType,
+		Type:    dynatracev1beta1.PaaSTokenConditionType,
 		Status:  metav1.ConditionTrue,
-		Reason:  dynatracev1alpha1.ReasonTokenReady,
+		Reason:  dynatracev1beta1.ReasonTokenReady,
 		Message: "Ready",
 	})
 
@@ -315,7 +320,7 @@ func TestReconcileDynatraceClient_ProbeRequests(t *testing.T) {
 	})
 }
 
-func AssertCondition(t *testing.T, oa *dynatracev1alpha1.DynaKube, ct string, status bool, reason string, message string) {
+func AssertCondition(t *testing.T, oa *dynatracev1beta1.DynaKube, ct string, status bool, reason string, message string) {
 	t.Helper()
 	s := metav1.ConditionFalse
 	if status {
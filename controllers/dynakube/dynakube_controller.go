@@ -3,10 +3,9 @@ package dynakube
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/capability"
 	rcap "github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/capability"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtpullsecret"
@@ -25,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -34,6 +34,9 @@ import (
 
 const (
 	defaultUpdateInterval = 5 * time.Minute
+
+	eventReasonReconcileFailed = "ReconcileFailed"
+	eventReasonRateLimited     = "DynatraceAPIRateLimited"
 )
 
 var log = logf.Log.WithName("controller_dynakube")
@@ -48,6 +51,7 @@ func NewReconciler(mgr manager.Manager) *ReconcileDynaKube {
 		client:       mgr.GetClient(),
 		apiReader:    mgr.GetAPIReader(),
 		scheme:       mgr.GetScheme(),
+		recorder:     mgr.GetEventRecorderFor("dynakube-controller"),
 		dtcBuildFunc: BuildDynatraceClient,
 		config:       mgr.GetConfig(),
 	}
@@ -55,17 +59,18 @@ func NewReconciler(mgr manager.Manager) *ReconcileDynaKube {
 
 func (r *ReconcileDynaKube) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&dynatracev1alpha1.DynaKube{}).
+		For(&dynatracev1beta1.DynaKube{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.DaemonSet{}).
 		Complete(r)
 }
 
-func NewDynaKubeReconciler(c client.Client, apiReader client.Reader, scheme *runtime.Scheme, dtcBuildFunc DynatraceClientFunc, logger logr.Logger, config *rest.Config) *ReconcileDynaKube {
+func NewDynaKubeReconciler(c client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, dtcBuildFunc DynatraceClientFunc, logger logr.Logger, config *rest.Config) *ReconcileDynaKube {
 	return &ReconcileDynaKube{
 		client:       c,
 		apiReader:    apiReader,
 		scheme:       scheme,
+		recorder:     recorder,
 		dtcBuildFunc: dtcBuildFunc,
 		logger:       logger,
 		config:       config,
@@ -82,12 +87,13 @@ type ReconcileDynaKube struct {
 	client       client.Client
 	apiReader    client.Reader
 	scheme       *runtime.Scheme
+	recorder     record.EventRecorder
 	dtcBuildFunc DynatraceClientFunc
 	logger       logr.Logger
 	config       *rest.Config
 }
 
-type DynatraceClientFunc func(rtc client.Client, instance *dynatracev1alpha1.DynaKube, secret *corev1.Secret) (dtclient.Client, error)
+type DynatraceClientFunc func(rtc client.Client, instance *dynatracev1beta1.DynaKube, secret *corev1.Secret) (dtclient.Client, error)
 
 // Reconcile reads that state of the cluster for a DynaKube object and makes changes based on the state read
 // and what is in the DynaKube.Spec
@@ -100,7 +106,7 @@ func (r *ReconcileDynaKube) Reconcile(ctx context.Context, request reconcile.Req
 	reqLogger.Info("Reconciling DynaKube")
 
 	// Fetch the DynaKube instance
-	instance := &dynatracev1alpha1.DynaKube{}
+	instance := &dynatracev1beta1.DynaKube{}
 	err := r.client.Get(ctx, request.NamespacedName, instance)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
@@ -123,15 +129,22 @@ func (r *ReconcileDynaKube) Reconcile(ctx context.Context, request reconcile.Req
 			}
 		}
 
-		var serr dtclient.ServerError
-		if ok := errors.As(rec.Err, &serr); ok && serr.Code == http.StatusTooManyRequests {
-			rec.Log.Info("Request limit for Dynatrace API reached! Next reconcile in one minute")
-			return reconcile.Result{RequeueAfter: 1 * time.Minute}, nil
+		if retryAfter, ok := dtclient.RetryAfter(rec.Err); ok {
+			rec.Log.Info("Request limit for Dynatrace API reached! Postponing next reconcile", "retryAfter", retryAfter)
+			r.recorder.Eventf(instance, corev1.EventTypeWarning, eventReasonRateLimited,
+				"Request limit for Dynatrace API reached, postponing next reconcile by %s", retryAfter)
+			return reconcile.Result{RequeueAfter: retryAfter}, nil
 		}
 
+		r.recorder.Event(instance, corev1.EventTypeWarning, eventReasonReconcileFailed, rec.Err.Error())
+
 		return reconcile.Result{}, rec.Err
 	}
 
+	if instance.Status.SetPhase(instance.Status.PhaseFromConditions()) {
+		rec.Update(true, rec.RequeueAfter, "Phase changed")
+	}
+
 	if rec.Updated {
 		if err := r.updateCR(ctx, reqLogger, instance); err != nil {
 			return reconcile.Result{}, err
@@ -145,6 +158,7 @@ func (r *ReconcileDynaKube) reconcileDynaKube(ctx context.Context, rec *utils.Re
 	dtcReconciler := DynatraceClientReconciler{
 		Client:              r.client,
 		DynatraceClientFunc: r.dtcBuildFunc,
+		Recorder:            r.recorder,
 		UpdateAPIToken:      true,
 		UpdatePaaSToken:     true,
 	}
@@ -155,7 +169,7 @@ func (r *ReconcileDynaKube) reconcileDynaKube(ctx context.Context, rec *utils.Re
 		return
 	}
 
-	err = status.SetDynakubeStatus(rec.Instance, status.Options{
+	err = status.SetDynakubeStatus(ctx, rec.Instance, status.Options{
 		Dtc:       dtc,
 		ApiClient: r.apiReader,
 	})
@@ -172,54 +186,76 @@ func (r *ReconcileDynaKube) reconcileDynaKube(ctx context.Context, rec *utils.Re
 	}
 
 	if rec.Instance.Spec.EnableIstio {
-		if upd, err = istio.NewController(r.config, r.scheme).ReconcileIstio(rec.Instance); err != nil {
+		upd, err = istio.NewController(r.config, r.scheme, r.recorder).ReconcileIstio(rec.Instance)
+		rec.Update(reconcileResultCondition(rec.Instance, dynatracev1beta1.IstioConfiguredConditionType, err, "Istio objects are up-to-date"),
+			defaultUpdateInterval, "Istio condition updated")
+
+		if err != nil {
 			// If there are errors log them, but move on.
 			rec.Log.Info("Istio: failed to reconcile objects", "error", err)
 		} else if upd {
 			rec.Update(true, 30*time.Second, "Istio: objects updated")
 		}
+	} else {
+		rec.Update(rec.Instance.Status.RemoveCondition(dynatracev1beta1.IstioConfiguredConditionType), defaultUpdateInterval, "Istio condition removed")
 	}
 
 	err = dtpullsecret.
-		NewReconciler(r.client, r.apiReader, r.scheme, rec.Instance, rec.Log, secret).
+		NewReconciler(r.client, r.apiReader, r.scheme, r.recorder, rec.Instance, rec.Log, secret).
 		Reconcile()
+	upd = reconcileResultCondition(rec.Instance, dynatracev1beta1.PullSecretReadyConditionType, err, "Pull secret is up-to-date")
+	rec.Update(upd, defaultUpdateInterval, "Pull secret condition updated")
 	if rec.Error(err) {
 		rec.Log.Error(err, "could not reconcile Dynatrace pull secret")
 		return
 	}
 
-	upd, err = updates.ReconcileVersions(ctx, rec, r.client, dtversion.GetImageVersion)
+	upd, err = updates.ReconcileVersions(ctx, rec, r.client, dtc, r.recorder, dtversion.GetImageVersion)
 	rec.Update(upd, defaultUpdateInterval, "Found updates")
 	rec.Error(err)
 
+	upd, err = r.reconcileWebhookCondition(ctx, rec.Instance)
+	rec.Update(upd, defaultUpdateInterval, "Webhook condition updated")
+	if rec.Error(err) {
+		return
+	}
+
+	upd, err = r.reconcileCSIProvisionerCondition(ctx, rec.Instance)
+	rec.Update(upd, defaultUpdateInterval, "CSI provisioner condition updated")
+	if rec.Error(err) {
+		return
+	}
+
 	if !r.reconcileActiveGateCapabilities(rec) {
 		return
 	}
 
-	if rec.Instance.Spec.InfraMonitoring.Enabled {
+	if !rec.Instance.NeedsOneAgent() {
+		rec.Update(rec.Instance.Status.RemoveCondition(dynatracev1beta1.OneAgentReadyConditionType), defaultUpdateInterval, "OneAgent condition removed")
+	}
+
+	if rec.Instance.HostMonitoringMode() || rec.Instance.CloudNativeFullStackMode() {
 		upd, err = oneagent.NewOneAgentReconciler(
-			r.client, r.apiReader, r.scheme, rec.Log, rec.Instance, &rec.Instance.Spec.InfraMonitoring, oneagent.InframonFeature,
+			r.client, r.apiReader, r.scheme, r.recorder, dtc, rec.Log, rec.Instance, rec.Instance.HostInjectSpec(), oneagent.InframonFeature,
 		).Reconcile(ctx, rec)
 		if rec.Error(err) || rec.Update(upd, defaultUpdateInterval, "infra monitoring reconciled") {
 			return
 		}
 	} else {
-		ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: rec.Instance.Name + "-inframon", Namespace: rec.Instance.Namespace}}
-		if err := r.ensureDeleted(&ds); rec.Error(err) {
+		if err := oneagent.DeleteDaemonSets(ctx, r.client, rec.Instance, oneagent.InframonFeature); rec.Error(err) {
 			return
 		}
 	}
 
-	if rec.Instance.Spec.ClassicFullStack.Enabled {
+	if rec.Instance.ClassicFullStackMode() {
 		upd, err = oneagent.NewOneAgentReconciler(
-			r.client, r.apiReader, r.scheme, rec.Log, rec.Instance, &rec.Instance.Spec.ClassicFullStack, oneagent.ClassicFeature,
+			r.client, r.apiReader, r.scheme, r.recorder, dtc, rec.Log, rec.Instance, rec.Instance.Spec.OneAgent.ClassicFullStack, oneagent.ClassicFeature,
 		).Reconcile(ctx, rec)
 		if rec.Error(err) || rec.Update(upd, defaultUpdateInterval, "classic fullstack reconciled") {
 			return
 		}
 	} else {
-		ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: rec.Instance.Name + "-classic", Namespace: rec.Instance.Namespace}}
-		if err := r.ensureDeleted(&ds); rec.Error(err) {
+		if err := oneagent.DeleteDaemonSets(ctx, r.client, rec.Instance, oneagent.ClassicFeature); rec.Error(err) {
 			return
 		}
 	}
@@ -242,7 +278,7 @@ func (r *ReconcileDynaKube) reconcileActiveGateCapabilities(rec *utils.Reconcili
 	for _, c := range caps {
 		if c.GetProperties().Enabled {
 			upd, err := rcap.NewReconciler(
-				c, r.client, r.apiReader, r.scheme, rec.Log, rec.Instance, dtversion.GetImageVersion,
+				c, r.client, r.apiReader, r.scheme, r.recorder, rec.Log, rec.Instance, dtversion.GetImageVersion,
 			).Reconcile()
 			if rec.Error(err) || rec.Update(upd, defaultUpdateInterval, c.GetModuleName()+" reconciled") {
 				return false
@@ -257,6 +293,8 @@ func (r *ReconcileDynaKube) reconcileActiveGateCapabilities(rec *utils.Reconcili
 			if err := r.ensureDeleted(&sts); rec.Error(err) {
 				return false
 			}
+			rec.Update(rec.Instance.Status.RemoveCondition(dynatracev1beta1.ActiveGateReadyConditionType(c.GetModuleName())),
+				defaultUpdateInterval, c.GetModuleName()+" condition removed")
 
 			if c.GetConfiguration().CreateService {
 				svc := corev1.Service{
@@ -275,13 +313,13 @@ func (r *ReconcileDynaKube) reconcileActiveGateCapabilities(rec *utils.Reconcili
 	return true
 }
 
-func (r *ReconcileDynaKube) getTokenSecret(ctx context.Context, instance *dynatracev1alpha1.DynaKube) (*corev1.Secret, error) {
+func (r *ReconcileDynaKube) getTokenSecret(ctx context.Context, instance *dynatracev1beta1.DynaKube) (*corev1.Secret, error) {
 	var secret corev1.Secret
 	err := r.client.Get(ctx, client.ObjectKey{Name: instance.Tokens(), Namespace: instance.Namespace}, &secret)
 	return &secret, errors.WithStack(err)
 }
 
-func (r *ReconcileDynaKube) updateCR(ctx context.Context, log logr.Logger, instance *dynatracev1alpha1.DynaKube) error {
+func (r *ReconcileDynaKube) updateCR(ctx context.Context, log logr.Logger, instance *dynatracev1beta1.DynaKube) error {
 	instance.Status.UpdatedTimestamp = metav1.Now()
 	err := r.client.Status().Update(ctx, instance)
 	if err != nil && k8serrors.IsConflict(err) {
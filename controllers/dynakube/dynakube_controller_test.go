@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/capability"
 	rcap "github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/capability"
 	"github.com/Dynatrace/dynatrace-operator/controllers/kubesystem"
@@ -18,6 +18,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -42,7 +43,8 @@ const (
 func TestReconcileActiveGate_Reconcile(t *testing.T) {
 	t.Run(`Reconcile works with minimal setup`, func(t *testing.T) {
 		r := &ReconcileDynaKube{
-			client: fake.NewClient(),
+			client:   fake.NewClient(),
+			recorder: &record.FakeRecorder{},
 		}
 		result, err := r.Reconcile(context.TODO(), reconcile.Request{})
 
@@ -78,7 +80,7 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 		mockClient.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
 		mockClient.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return(testVersion, nil)
 
-		instance := &v1alpha1.DynaKube{
+		instance := &v1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      testName,
 				Namespace: testNamespace,
@@ -104,7 +106,8 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 			client:    fakeClient,
 			apiReader: fakeClient,
 			scheme:    scheme.Scheme,
-			dtcBuildFunc: func(_ client.Client, _ *v1alpha1.DynaKube, _ *corev1.Secret) (dtclient.Client, error) {
+			recorder:  &record.FakeRecorder{},
+			dtcBuildFunc: func(_ client.Client, _ *v1beta1.DynaKube, _ *corev1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 		}
@@ -117,14 +120,14 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 	})
 	t.Run(`Reconcile reconciles Kubernetes Monitoring if enabled`, func(t *testing.T) {
 		mockClient := &dtclient.MockDynatraceClient{}
-		instance := &v1alpha1.DynaKube{
+		instance := &v1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      testName,
 				Namespace: testNamespace,
 			},
-			Spec: v1alpha1.DynaKubeSpec{
-				KubernetesMonitoringSpec: v1alpha1.KubernetesMonitoringSpec{
-					CapabilityProperties: v1alpha1.CapabilityProperties{
+			Spec: v1beta1.DynaKubeSpec{
+				KubernetesMonitoringSpec: v1beta1.KubernetesMonitoringSpec{
+					CapabilityProperties: v1beta1.CapabilityProperties{
 						Enabled: true,
 					},
 				}}}
@@ -147,7 +150,8 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 			client:    fakeClient,
 			apiReader: fakeClient,
 			scheme:    scheme.Scheme,
-			dtcBuildFunc: func(_ client.Client, _ *v1alpha1.DynaKube, _ *corev1.Secret) (dtclient.Client, error) {
+			recorder:  &record.FakeRecorder{},
+			dtcBuildFunc: func(_ client.Client, _ *v1beta1.DynaKube, _ *corev1.Secret) (dtclient.Client, error) {
 				return mockClient, nil
 			},
 		}
@@ -197,14 +201,14 @@ func TestReconcileActiveGate_Reconcile(t *testing.T) {
 
 func TestReconcile_RemoveRoutingIfDisabled(t *testing.T) {
 	mockClient := &dtclient.MockDynatraceClient{}
-	instance := &v1alpha1.DynaKube{
+	instance := &v1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      testName,
 			Namespace: testNamespace,
 		},
-		Spec: v1alpha1.DynaKubeSpec{
-			RoutingSpec: v1alpha1.RoutingSpec{
-				CapabilityProperties: v1alpha1.CapabilityProperties{
+		Spec: v1beta1.DynaKubeSpec{
+			RoutingSpec: v1beta1.RoutingSpec{
+				CapabilityProperties: v1beta1.CapabilityProperties{
 					Enabled: true,
 				},
 			}}}
@@ -227,7 +231,8 @@ func TestReconcile_RemoveRoutingIfDisabled(t *testing.T) {
 		client:    fakeClient,
 		apiReader: fakeClient,
 		scheme:    scheme.Scheme,
-		dtcBuildFunc: func(_ client.Client, _ *v1alpha1.DynaKube, _ *corev1.Secret) (dtclient.Client, error) {
+		recorder:  &record.FakeRecorder{},
+		dtcBuildFunc: func(_ client.Client, _ *v1beta1.DynaKube, _ *corev1.Secret) (dtclient.Client, error) {
 			return mockClient, nil
 		},
 	}
@@ -1,7 +1,9 @@
 package status
 
 import (
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/pkg/errors"
@@ -13,7 +15,7 @@ type Options struct {
 	ApiClient client.Reader
 }
 
-func SetDynakubeStatus(instance *dynatracev1alpha1.DynaKube, opts Options) error {
+func SetDynakubeStatus(ctx context.Context, instance *dynatracev1beta1.DynaKube, opts Options) error {
 	clt := opts.ApiClient
 	dtc := opts.Dtc
 
@@ -27,24 +29,24 @@ func SetDynakubeStatus(instance *dynatracev1alpha1.DynaKube, opts Options) error
 		return errors.WithStack(err)
 	}
 
-	connectionInfo, err := dtc.GetConnectionInfo()
+	connectionInfo, err := dtc.GetConnectionInfo(ctx)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	latestAgentVersionUnixDefault, err := dtc.GetLatestAgentVersion(dtclient.OsUnix, dtclient.InstallerTypeDefault)
+	latestAgentVersionUnixDefault, err := dtc.GetLatestAgentVersion(ctx, dtclient.OsUnix, dtclient.InstallerTypeDefault)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	latestAgentVersionUnixPaas, err := dtc.GetLatestAgentVersion(dtclient.OsUnix, dtclient.InstallerTypePaaS)
+	latestAgentVersionUnixPaas, err := dtc.GetLatestAgentVersion(ctx, dtclient.OsUnix, dtclient.InstallerTypePaaS)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	communicationHostStatus := dynatracev1alpha1.CommunicationHostStatus(communicationHost)
+	communicationHostStatus := dynatracev1beta1.CommunicationHostStatus(communicationHost)
 
-	connectionInfoStatus := dynatracev1alpha1.ConnectionInfoStatus{
+	connectionInfoStatus := dynatracev1beta1.ConnectionInfoStatus{
 		CommunicationHosts: communicationHostsToStatus(connectionInfo.CommunicationHosts),
 		TenantUUID:         connectionInfo.TenantUUID,
 	}
@@ -58,11 +60,11 @@ func SetDynakubeStatus(instance *dynatracev1alpha1.DynaKube, opts Options) error
 	return nil
 }
 
-func communicationHostsToStatus(communicationHosts []dtclient.CommunicationHost) []dynatracev1alpha1.CommunicationHostStatus {
-	var communicationHostStatuses []dynatracev1alpha1.CommunicationHostStatus
+func communicationHostsToStatus(communicationHosts []dtclient.CommunicationHost) []dynatracev1beta1.CommunicationHostStatus {
+	var communicationHostStatuses []dynatracev1beta1.CommunicationHostStatus
 
 	for _, communicationHost := range communicationHosts {
-		communicationHostStatuses = append(communicationHostStatuses, dynatracev1alpha1.CommunicationHostStatus(communicationHost))
+		communicationHostStatuses = append(communicationHostStatuses, dynatracev1beta1.CommunicationHostStatus(communicationHost))
 	}
 
 	return communicationHostStatuses
@@ -1,10 +1,11 @@
 package status
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
@@ -39,7 +40,7 @@ func TestStatusOptions(t *testing.T) {
 
 func TestSetDynakubeStatus(t *testing.T) {
 	t.Run(`set status`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -77,7 +78,7 @@ func TestSetDynakubeStatus(t *testing.T) {
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return(testVersionPaas, nil)
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 
 		assert.NoError(t, err)
 		assert.Equal(t, testUUID, instance.Status.KubeSystemUUID)
@@ -88,7 +89,7 @@ func TestSetDynakubeStatus(t *testing.T) {
 		assert.NotNil(t, instance.Status.ConnectionInfo)
 		assert.Equal(t, testUUID, instance.Status.ConnectionInfo.TenantUUID)
 		assert.NotNil(t, instance.Status.ConnectionInfo.CommunicationHosts)
-		assert.Equal(t, []dynatracev1alpha1.CommunicationHostStatus{
+		assert.Equal(t, []dynatracev1beta1.CommunicationHostStatus{
 			{
 				Protocol: testProtocol,
 				Host:     testHost,
@@ -105,7 +106,7 @@ func TestSetDynakubeStatus(t *testing.T) {
 		assert.Equal(t, testVersionPaas, instance.Status.LatestAgentVersionUnixPaas)
 	})
 	t.Run(`error querying kube system uid`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient()
 		options := Options{
@@ -113,11 +114,11 @@ func TestSetDynakubeStatus(t *testing.T) {
 			ApiClient: clt,
 		}
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, "namespaces \"kube-system\" not found")
 	})
 	t.Run(`error querying communication host for client`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -132,11 +133,11 @@ func TestSetDynakubeStatus(t *testing.T) {
 
 		dtc.On("GetCommunicationHostForClient").Return(dtclient.CommunicationHost{}, fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 	t.Run(`error querying connection info`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -157,11 +158,11 @@ func TestSetDynakubeStatus(t *testing.T) {
 
 		dtc.On("GetConnectionInfo").Return(dtclient.ConnectionInfo{}, fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 	t.Run(`error querying latest agent version for unix / default`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -198,11 +199,11 @@ func TestSetDynakubeStatus(t *testing.T) {
 
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return("", fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 	t.Run(`error querying latest agent version for unix / paas`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		dtc := &dtclient.MockDynatraceClient{}
 		clt := fake.NewClient(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -240,7 +241,7 @@ func TestSetDynakubeStatus(t *testing.T) {
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return(testVersion, nil)
 		dtc.On("GetLatestAgentVersion", dtclient.OsUnix, dtclient.InstallerTypePaaS).Return("", fmt.Errorf(testError))
 
-		err := SetDynakubeStatus(instance, options)
+		err := SetDynakubeStatus(context.TODO(), instance, options)
 		assert.EqualError(t, err, testError)
 	})
 }
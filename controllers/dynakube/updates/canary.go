@@ -0,0 +1,174 @@
+package updates
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube/updates/maintenance"
+	"github.com/Dynatrace/dynatrace-operator/controllers/oneagent"
+	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	eventReasonCanaryPromoted = "CanaryPromoted"
+	eventReasonCanarySkipped  = "CanarySkipped"
+)
+
+// reconcileCanary replaces the OneAgent pods on the canary nodes which don't run the canary version yet, and promotes the
+// canary version to all nodes once the pods on the canary nodes are ready. Returns true if the canary version has been
+// promoted. Promoting the canary version is postponed until the next maintenance window.
+func reconcileCanary(ctx context.Context, rec *utils.Reconciliation, cl client.Client, recorder record.EventRecorder, window *maintenance.Window, dk *dynatracev1beta1.DynaKube) (bool, error) {
+	canary := dk.Status.OneAgent.Canary
+
+	policy := dk.OneAgentVersionPolicy()
+	if policy == nil || policy.Canary == nil {
+		rec.Log.Info("Canary nodes no longer configured, rolling out OneAgent to all nodes", "version", canary.Version)
+		return promoteCanary(rec, recorder, window, dk), nil
+	}
+
+	dsList, err := oneagent.ListDaemonSets(ctx, cl, dk, oneAgentFeature(dk))
+	if err != nil || len(dsList) == 0 {
+		return false, err
+	}
+
+	// Pods on the canary nodes are only replaced once the DaemonSets have been updated to the canary version, otherwise
+	// they would be recreated with the previous version.
+	if !isDaemonSetVersion(dsList, canary.Version) {
+		return false, nil
+	}
+
+	canaryNodes, err := getCanaryNodes(ctx, cl, policy.Canary.NodeSelector, dk.HostInjectSpec().NodeSelector)
+	if err != nil {
+		return false, err
+	}
+
+	pods, err := oneagent.ListPods(ctx, cl, dk, oneAgentFeature(dk))
+	if err != nil {
+		return false, err
+	}
+
+	ready := 0
+	podNodes := map[string]bool{}
+	for i := range pods {
+		pod := &pods[i]
+		if !canaryNodes[pod.Spec.NodeName] {
+			continue
+		}
+		podNodes[pod.Spec.NodeName] = true
+
+		if pod.Annotations[statefulset.AnnotationVersion] != canary.Version {
+			rec.Log.Info("Replacing OneAgent pod on canary node", "pod", pod.Name, "node", pod.Spec.NodeName, "version", canary.Version)
+			if err := cl.Delete(ctx, pod); err != nil && !k8serrors.IsNotFound(err) {
+				return false, errors.WithStack(err)
+			}
+			continue
+		}
+
+		if isPodReady(pod) {
+			ready++
+		}
+	}
+
+	// Canary nodes which didn't get a OneAgent pod within the wait ready duration, e.g. because the DaemonSet doesn't
+	// tolerate their taints or the node isn't ready, are ignored so they don't stall the rollout.
+	waitForPods := rec.Now.Sub(canary.StartedTimestamp.Time) <= dk.OneAgentWaitReadyDuration()
+	expected := 0
+	for node := range canaryNodes {
+		if podNodes[node] || waitForPods {
+			expected++
+		} else {
+			rec.Log.Info("Ignoring canary node without OneAgent pod", "node", node, "version", canary.Version)
+		}
+	}
+
+	if expected == 0 {
+		version := canary.Version
+		if !promoteCanary(rec, recorder, window, dk) {
+			return false, nil
+		}
+		recorder.Eventf(dk, corev1.EventTypeWarning, eventReasonCanarySkipped, "No canary nodes found for OneAgent version %s", version)
+		return true, nil
+	}
+
+	if ready < expected {
+		rec.Log.Info("Waiting for OneAgent pods on canary nodes", "version", canary.Version, "ready", ready, "nodes", expected)
+		return false, nil
+	}
+
+	rec.Log.Info("OneAgent pods on canary nodes are ready", "version", canary.Version)
+	return promoteCanary(rec, recorder, window, dk), nil
+}
+
+// oneAgentFeature returns the feature of the OneAgent DaemonSets deployed for the DynaKube.
+func oneAgentFeature(dk *dynatracev1beta1.DynaKube) string {
+	if dk.ClassicFullStackMode() {
+		return oneagent.ClassicFeature
+	}
+	return oneagent.InframonFeature
+}
+
+// isDaemonSetVersion returns true if the OneAgent DaemonSets of all node architectures have been updated to the version.
+func isDaemonSetVersion(dsList []appsv1.DaemonSet, version string) bool {
+	for _, ds := range dsList {
+		if ds.Spec.Template.Annotations[statefulset.AnnotationVersion] != version {
+			return false
+		}
+	}
+	return true
+}
+
+// getCanaryNodes returns the names of the nodes matched by the canary node selector on which OneAgent is deployed.
+func getCanaryNodes(ctx context.Context, cl client.Client, canarySelector map[string]string, dsSelector map[string]string) (map[string]bool, error) {
+	selector := make(map[string]string, len(canarySelector)+len(dsSelector))
+	for k, v := range dsSelector {
+		selector[k] = v
+	}
+	for k, v := range canarySelector {
+		if dsValue, ok := selector[k]; ok && dsValue != v {
+			return nil, nil
+		}
+		selector[k] = v
+	}
+
+	var nodes corev1.NodeList
+	if err := cl.List(ctx, &nodes, client.MatchingLabels(selector)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	canaryNodes := make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		canaryNodes[node.Name] = true
+	}
+	return canaryNodes, nil
+}
+
+func promoteCanary(rec *utils.Reconciliation, recorder record.EventRecorder, window *maintenance.Window, dk *dynatracev1beta1.DynaKube) bool {
+	version := dk.Status.OneAgent.Canary.Version
+	if !window.Contains(rec.Now.Time) {
+		rec.Log.Info("Waiting for maintenance window to roll out OneAgent to all nodes", "version", version)
+		return false
+	}
+
+	recorder.Eventf(dk, corev1.EventTypeNormal, eventReasonCanaryPromoted, "Updating OneAgent on all nodes to version %s", version)
+
+	startRollout(rec, dk, dk.Status.OneAgent.Version)
+	dk.Status.OneAgent.Version = version
+	dk.Status.OneAgent.Canary = nil
+	return true
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package updates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube/updates/maintenance"
+	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/logger"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileCanary(t *testing.T) {
+	const (
+		oldVersion = "1.213.0.20210301-123456"
+		newVersion = "1.215.0.20210415-123456"
+	)
+	podLabels := map[string]string{
+		"dynatrace.com/component":         "operator",
+		"operator.dynatrace.com/instance": testName,
+		"operator.dynatrace.com/feature":  "classic",
+	}
+
+	now := metav1.Now()
+	newReconciliation := func() *utils.Reconciliation {
+		dk := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						VersionPolicy: &dynatracev1beta1.VersionPolicySpec{
+							Canary: &dynatracev1beta1.CanarySpec{NodeSelector: map[string]string{"canary": "true"}},
+						},
+					},
+				},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					VersionStatus: dynatracev1beta1.VersionStatus{Version: oldVersion},
+					Canary:        &dynatracev1beta1.CanaryStatus{Version: newVersion, StartedTimestamp: now},
+				},
+			},
+		}
+		return &utils.Reconciliation{Instance: &dk, Log: logger.NewDTLogger(), Now: now}
+	}
+	newDaemonSet := func(version string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: testName + "-classic", Namespace: testNamespace, Labels: podLabels},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{statefulset.AnnotationVersion: version}},
+				},
+			},
+		}
+	}
+	newNode := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	newPod := func(name, node, version string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   testNamespace,
+				Labels:      podLabels,
+				Annotations: map[string]string{statefulset.AnnotationVersion: version},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		}
+	}
+
+	t.Run(`waits for daemonset to be updated`, func(t *testing.T) {
+		rec := newReconciliation()
+		fakeClient := fake.NewClient(
+			newDaemonSet(oldVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newPod("canary-pod", "canary-node", oldVersion, true))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), nil, rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, promoted)
+		assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "canary-pod", Namespace: testNamespace}, &corev1.Pod{}))
+	})
+	t.Run(`replaces outdated pods on canary nodes only`, func(t *testing.T) {
+		rec := newReconciliation()
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newNode("other-node", nil),
+			newPod("canary-pod", "canary-node", oldVersion, true),
+			newPod("other-pod", "other-node", oldVersion, true))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), nil, rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, promoted)
+
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: "canary-pod", Namespace: testNamespace}, &corev1.Pod{})
+		assert.True(t, k8serrors.IsNotFound(err))
+		assert.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "other-pod", Namespace: testNamespace}, &corev1.Pod{}))
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+	})
+	t.Run(`waits for pods on canary nodes to be ready`, func(t *testing.T) {
+		rec := newReconciliation()
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newPod("canary-pod", "canary-node", newVersion, false))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), nil, rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, promoted)
+		assert.NotNil(t, rec.Instance.Status.OneAgent.Canary)
+	})
+	t.Run(`promotes version once pods on canary nodes are ready`, func(t *testing.T) {
+		rec := newReconciliation()
+		recorder := record.NewFakeRecorder(10)
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newNode("other-node", nil),
+			newPod("canary-pod", "canary-node", newVersion, true),
+			newPod("other-pod", "other-node", oldVersion, false))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, recorder, nil, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, promoted)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Nil(t, rec.Instance.Status.OneAgent.Canary)
+		assert.Equal(t, "Normal CanaryPromoted Updating OneAgent on all nodes to version "+newVersion, <-recorder.Events)
+	})
+	t.Run(`promotes version if no canary nodes exist`, func(t *testing.T) {
+		rec := newReconciliation()
+		recorder := record.NewFakeRecorder(10)
+		fakeClient := fake.NewClient(newDaemonSet(newVersion), newNode("other-node", nil))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, recorder, nil, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, promoted)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Contains(t, <-recorder.Events, "Normal CanaryPromoted")
+		assert.Contains(t, <-recorder.Events, "Warning CanarySkipped")
+	})
+	t.Run(`waits for pods on canary nodes without pod during the wait ready duration`, func(t *testing.T) {
+		rec := newReconciliation()
+		rec.Now = metav1.NewTime(now.Add(time.Minute))
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newNode("tainted-canary-node", map[string]string{"canary": "true"}),
+			newPod("canary-pod", "canary-node", newVersion, true))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), nil, rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, promoted)
+		assert.NotNil(t, rec.Instance.Status.OneAgent.Canary)
+	})
+	t.Run(`ignores canary nodes without pod after the wait ready duration`, func(t *testing.T) {
+		rec := newReconciliation()
+		rec.Now = metav1.NewTime(now.Add(rec.Instance.OneAgentWaitReadyDuration() + time.Minute))
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newNode("tainted-canary-node", map[string]string{"canary": "true"}),
+			newPod("canary-pod", "canary-node", newVersion, true))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), nil, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, promoted)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+	})
+	t.Run(`promotes version if no canary node gets a pod`, func(t *testing.T) {
+		rec := newReconciliation()
+		rec.Now = metav1.NewTime(now.Add(rec.Instance.OneAgentWaitReadyDuration() + time.Minute))
+		recorder := record.NewFakeRecorder(10)
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("tainted-canary-node", map[string]string{"canary": "true"}))
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, recorder, nil, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, promoted)
+		assert.Contains(t, <-recorder.Events, "Normal CanaryPromoted")
+		assert.Contains(t, <-recorder.Events, "Warning CanarySkipped")
+	})
+	t.Run(`promotion waits for maintenance window`, func(t *testing.T) {
+		rec := newReconciliation()
+		rec.Now = metav1.NewTime(time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC))
+		fakeClient := fake.NewClient(
+			newDaemonSet(newVersion),
+			newNode("canary-node", map[string]string{"canary": "true"}),
+			newPod("canary-pod", "canary-node", newVersion, true))
+
+		window, err := maintenance.NewWindow(&dynatracev1beta1.MaintenanceWindowSpec{
+			Schedule: "0 2 * * *",
+			Duration: metav1.Duration{Duration: time.Hour},
+		})
+		require.NoError(t, err)
+
+		promoted, err := reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), window, rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, promoted)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+
+		rec.Now = metav1.NewTime(time.Date(2021, 5, 2, 2, 30, 0, 0, time.UTC))
+		promoted, err = reconcileCanary(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), window, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, promoted)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+	})
+}
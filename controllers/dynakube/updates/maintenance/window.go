@@ -0,0 +1,178 @@
+package maintenance
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/pkg/errors"
+)
+
+// maxMaintenanceWindowLookahead limits the search for the start of the next maintenance window, for schedules which
+// never match like "0 0 31 2 *".
+const maxMaintenanceWindowLookahead = 5 * 366 * 24 * time.Hour
+
+// Window is a parsed MaintenanceWindowSpec. A nil Window contains any point in time.
+type Window struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// anyDay and anyWeekday are set if the field starts with "*", in which case both fields must match a day
+	anyDay     bool
+	anyWeekday bool
+
+	duration time.Duration
+}
+
+// NewWindow parses the schedule of the maintenance window, or returns nil if spec is nil.
+func NewWindow(spec *dynatracev1beta1.MaintenanceWindowSpec) (*Window, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	if spec.Duration.Duration <= 0 {
+		return nil, errors.Errorf("invalid maintenance window duration '%s'", spec.Duration.Duration)
+	}
+
+	fields := strings.Fields(spec.Schedule)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid maintenance window schedule '%s': expected 5 fields", spec.Schedule)
+	}
+
+	w := Window{
+		duration:   spec.Duration.Duration,
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}
+
+	var err error
+	for _, f := range []struct {
+		target   *uint64
+		expr     string
+		min, max int
+	}{
+		{&w.minutes, fields[0], 0, 59},
+		{&w.hours, fields[1], 0, 23},
+		{&w.days, fields[2], 1, 31},
+		{&w.months, fields[3], 1, 12},
+		{&w.weekdays, fields[4], 0, 7},
+	} {
+		if *f.target, err = parseScheduleField(f.expr, f.min, f.max); err != nil {
+			return nil, errors.WithMessagef(err, "invalid maintenance window schedule '%s'", spec.Schedule)
+		}
+	}
+
+	// Sunday can be given as 0 or 7
+	if w.weekdays&(1<<7) != 0 {
+		w.weekdays |= 1
+	}
+
+	return &w, nil
+}
+
+// parseScheduleField parses a comma separated list of values, ranges and steps, e.g. "1,10-20/2,*/15", into a bit set.
+func parseScheduleField(expr string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, errors.Errorf("invalid step in '%s'", part)
+			}
+			rangeExpr, step = part[:i], s
+		}
+
+		from, to := min, max
+		if rangeExpr != "*" {
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("invalid value in '%s'", part)
+			}
+			to = from
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.Errorf("invalid value in '%s'", part)
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, errors.Errorf("'%s' is out of range %d-%d", part, min, max)
+		}
+
+		for i := from; i <= to; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// matches returns true if a maintenance window starts at the minute of t.
+func (w *Window) matches(t time.Time) bool {
+	return w.minutes&(1<<uint(t.Minute())) != 0 &&
+		w.hours&(1<<uint(t.Hour())) != 0 &&
+		w.months&(1<<uint(t.Month())) != 0 &&
+		w.matchesDay(t)
+}
+
+// matchesDay follows the cron convention that a day matches either the day of month or the day of week, unless one of
+// the fields starts with "*", e.g. "*/2", in which case both have to match.
+func (w *Window) matchesDay(t time.Time) bool {
+	day := w.days&(1<<uint(t.Day())) != 0
+	weekday := w.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if w.anyDay || w.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// Contains returns true if t is inside of a maintenance window.
+func (w *Window) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	t = t.UTC()
+	for start := t.Truncate(time.Minute); t.Sub(start) < w.duration; start = start.Add(-time.Minute) {
+		if w.matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the start of the next maintenance window after t, or the zero time if none has been found.
+func (w *Window) Next(t time.Time) time.Time {
+	if w == nil {
+		return time.Time{}
+	}
+
+	t = t.UTC()
+	limit := t.Add(maxMaintenanceWindowLookahead)
+
+	for start := t.Truncate(time.Minute).Add(time.Minute); start.Before(limit); {
+		switch {
+		case w.months&(1<<uint(start.Month())) == 0:
+			start = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !w.matchesDay(start):
+			start = time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+		case w.hours&(1<<uint(start.Hour())) == 0:
+			start = start.Truncate(time.Hour).Add(time.Hour)
+		case w.minutes&(1<<uint(start.Minute())) == 0:
+			start = start.Add(time.Minute)
+		default:
+			return start
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	newWindow := func(t *testing.T, schedule string, duration time.Duration) *Window {
+		w, err := NewWindow(&dynatracev1beta1.MaintenanceWindowSpec{Schedule: schedule, Duration: metav1.Duration{Duration: duration}})
+		require.NoError(t, err)
+		return w
+	}
+	at := func(day, hour, minute int) time.Time {
+		// 2021-05-01 is a Saturday
+		return time.Date(2021, 5, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	t.Run(`no maintenance window contains any time`, func(t *testing.T) {
+		w, err := NewWindow(nil)
+		require.NoError(t, err)
+		assert.True(t, w.Contains(at(1, 12, 0)))
+	})
+	t.Run(`invalid schedules are rejected`, func(t *testing.T) {
+		for _, schedule := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "a * * * *", "*/0 * * * *", "5-1 * * * *"} {
+			_, err := NewWindow(&dynatracev1beta1.MaintenanceWindowSpec{Schedule: schedule, Duration: metav1.Duration{Duration: time.Hour}})
+			assert.Error(t, err, schedule)
+		}
+
+		_, err := NewWindow(&dynatracev1beta1.MaintenanceWindowSpec{Schedule: "* * * * *"})
+		assert.Error(t, err)
+	})
+	t.Run(`daily window`, func(t *testing.T) {
+		w := newWindow(t, "30 2 * * *", 2*time.Hour)
+
+		assert.False(t, w.Contains(at(1, 2, 29)))
+		assert.True(t, w.Contains(at(1, 2, 30)))
+		assert.True(t, w.Contains(at(1, 4, 29)))
+		assert.False(t, w.Contains(at(1, 4, 30)))
+
+		assert.Equal(t, at(2, 2, 30), w.Next(at(1, 2, 30)))
+		assert.Equal(t, at(1, 2, 30), w.Next(at(1, 0, 0)))
+	})
+	t.Run(`window spanning midnight`, func(t *testing.T) {
+		w := newWindow(t, "0 23 * * 6", 3*time.Hour)
+
+		assert.True(t, w.Contains(at(1, 23, 0)))
+		assert.True(t, w.Contains(at(2, 1, 59)))
+		assert.False(t, w.Contains(at(2, 2, 0)))
+		assert.Equal(t, at(8, 23, 0), w.Next(at(2, 0, 0)))
+	})
+	t.Run(`lists, ranges and steps`, func(t *testing.T) {
+		w := newWindow(t, "*/15 1,3-4 * 5 1-5", time.Minute)
+
+		assert.True(t, w.Contains(at(3, 1, 45)))
+		assert.False(t, w.Contains(at(3, 2, 0)))
+		assert.True(t, w.Contains(at(3, 4, 30)))
+		assert.False(t, w.Contains(at(1, 1, 0)), "Saturday")
+		assert.Equal(t, at(3, 1, 0), w.Next(at(1, 0, 0)))
+	})
+	t.Run(`day of month or day of week`, func(t *testing.T) {
+		w := newWindow(t, "0 0 15 * 0", time.Hour)
+
+		assert.True(t, w.Contains(at(2, 0, 0)), "Sunday")
+		assert.True(t, w.Contains(at(15, 0, 0)), "15th")
+		assert.False(t, w.Contains(at(3, 0, 0)))
+
+		w = newWindow(t, "0 0 * * 7", time.Hour)
+		assert.True(t, w.Contains(at(2, 0, 0)), "Sunday given as 7")
+	})
+	t.Run(`steps starting with * restrict days together with the other field`, func(t *testing.T) {
+		w := newWindow(t, "0 0 */2 * 0", time.Hour)
+
+		assert.True(t, w.Contains(at(9, 0, 0)), "Sunday the 9th")
+		assert.False(t, w.Contains(at(2, 0, 0)), "Sunday the 2nd")
+		assert.False(t, w.Contains(at(3, 0, 0)))
+
+		w = newWindow(t, "0 0 15 * */2", time.Hour)
+		assert.True(t, w.Contains(at(15, 0, 0)), "Saturday the 15th")
+		assert.False(t, w.Contains(at(2, 0, 0)), "Sunday")
+	})
+	t.Run(`schedule without matches`, func(t *testing.T) {
+		w := newWindow(t, "0 0 31 2 *", time.Hour)
+		assert.True(t, w.Next(at(1, 0, 0)).IsZero())
+	})
+}
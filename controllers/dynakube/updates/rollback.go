@@ -0,0 +1,163 @@
+package updates
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/controllers/oneagent"
+	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const eventReasonVersionRollback = "VersionRollback"
+
+// startRollout remembers the version the OneAgent pods are updated from, to be able to roll back to it if the pods don't
+// become ready with the new version.
+func startRollout(rec *utils.Reconciliation, dk *dynatracev1beta1.DynaKube, previousVersion string) {
+	dk.Status.OneAgent.PreviousVersion = previousVersion
+	dk.Status.OneAgent.RolloutStartedTimestamp = rec.Now.DeepCopy()
+}
+
+func finishRollout(dk *dynatracev1beta1.DynaKube) {
+	dk.Status.OneAgent.PreviousVersion = ""
+	dk.Status.OneAgent.RolloutStartedTimestamp = nil
+}
+
+// reconcileRollout watches the OneAgent pods while a new version is rolled out. If a pod running the new version isn't
+// ready within the wait ready duration, the previous version is restored and the new one is blocked. Returns true if the
+// status has changed.
+//
+// Rollbacks are only possible with the installer, since the version of immutable images isn't controlled by the Operator.
+func reconcileRollout(ctx context.Context, rec *utils.Reconciliation, cl client.Client, recorder record.EventRecorder, dk *dynatracev1beta1.DynaKube) (bool, error) {
+	started := dk.Status.OneAgent.RolloutStartedTimestamp
+	if started == nil {
+		return false, nil
+	}
+
+	version := dk.OneAgentRolloutVersion()
+
+	dsList, err := oneagent.ListDaemonSets(ctx, cl, dk, oneAgentFeature(dk))
+	if err != nil || len(dsList) == 0 || !isDaemonSetVersion(dsList, version) {
+		return false, err
+	}
+
+	pods, err := oneagent.ListPods(ctx, cl, dk, oneAgentFeature(dk))
+	if err != nil {
+		return false, err
+	}
+
+	waitReady := dk.OneAgentWaitReadyDuration()
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Annotations[statefulset.AnnotationVersion] != version || !hasPodStarted(pod) || isPodReady(pod) {
+			continue
+		}
+
+		since := started.Time
+		if pod.CreationTimestamp.After(since) {
+			since = pod.CreationTimestamp.Time
+		}
+		if rec.Now.Sub(since) > waitReady {
+			rollback(rec, recorder, dk, pod)
+			return true, nil
+		}
+	}
+
+	if dk.Status.OneAgent.Canary == nil && isDaemonSetRolledOut(dsList) {
+		rec.Log.Info("OneAgent pods are ready", "version", version)
+		finishRollout(dk)
+		return true, nil
+	}
+	return false, nil
+}
+
+func rollback(rec *utils.Reconciliation, recorder record.EventRecorder, dk *dynatracev1beta1.DynaKube, pod *corev1.Pod) {
+	version := dk.OneAgentRolloutVersion()
+	previousVersion := dk.Status.OneAgent.PreviousVersion
+
+	rec.Log.Info("OneAgent pod didn't become ready, rolling back", "pod", pod.Name, "node", pod.Spec.NodeName,
+		"version", version, "previousVersion", previousVersion)
+	recorder.Eventf(dk, corev1.EventTypeWarning, eventReasonVersionRollback,
+		"OneAgent version %s didn't become ready on node %s within %s, rolling back to version %s",
+		version, pod.Spec.NodeName, dk.OneAgentWaitReadyDuration(), previousVersion)
+
+	if !dk.IsOneAgentVersionBlocked(version) {
+		dk.Status.OneAgent.BlockedVersions = append(dk.Status.OneAgent.BlockedVersions, version)
+	}
+	if dk.Status.OneAgent.Canary != nil {
+		dk.Status.OneAgent.Canary = nil
+	} else {
+		dk.Status.OneAgent.Version = previousVersion
+	}
+	finishRollout(dk)
+}
+
+// hasPodStarted returns true if the containers of the pod have been started. Pods which are still pending, e.g. because
+// they can't be scheduled or their image can't be pulled, don't tell whether the OneAgent version works.
+func hasPodStarted(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodRunning || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.RestartCount > 0 || status.State.Running != nil || status.State.Terminated != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileUnblockedVersions removes the versions listed by the unblock-oneagent-versions feature flag from the blocked
+// versions. The annotation is removed again, so that the versions are blocked by later rollbacks. Returns true if the
+// status has changed.
+func reconcileUnblockedVersions(ctx context.Context, rec *utils.Reconciliation, cl client.Client, dk *dynatracev1beta1.DynaKube) (bool, error) {
+	versions, ok := dk.FeatureUnblockOneAgentVersions()
+	if !ok {
+		return false, nil
+	}
+
+	// Only the metadata is updated here, the status is updated at the end of the reconciliation.
+	updated := dk.DeepCopy()
+	delete(updated.Annotations, dk.GetFeatureUnblockOneAgentVersions())
+	if err := cl.Update(ctx, updated); err != nil {
+		return false, errors.WithMessage(err, "failed to remove annotation for unblocked OneAgent versions")
+	}
+	dk.Annotations = updated.Annotations
+	dk.ResourceVersion = updated.ResourceVersion
+
+	unblock := map[string]bool{}
+	for _, v := range versions {
+		unblock[v] = true
+	}
+
+	var blocked []string
+	for _, v := range dk.Status.OneAgent.BlockedVersions {
+		if unblock[v] || unblock["all"] {
+			rec.Log.Info("Unblocking OneAgent version", "version", v)
+			continue
+		}
+		blocked = append(blocked, v)
+	}
+
+	if len(blocked) == len(dk.Status.OneAgent.BlockedVersions) {
+		return false, nil
+	}
+	dk.Status.OneAgent.BlockedVersions = blocked
+	return true, nil
+}
+
+// isDaemonSetRolledOut returns true if the OneAgent DaemonSets of all node architectures have been rolled out.
+func isDaemonSetRolledOut(dsList []appsv1.DaemonSet) bool {
+	for _, ds := range dsList {
+		if ds.Status.ObservedGeneration < ds.Generation ||
+			ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled ||
+			ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+			return false
+		}
+	}
+	return true
+}
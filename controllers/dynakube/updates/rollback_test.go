@@ -0,0 +1,225 @@
+package updates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/logger"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileRollout(t *testing.T) {
+	const (
+		oldVersion = "1.213.0.20210301-123456"
+		newVersion = "1.215.0.20210415-123456"
+	)
+	started := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	podLabels := map[string]string{
+		"dynatrace.com/component":         "operator",
+		"operator.dynatrace.com/instance": testName,
+		"operator.dynatrace.com/feature":  "classic",
+	}
+	waitReadySeconds := uint16(60)
+
+	newReconciliation := func(now time.Time) *utils.Reconciliation {
+		dk := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{WaitReadySeconds: &waitReadySeconds},
+				},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					VersionStatus:           dynatracev1beta1.VersionStatus{Version: newVersion},
+					PreviousVersion:         oldVersion,
+					RolloutStartedTimestamp: &metav1.Time{Time: started},
+				},
+			},
+		}
+		return &utils.Reconciliation{Instance: &dk, Log: logger.NewDTLogger(), Now: metav1.NewTime(now)}
+	}
+	newDaemonSet := func(rolledOut bool) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: testName + "-classic", Namespace: testNamespace, Labels: podLabels},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{statefulset.AnnotationVersion: newVersion}},
+				},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 1, NumberReady: 1},
+		}
+		if rolledOut {
+			ds.Status.UpdatedNumberScheduled = 2
+			ds.Status.NumberReady = 2
+		}
+		return ds
+	}
+	newPod := func(version string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pod-" + version,
+				Namespace:         testNamespace,
+				Labels:            podLabels,
+				Annotations:       map[string]string{statefulset.AnnotationVersion: version},
+				CreationTimestamp: metav1.NewTime(started.Add(30 * time.Second)),
+			},
+			Spec: corev1.PodSpec{NodeName: "node-" + version},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	t.Run(`waits for pods to become ready`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(80 * time.Second))
+		fakeClient := fake.NewClient(newDaemonSet(false), newPod(newVersion, false), newPod(oldVersion, true))
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, upd)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.NotNil(t, rec.Instance.Status.OneAgent.RolloutStartedTimestamp)
+	})
+	t.Run(`rolls back if pods don't become ready in time`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(91 * time.Second))
+		recorder := record.NewFakeRecorder(10)
+		fakeClient := fake.NewClient(newDaemonSet(false), newPod(newVersion, false), newPod(oldVersion, true))
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, recorder, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Equal(t, []string{newVersion}, rec.Instance.Status.OneAgent.BlockedVersions)
+		assert.Empty(t, rec.Instance.Status.OneAgent.PreviousVersion)
+		assert.Nil(t, rec.Instance.Status.OneAgent.RolloutStartedTimestamp)
+		assert.Equal(t, "Warning VersionRollback OneAgent version "+newVersion+" didn't become ready on node node-"+newVersion+
+			" within 1m0s, rolling back to version "+oldVersion, <-recorder.Events)
+	})
+	t.Run(`pending pods don't cause a rollback`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(time.Hour))
+		unschedulable := newPod(newVersion, false)
+		unschedulable.Spec.NodeName = ""
+		unschedulable.Status.Phase = corev1.PodPending
+		imagePullFailed := newPod(newVersion, false)
+		imagePullFailed.Name = "pod-image-pull"
+		imagePullFailed.Status.Phase = corev1.PodPending
+		imagePullFailed.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+		}}
+		fakeClient := fake.NewClient(newDaemonSet(false), unschedulable, imagePullFailed)
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, upd)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Empty(t, rec.Instance.Status.OneAgent.BlockedVersions)
+	})
+	t.Run(`crashing pods cause a rollback`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(91 * time.Second))
+		crashing := newPod(newVersion, false)
+		crashing.Status.Phase = corev1.PodPending
+		crashing.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			RestartCount: 3,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}
+		fakeClient := fake.NewClient(newDaemonSet(false), crashing)
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		assert.True(t, rec.Instance.IsOneAgentVersionBlocked(newVersion))
+	})
+	t.Run(`canary rollout is stopped if pods don't become ready in time`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(91 * time.Second))
+		rec.Instance.Status.OneAgent.Version = oldVersion
+		rec.Instance.Status.OneAgent.Canary = &dynatracev1beta1.CanaryStatus{Version: newVersion}
+		fakeClient := fake.NewClient(newDaemonSet(false), newPod(newVersion, false))
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Nil(t, rec.Instance.Status.OneAgent.Canary)
+		assert.True(t, rec.Instance.IsOneAgentVersionBlocked(newVersion))
+	})
+	t.Run(`rollout finishes once all pods are ready`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(time.Hour))
+		fakeClient := fake.NewClient(newDaemonSet(true), newPod(newVersion, true))
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Empty(t, rec.Instance.Status.OneAgent.PreviousVersion)
+		assert.Nil(t, rec.Instance.Status.OneAgent.RolloutStartedTimestamp)
+	})
+	t.Run(`rollout finishes once daemonsets of all architectures are rolled out`, func(t *testing.T) {
+		rec := newReconciliation(started.Add(time.Hour))
+		armDaemonSet := newDaemonSet(false)
+		armDaemonSet.Name = testName + "-classic-arm64"
+		fakeClient := fake.NewClient(newDaemonSet(true), armDaemonSet, newPod(newVersion, true))
+
+		upd, err := reconcileRollout(context.TODO(), rec, fakeClient, record.NewFakeRecorder(10), rec.Instance)
+		require.NoError(t, err)
+		assert.False(t, upd)
+		assert.NotNil(t, rec.Instance.Status.OneAgent.RolloutStartedTimestamp)
+	})
+	t.Run(`blocked versions are not rolled out again`, func(t *testing.T) {
+		rec := newReconciliation(started)
+		rec.Instance.Status.OneAgent = dynatracev1beta1.OneAgentStatus{
+			VersionStatus:   dynatracev1beta1.VersionStatus{Version: oldVersion},
+			BlockedVersions: []string{newVersion},
+		}
+		rec.Instance.Status.LatestAgentVersionUnixDefault = newVersion
+
+		err := updateOneAgentInstallerVersion(context.TODO(), rec, nil, record.NewFakeRecorder(10), nil, rec.Instance)
+		require.NoError(t, err)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+	})
+	t.Run(`blocked versions are unblocked by annotation`, func(t *testing.T) {
+		rec := newReconciliation(started)
+		rec.Instance.Annotations = map[string]string{rec.Instance.GetFeatureUnblockOneAgentVersions(): newVersion}
+		rec.Instance.Status.OneAgent.BlockedVersions = []string{oldVersion, newVersion}
+		fakeClient := fake.NewClient(rec.Instance.DeepCopy())
+
+		upd, err := reconcileUnblockedVersions(context.TODO(), rec, fakeClient, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, []string{oldVersion}, rec.Instance.Status.OneAgent.BlockedVersions)
+		assert.NotContains(t, rec.Instance.Annotations, rec.Instance.GetFeatureUnblockOneAgentVersions())
+
+		var dk dynatracev1beta1.DynaKube
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dk))
+		assert.NotContains(t, dk.Annotations, rec.Instance.GetFeatureUnblockOneAgentVersions())
+	})
+	t.Run(`all blocked versions are unblocked by annotation`, func(t *testing.T) {
+		rec := newReconciliation(started)
+		rec.Instance.Annotations = map[string]string{rec.Instance.GetFeatureUnblockOneAgentVersions(): "all"}
+		rec.Instance.Status.OneAgent.BlockedVersions = []string{oldVersion, newVersion}
+		fakeClient := fake.NewClient(rec.Instance.DeepCopy())
+
+		upd, err := reconcileUnblockedVersions(context.TODO(), rec, fakeClient, rec.Instance)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Empty(t, rec.Instance.Status.OneAgent.BlockedVersions)
+	})
+}
@@ -2,19 +2,28 @@ package updates
 
 import (
 	"context"
+	"strconv"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube/updates/maintenance"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ProbeThreshold is the minimum time to wait between version upgrades.
 const ProbeThreshold = 15 * time.Minute
 
+const (
+	eventReasonVersionUpdate        = "VersionUpdate"
+	eventReasonVersionUpdatePending = "VersionUpdatePending"
+)
+
 // VersionProviderCallback fetches the version for a given image.
 type VersionProviderCallback func(string, *dtversion.DockerConfig) (dtversion.ImageVersion, error)
 
@@ -23,25 +32,60 @@ func ReconcileVersions(
 	ctx context.Context,
 	rec *utils.Reconciliation,
 	cl client.Client,
+	dtc dtclient.Client,
+	recorder record.EventRecorder,
 	verProvider VersionProviderCallback,
 ) (bool, error) {
 	upd := false
 	dk := rec.Instance
 
+	window, err := maintenance.NewWindow(dk.Spec.MaintenanceWindow)
+	if err != nil {
+		return false, err
+	}
+	inWindow := window.Contains(rec.Now.Time)
+	defer requeueForMaintenanceWindow(rec, window, dk)
+
+	if unblocked, err := reconcileUnblockedVersions(ctx, rec, cl, dk); err != nil {
+		return false, err
+	} else if unblocked {
+		upd = true
+	}
+
+	// Pending versions are applied as soon as a maintenance window starts, instead of waiting for the next probe.
 	needsOneAgentUpdate := dk.NeedsOneAgent() &&
-		rec.IsOutdated(dk.Status.OneAgent.LastUpdateProbeTimestamp, ProbeThreshold) &&
+		(rec.IsOutdated(dk.Status.OneAgent.LastUpdateProbeTimestamp, ProbeThreshold) || (inWindow && dk.Status.OneAgent.PendingVersion != "")) &&
 		dk.ShouldAutoUpdateOneAgent()
 
 	if needsOneAgentUpdate && !dk.NeedsImmutableOneAgent() {
 		upd = true
-		if err := updateOneAgentInstallerVersion(rec, dk); err != nil {
+		if err := updateOneAgentInstallerVersion(ctx, rec, dtc, recorder, window, dk); err != nil {
 			rec.Log.Error(err, "Failed to fetch OneAgent installer version")
 		}
 	}
 
+	if dk.NeedsOneAgent() && !dk.NeedsImmutableOneAgent() {
+		if updRollout, err := reconcileRollout(ctx, rec, cl, recorder, dk); err != nil {
+			return upd, err
+		} else if updRollout {
+			upd = true
+		}
+	}
+
+	if dk.Status.OneAgent.Canary != nil {
+		if !dk.NeedsOneAgent() || dk.NeedsImmutableOneAgent() {
+			dk.Status.OneAgent.Canary = nil
+			upd = true
+		} else if promoted, err := reconcileCanary(ctx, rec, cl, recorder, window, dk); err != nil {
+			return upd, err
+		} else if promoted {
+			upd = true
+		}
+	}
+
 	needsActiveGateUpdate := dk.NeedsActiveGate() &&
 		!dk.FeatureDisableActiveGateUpdates() &&
-		rec.IsOutdated(dk.Status.ActiveGate.LastUpdateProbeTimestamp, ProbeThreshold)
+		(rec.IsOutdated(dk.Status.ActiveGate.LastUpdateProbeTimestamp, ProbeThreshold) || (inWindow && dk.Status.ActiveGate.PendingVersion != ""))
 
 	needsImmutableOneAgentUpdate := dk.NeedsImmutableOneAgent() && needsOneAgentUpdate
 
@@ -63,13 +107,13 @@ func ReconcileVersions(
 	upd = true // updateImageVersion() always updates the status
 
 	if needsActiveGateUpdate {
-		if err := updateImageVersion(rec, dk.ActiveGateImage(), &dk.Status.ActiveGate.VersionStatus, &dockerCfg, verProvider, true); err != nil {
+		if err := updateImageVersion(rec, recorder, window, "ActiveGate", dk.ActiveGateImage(), &dk.Status.ActiveGate.VersionStatus, &dockerCfg, verProvider, true); err != nil {
 			rec.Log.Error(err, "Failed to update ActiveGate image version")
 		}
 	}
 
 	if needsImmutableOneAgentUpdate {
-		if err := updateImageVersion(rec, dk.ImmutableOneAgentImage(), &dk.Status.OneAgent.VersionStatus, &dockerCfg, verProvider, false); err != nil {
+		if err := updateImageVersion(rec, recorder, window, "OneAgent", dk.ImmutableOneAgentImage(), &dk.Status.OneAgent.VersionStatus, &dockerCfg, verProvider, dk.FeatureAllowOneAgentDowngrades()); err != nil {
 			rec.Log.Error(err, "Failed to update OneAgent image version")
 		}
 	}
@@ -79,8 +123,11 @@ func ReconcileVersions(
 
 func updateImageVersion(
 	rec *utils.Reconciliation,
+	recorder record.EventRecorder,
+	window *maintenance.Window,
+	component string,
 	img string,
-	target *dynatracev1alpha1.VersionStatus,
+	target *dynatracev1beta1.VersionStatus,
 	dockerCfg *dtversion.DockerConfig,
 	verProvider VersionProviderCallback,
 	allowDowngrades bool,
@@ -93,6 +140,7 @@ func updateImageVersion(
 	}
 
 	if target.Version == ver.Version {
+		target.PendingVersion = ""
 		return nil
 	}
 
@@ -104,27 +152,59 @@ func updateImageVersion(
 		}
 	}
 
+	if target.Version != "" && postponeUpdate(rec, recorder, window, component, target, ver.Version) {
+		return nil
+	}
+
 	rec.Log.Info("Update found",
 		"image", img,
 		"oldVersion", target.Version, "newVersion", ver.Version,
 		"oldHash", target.ImageHash, "newHash", ver.Hash)
+	recorder.Eventf(rec.Instance, corev1.EventTypeNormal, eventReasonVersionUpdate, "Updating image %s to version %s", img, ver.Version)
 
 	target.Version = ver.Version
 	target.ImageHash = ver.Hash
 	return nil
 }
 
-func updateOneAgentInstallerVersion(rec *utils.Reconciliation, dk *dynatracev1alpha1.DynaKube) error {
+func updateOneAgentInstallerVersion(
+	ctx context.Context,
+	rec *utils.Reconciliation,
+	dtc dtclient.Client,
+	recorder record.EventRecorder,
+	window *maintenance.Window,
+	dk *dynatracev1beta1.DynaKube,
+) error {
 	dk.Status.OneAgent.LastUpdateProbeTimestamp = rec.Now.DeepCopy()
-	ver := dk.Status.LatestAgentVersionUnixDefault
+	ver, err := selectOneAgentVersion(ctx, dtc, dk, rec.Now.Time)
+	if err != nil {
+		return err
+	}
 
 	oldVer := dk.Status.OneAgent.Version
+	canary := dk.Status.OneAgent.Canary
 
 	if oldVer == ver {
+		dk.Status.OneAgent.PendingVersion = ""
+		if canary != nil {
+			rec.Log.Info("OneAgent version no longer selected by version policy, stopping canary rollout", "version", canary.Version)
+			dk.Status.OneAgent.Canary = nil
+			finishRollout(dk)
+		}
 		return nil
 	}
 
-	if oldVer != "" {
+	if canary != nil && canary.Version == ver {
+		dk.Status.OneAgent.PendingVersion = ""
+		return nil
+	}
+
+	if dk.IsOneAgentVersionBlocked(ver) {
+		rec.Log.Info("OneAgent version has been rolled back before, not updating", "version", ver)
+		return nil
+	}
+
+	if oldVer != "" && !dk.FeatureAllowOneAgentDowngrades() {
 		if upgrade, err := dtversion.NeedsUpgradeRaw(oldVer, ver); err != nil {
 			return err
 		} else if !upgrade {
@@ -132,7 +212,137 @@ func updateOneAgentInstallerVersion(rec *utils.Reconciliation, dk *dynatracev1al
 		}
 	}
 
+	if oldVer != "" && postponeUpdate(rec, recorder, window, "OneAgent", &dk.Status.OneAgent.VersionStatus, ver) {
+		return nil
+	}
+
+	if policy := dk.OneAgentVersionPolicy(); policy != nil && policy.Canary != nil && oldVer != "" {
+		rec.Log.Info("OneAgent update found, rolling out to canary nodes", "oldVersion", oldVer, "newVersion", ver)
+		recorder.Eventf(dk, corev1.EventTypeNormal, eventReasonVersionUpdate, "Updating OneAgent on canary nodes to version %s", ver)
+		dk.Status.OneAgent.Canary = &dynatracev1beta1.CanaryStatus{Version: ver, StartedTimestamp: rec.Now}
+		startRollout(rec, dk, oldVer)
+		return nil
+	}
+
 	rec.Log.Info("OneAgent update found", "oldVersion", oldVer, "newVersion", ver)
+	recorder.Eventf(dk, corev1.EventTypeNormal, eventReasonVersionUpdate, "Updating OneAgent to version %s", ver)
+	if oldVer != "" {
+		startRollout(rec, dk, oldVer)
+	}
 	dk.Status.OneAgent.Version = ver
+	dk.Status.OneAgent.Canary = nil
 	return nil
 }
+
+// requeueForMaintenanceWindow makes sure that the DynaKube is reconciled again at the start of the next maintenance
+// window while updates are waiting for it, as the window could be over before the next regular reconciliation.
+func requeueForMaintenanceWindow(rec *utils.Reconciliation, window *maintenance.Window, dk *dynatracev1beta1.DynaKube) {
+	if dk.Status.OneAgent.PendingVersion == "" && dk.Status.ActiveGate.PendingVersion == "" && dk.Status.OneAgent.Canary == nil {
+		return
+	}
+
+	if next := window.Next(rec.Now.Time); !next.IsZero() {
+		rec.RequeueBefore(next.Sub(rec.Now.Time))
+	}
+}
+
+// postponeUpdate records ver as pending if the update has to wait for the next maintenance window, and returns true if
+// so. Otherwise, the pending version is reset as the update is applied.
+func postponeUpdate(rec *utils.Reconciliation, recorder record.EventRecorder, window *maintenance.Window, component string, target *dynatracev1beta1.VersionStatus, ver string) bool {
+	if window.Contains(rec.Now.Time) {
+		target.PendingVersion = ""
+		return false
+	}
+
+	if target.PendingVersion != ver {
+		next := window.Next(rec.Now.Time)
+		rec.Log.Info("Update found outside of maintenance window", "component", component, "version", ver, "nextMaintenanceWindow", next)
+		recorder.Eventf(rec.Instance, corev1.EventTypeNormal, eventReasonVersionUpdatePending,
+			"Updating %s to version %s in the maintenance window starting at %s", component, ver, next.Format(time.RFC3339))
+		target.PendingVersion = ver
+	}
+	return true
+}
+
+// selectOneAgentVersion returns the OneAgent version to be rolled out according to the version policy of the DynaKube.
+// Without a version policy, the latest version is used.
+func selectOneAgentVersion(ctx context.Context, dtc dtclient.Client, dk *dynatracev1beta1.DynaKube, now time.Time) (string, error) {
+	policy := dk.OneAgentVersionPolicy()
+	if policy == nil {
+		return dk.Status.LatestAgentVersionUnixDefault, nil
+	}
+
+	releasedBefore := now.AddDate(0, 0, -int(policy.DelayDays))
+
+	switch policy.Type {
+	case dynatracev1beta1.VersionPolicyPinned:
+		pinned, err := dtversion.ParseVersion(policy.Version)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid version for Pinned version policy")
+		}
+		if _, err := pinned.BuildTime(); err == nil {
+			return policy.Version, nil
+		}
+
+		// Short versions like 1.213 are pinned to the latest build starting with them.
+		versionRange, err := dtversion.ParseRange(policy.Version)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid version for Pinned version policy")
+		}
+		return latestAvailableOneAgentVersion(ctx, dtc, dk, now, versionRange.Contains)
+	case dynatracev1beta1.VersionPolicyLatestMinor:
+		major, err := strconv.Atoi(policy.Version)
+		if err != nil {
+			return "", errors.Errorf("invalid major version for LatestMinor version policy: '%s'", policy.Version)
+		}
+		return latestAvailableOneAgentVersion(ctx, dtc, dk, releasedBefore, func(v dtversion.Version) bool {
+			return v.Major() == major
+		})
+	default:
+		if policy.Version == "" {
+			if policy.DelayDays == 0 {
+				return dk.Status.LatestAgentVersionUnixDefault, nil
+			}
+			return latestAvailableOneAgentVersion(ctx, dtc, dk, releasedBefore, func(dtversion.Version) bool {
+				return true
+			})
+		}
+
+		versionRange, err := dtversion.ParseRange(policy.Version)
+		if err != nil {
+			return "", errors.WithMessage(err, "invalid version range for Latest version policy")
+		}
+		return latestAvailableOneAgentVersion(ctx, dtc, dk, releasedBefore, versionRange.Contains)
+	}
+}
+
+// latestAvailableOneAgentVersion returns the newest OneAgent version available for the environment which matches, has
+// been built before releasedBefore and hasn't been blocked.
+func latestAvailableOneAgentVersion(ctx context.Context, dtc dtclient.Client, dk *dynatracev1beta1.DynaKube, releasedBefore time.Time, matches func(dtversion.Version) bool) (string, error) {
+	versions, err := dtc.GetAgentVersions(ctx, dtclient.OsUnix, dtclient.InstallerTypeDefault)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to get available OneAgent versions")
+	}
+
+	var latest string
+	var latestInfo dtversion.Version
+	for _, ver := range versions {
+		info, err := dtversion.ParseVersion(ver)
+		if err != nil || !matches(info) || dk.IsOneAgentVersionBlocked(ver) {
+			continue
+		}
+
+		if buildTime, err := info.BuildTime(); err != nil || buildTime.After(releasedBefore) {
+			continue
+		}
+
+		if latest == "" || dtversion.CompareVersions(info, latestInfo) > 0 {
+			latest, latestInfo = ver, info
+		}
+	}
+
+	if latest == "" {
+		return "", errors.New("no available OneAgent version matches the version policy")
+	}
+	return latest, nil
+}
@@ -21,17 +21,20 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtpullsecret"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtversion"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/logger"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -47,13 +50,17 @@ const (
 func TestReconcile_UpdateImageVersion(t *testing.T) {
 	ctx := context.Background()
 
-	dk := dynatracev1alpha1.DynaKube{
+	dk := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			KubernetesMonitoringSpec: dynatracev1alpha1.KubernetesMonitoringSpec{
-				CapabilityProperties: dynatracev1alpha1.CapabilityProperties{Enabled: true},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			KubernetesMonitoringSpec: dynatracev1beta1.KubernetesMonitoringSpec{
+				CapabilityProperties: dynatracev1beta1.CapabilityProperties{Enabled: true},
+			},
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					UseImmutableImage: true,
+				},
 			},
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{Enabled: true, UseImmutableImage: true},
 		},
 	}
 
@@ -61,12 +68,13 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 
 	now := metav1.Now()
 	rec := &utils.Reconciliation{Instance: &dk, Log: logger.NewDTLogger(), Now: now}
+	recorder := record.NewFakeRecorder(10)
 
 	errVerProvider := func(img string, dockerConfig *dtversion.DockerConfig) (dtversion.ImageVersion, error) {
 		return dtversion.ImageVersion{}, errors.New("Not implemented")
 	}
 
-	upd, err := ReconcileVersions(ctx, rec, fakeClient, errVerProvider)
+	upd, err := ReconcileVersions(ctx, rec, fakeClient, &dtclient.MockDynatraceClient{}, recorder, errVerProvider)
 	assert.Error(t, err)
 	assert.False(t, upd)
 
@@ -80,10 +88,13 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 		return dtversion.ImageVersion{Version: testVersion, Hash: testHash}, nil
 	}
 
-	upd, err = ReconcileVersions(ctx, rec, fakeClient, sampleVerProvider)
+	upd, err = ReconcileVersions(ctx, rec, fakeClient, &dtclient.MockDynatraceClient{}, recorder, sampleVerProvider)
 	assert.NoError(t, err)
 	assert.True(t, upd)
 
+	assert.Len(t, recorder.Events, 2)
+	assert.Contains(t, <-recorder.Events, "Normal VersionUpdate Updating image")
+
 	assert.Equal(t, testVersion, rec.Instance.Status.ActiveGate.Version)
 	assert.Equal(t, testHash, rec.Instance.Status.ActiveGate.ImageHash)
 	if ts := rec.Instance.Status.ActiveGate.LastUpdateProbeTimestamp; assert.NotNil(t, ts) {
@@ -96,11 +107,233 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 		assert.Equal(t, now, *ts)
 	}
 
-	upd, err = ReconcileVersions(ctx, rec, fakeClient, sampleVerProvider)
+	upd, err = ReconcileVersions(ctx, rec, fakeClient, &dtclient.MockDynatraceClient{}, recorder, sampleVerProvider)
 	assert.NoError(t, err)
 	assert.False(t, upd)
 }
 
+func TestSelectOneAgentVersion(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	dtc := &dtclient.MockDynatraceClient{}
+	dtc.On("GetAgentVersions", dtclient.OsUnix, dtclient.InstallerTypeDefault).Return([]string{
+		"1.213.0.20210301-123456",
+		"1.215.0.20210415-123456",
+		"1.217.0.20210428-123456",
+		"2.1.0.20210429-123456",
+		"invalid",
+	}, nil)
+
+	newDynaKube := func(policy *dynatracev1beta1.VersionPolicySpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{VersionPolicy: policy},
+				},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{LatestAgentVersionUnixDefault: "2.1.0.20210429-123456"},
+		}
+	}
+
+	t.Run(`latest version without version policy`, func(t *testing.T) {
+		ver, err := selectOneAgentVersion(context.TODO(), dtc, newDynaKube(nil), now)
+		require.NoError(t, err)
+		assert.Equal(t, "2.1.0.20210429-123456", ver)
+	})
+	t.Run(`pinned version`, func(t *testing.T) {
+		ver, err := selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyPinned,
+			Version: "1.213.0.20210301-123456",
+		}), now)
+		require.NoError(t, err)
+		assert.Equal(t, "1.213.0.20210301-123456", ver)
+
+		for _, short := range []string{"1.213", "1.213.0"} {
+			ver, err = selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+				Type:    dynatracev1beta1.VersionPolicyPinned,
+				Version: short,
+			}), now)
+			require.NoError(t, err, short)
+			assert.Equal(t, "1.213.0.20210301-123456", ver, short)
+		}
+
+		_, err = selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyPinned,
+			Version: "1.213.x",
+		}), now)
+		assert.Error(t, err)
+	})
+	t.Run(`latest minor of major version`, func(t *testing.T) {
+		ver, err := selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyLatestMinor,
+			Version: "1",
+		}), now)
+		require.NoError(t, err)
+		assert.Equal(t, "1.217.0.20210428-123456", ver)
+
+		_, err = selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyLatestMinor,
+			Version: "3",
+		}), now)
+		assert.Error(t, err)
+	})
+	t.Run(`latest version in version range`, func(t *testing.T) {
+		ver, err := selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyLatest,
+			Version: ">=1.213 <1.217",
+		}), now)
+		require.NoError(t, err)
+		assert.Equal(t, "1.215.0.20210415-123456", ver)
+
+		_, err = selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyLatest,
+			Version: ">=1.213 <",
+		}), now)
+		assert.Error(t, err)
+	})
+	t.Run(`latest version released before delay`, func(t *testing.T) {
+		ver, err := selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:      dynatracev1beta1.VersionPolicyLatest,
+			DelayDays: 7,
+		}), now)
+		require.NoError(t, err)
+		assert.Equal(t, "1.215.0.20210415-123456", ver)
+
+		ver, err = selectOneAgentVersion(context.TODO(), dtc, newDynaKube(&dynatracev1beta1.VersionPolicySpec{
+			Type:      dynatracev1beta1.VersionPolicyLatestMinor,
+			Version:   "1",
+			DelayDays: 1,
+		}), now)
+		require.NoError(t, err)
+		assert.Equal(t, "1.217.0.20210428-123456", ver)
+	})
+}
+
+func TestReconcile_OneAgentInstallerVersion(t *testing.T) {
+	const (
+		oldVersion = "1.213.0.20210301-123456"
+		newVersion = "1.215.0.20210415-123456"
+	)
+
+	newReconciliation := func(policy *dynatracev1beta1.VersionPolicySpec) *utils.Reconciliation {
+		dk := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{VersionPolicy: policy},
+				},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				LatestAgentVersionUnixDefault: newVersion,
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					VersionStatus: dynatracev1beta1.VersionStatus{Version: oldVersion},
+				},
+			},
+		}
+		return utils.NewReconciliation(logger.NewDTLogger(), &dk)
+	}
+
+	t.Run(`new version is rolled out to all nodes`, func(t *testing.T) {
+		rec := newReconciliation(nil)
+		recorder := record.NewFakeRecorder(10)
+
+		upd, err := ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, recorder, nil)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.PreviousVersion)
+		assert.NotNil(t, rec.Instance.Status.OneAgent.RolloutStartedTimestamp)
+		assert.Nil(t, rec.Instance.Status.OneAgent.Canary)
+		assert.Equal(t, "Normal VersionUpdate Updating OneAgent to version "+newVersion, <-recorder.Events)
+	})
+	t.Run(`new version is rolled out to canary nodes first`, func(t *testing.T) {
+		rec := newReconciliation(&dynatracev1beta1.VersionPolicySpec{
+			Canary: &dynatracev1beta1.CanarySpec{NodeSelector: map[string]string{"canary": "true"}},
+		})
+		recorder := record.NewFakeRecorder(10)
+
+		upd, err := ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, recorder, nil)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		if canary := rec.Instance.Status.OneAgent.Canary; assert.NotNil(t, canary) {
+			assert.Equal(t, newVersion, canary.Version)
+			assert.Equal(t, rec.Now, canary.StartedTimestamp)
+		}
+		assert.Equal(t, "Normal VersionUpdate Updating OneAgent on canary nodes to version "+newVersion, <-recorder.Events)
+	})
+	t.Run(`new version is pending outside of maintenance window`, func(t *testing.T) {
+		rec := newReconciliation(nil)
+		rec.Instance.Spec.MaintenanceWindow = &dynatracev1beta1.MaintenanceWindowSpec{
+			Schedule: "0 2 * * *",
+			Duration: metav1.Duration{Duration: time.Hour},
+		}
+		rec.Now = metav1.NewTime(time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC))
+		recorder := record.NewFakeRecorder(10)
+
+		upd, err := ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, recorder, nil)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.PendingVersion)
+		assert.Equal(t, "Normal VersionUpdatePending Updating OneAgent to version "+newVersion+
+			" in the maintenance window starting at 2021-05-02T02:00:00Z", <-recorder.Events)
+
+		rec.Now = metav1.NewTime(time.Date(2021, 5, 1, 12, 5, 0, 0, time.UTC))
+		upd, err = ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, recorder, nil)
+		require.NoError(t, err)
+		assert.False(t, upd, "pending versions are only probed again after the probe threshold")
+		assert.Equal(t, 30*time.Minute, rec.RequeueAfter)
+
+		rec.Now = metav1.NewTime(time.Date(2021, 5, 2, 1, 50, 0, 0, time.UTC))
+		_, err = ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, recorder, nil)
+		require.NoError(t, err)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.PendingVersion)
+		assert.Equal(t, 10*time.Minute, rec.RequeueAfter, "requeued at the start of the maintenance window")
+
+		rec.Now = metav1.NewTime(time.Date(2021, 5, 2, 2, 5, 0, 0, time.UTC))
+		upd, err = ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, recorder, nil)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Empty(t, rec.Instance.Status.OneAgent.PendingVersion)
+		assert.Equal(t, "Normal VersionUpdate Updating OneAgent to version "+newVersion, <-recorder.Events)
+	})
+	t.Run(`canary rollout is stopped when version is no longer selected`, func(t *testing.T) {
+		rec := newReconciliation(&dynatracev1beta1.VersionPolicySpec{
+			Type:    dynatracev1beta1.VersionPolicyPinned,
+			Version: oldVersion,
+			Canary:  &dynatracev1beta1.CanarySpec{NodeSelector: map[string]string{"canary": "true"}},
+		})
+		rec.Instance.Status.OneAgent.Canary = &dynatracev1beta1.CanaryStatus{Version: newVersion}
+
+		_, err := ReconcileVersions(context.TODO(), rec, fake.NewClient(), &dtclient.MockDynatraceClient{}, record.NewFakeRecorder(10), nil)
+		require.NoError(t, err)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Nil(t, rec.Instance.Status.OneAgent.Canary)
+	})
+	t.Run(`downgrades are refused by default`, func(t *testing.T) {
+		rec := newReconciliation(&dynatracev1beta1.VersionPolicySpec{Type: dynatracev1beta1.VersionPolicyPinned, Version: oldVersion})
+		rec.Instance.Status.OneAgent.Version = newVersion
+
+		err := updateOneAgentInstallerVersion(context.TODO(), rec, nil, record.NewFakeRecorder(10), nil, rec.Instance)
+		assert.Error(t, err)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.Version)
+	})
+	t.Run(`downgrades are rolled out if allowed`, func(t *testing.T) {
+		rec := newReconciliation(&dynatracev1beta1.VersionPolicySpec{Type: dynatracev1beta1.VersionPolicyPinned, Version: oldVersion})
+		rec.Instance.Annotations = map[string]string{"alpha.operator.dynatrace.com/feature-allow-oneagent-downgrades": "true"}
+		rec.Instance.Status.OneAgent.Version = newVersion
+		recorder := record.NewFakeRecorder(10)
+
+		err := updateOneAgentInstallerVersion(context.TODO(), rec, nil, recorder, nil, rec.Instance)
+		require.NoError(t, err)
+		assert.Equal(t, oldVersion, rec.Instance.Status.OneAgent.Version)
+		assert.Equal(t, newVersion, rec.Instance.Status.OneAgent.PreviousVersion)
+		assert.Equal(t, "Normal VersionUpdate Updating OneAgent to version "+oldVersion, <-recorder.Events)
+	})
+}
+
 // Adding *testing.T parameter to prevent usage in production code
 func createTestPullSecret(_ *testing.T, clt client.Client, rec *utils.Reconciliation, data []byte) error {
 	return clt.Create(context.TODO(), &corev1.Secret{
@@ -4,17 +4,19 @@ import (
 	"context"
 	"fmt"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/go-logr/logr"
 	istiov1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	istioclientset "istio.io/client-go/pkg/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -30,21 +32,29 @@ const (
 	probeUnknown
 )
 
+const (
+	eventReasonIstioObjectCreated = "IstioObjectCreated"
+	eventReasonIstioObjectDeleted = "IstioObjectDeleted"
+	eventReasonIstioCRDsNotFound  = "IstioCRDsNotFound"
+)
+
 // Controller - manager istioclientset and config
 type Controller struct {
 	istioClient istioclientset.Interface
 	scheme      *runtime.Scheme
+	recorder    record.EventRecorder
 
 	logger logr.Logger
 	config *rest.Config
 }
 
 // NewController - creates new instance of istio controller
-func NewController(config *rest.Config, scheme *runtime.Scheme) *Controller {
+func NewController(config *rest.Config, scheme *runtime.Scheme, recorder record.EventRecorder) *Controller {
 	c := &Controller{
-		config: config,
-		scheme: scheme,
-		logger: log.Log.WithName("istio.controller"),
+		config:   config,
+		scheme:   scheme,
+		recorder: recorder,
+		logger:   log.Log.WithName("istio.controller"),
 	}
 	istioClient, err := c.initialiseIstioClient(config)
 	if err != nil {
@@ -66,7 +76,7 @@ func (c *Controller) initialiseIstioClient(config *rest.Config) (istioclientset.
 
 // ReconcileIstio - runs the istio's reconcile workflow,
 // creating/deleting VS & SE for external communications
-func (c *Controller) ReconcileIstio(instance *dynatracev1alpha1.DynaKube) (updated bool, err error) {
+func (c *Controller) ReconcileIstio(instance *dynatracev1beta1.DynaKube) (updated bool, err error) {
 
 	enabled, err := CheckIstioEnabled(c.config)
 	if err != nil {
@@ -96,7 +106,7 @@ func (c *Controller) ReconcileIstio(instance *dynatracev1alpha1.DynaKube) (updat
 	return false, nil
 }
 
-func (c *Controller) reconcileIstioConfigurations(instance *dynatracev1alpha1.DynaKube,
+func (c *Controller) reconcileIstioConfigurations(instance *dynatracev1beta1.DynaKube,
 	comHosts []dtclient.CommunicationHost, role string) (bool, error) {
 
 	add, err := c.reconcileIstioCreateConfigurations(instance, comHosts, role)
@@ -111,7 +121,7 @@ func (c *Controller) reconcileIstioConfigurations(instance *dynatracev1alpha1.Dy
 	return add || rem, nil
 }
 
-func (c *Controller) reconcileIstioRemoveConfigurations(instance *dynatracev1alpha1.DynaKube,
+func (c *Controller) reconcileIstioRemoveConfigurations(instance *dynatracev1beta1.DynaKube,
 	comHosts []dtclient.CommunicationHost, role string) (bool, error) {
 
 	labelSelector := labels.SelectorFromSet(buildIstioLabels(instance.GetName(), role)).String()
@@ -124,11 +134,11 @@ func (c *Controller) reconcileIstioRemoveConfigurations(instance *dynatracev1alp
 		seen[buildNameForEndpoint(instance.GetName(), ch.Protocol, ch.Host, ch.Port)] = true
 	}
 
-	vsUpd, err := c.removeIstioConfigurationForVirtualService(listOps, seen, instance.GetNamespace())
+	vsUpd, err := c.removeIstioConfigurationForVirtualService(instance, listOps, seen)
 	if err != nil {
 		return false, err
 	}
-	seUpd, err := c.removeIstioConfigurationForServiceEntry(listOps, seen, instance.GetNamespace())
+	seUpd, err := c.removeIstioConfigurationForServiceEntry(instance, listOps, seen)
 	if err != nil {
 		return false, err
 	}
@@ -136,9 +146,10 @@ func (c *Controller) reconcileIstioRemoveConfigurations(instance *dynatracev1alp
 	return vsUpd || seUpd, nil
 }
 
-func (c *Controller) removeIstioConfigurationForServiceEntry(listOps *metav1.ListOptions,
-	seen map[string]bool, namespace string) (bool, error) {
+func (c *Controller) removeIstioConfigurationForServiceEntry(instance *dynatracev1beta1.DynaKube, listOps *metav1.ListOptions,
+	seen map[string]bool) (bool, error) {
 
+	namespace := instance.GetNamespace()
 	list, err := c.istioClient.NetworkingV1alpha3().ServiceEntries(namespace).List(context.TODO(), *listOps)
 	if err != nil {
 		c.logger.Error(err, fmt.Sprintf("istio: error listing service entries, %v", err))
@@ -156,6 +167,7 @@ func (c *Controller) removeIstioConfigurationForServiceEntry(listOps *metav1.Lis
 				c.logger.Error(err, fmt.Sprintf("istio: error deleting service entry, %s : %v", se.GetName(), err))
 				continue
 			}
+			c.recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonIstioObjectDeleted, "Deleted ServiceEntry %s", se.GetName())
 			del = true
 		}
 	}
@@ -163,9 +175,10 @@ func (c *Controller) removeIstioConfigurationForServiceEntry(listOps *metav1.Lis
 	return del, nil
 }
 
-func (c *Controller) removeIstioConfigurationForVirtualService(listOps *metav1.ListOptions,
-	seen map[string]bool, namespace string) (bool, error) {
+func (c *Controller) removeIstioConfigurationForVirtualService(instance *dynatracev1beta1.DynaKube, listOps *metav1.ListOptions,
+	seen map[string]bool) (bool, error) {
 
+	namespace := instance.GetNamespace()
 	list, err := c.istioClient.NetworkingV1alpha3().VirtualServices(namespace).List(context.TODO(), *listOps)
 	if err != nil {
 		c.logger.Error(err, fmt.Sprintf("istio: error listing virtual service, %v", err))
@@ -183,6 +196,7 @@ func (c *Controller) removeIstioConfigurationForVirtualService(listOps *metav1.L
 				c.logger.Error(err, fmt.Sprintf("istio: error deleting virtual service, %s : %v", vs.GetName(), err))
 				continue
 			}
+			c.recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonIstioObjectDeleted, "Deleted VirtualService %s", vs.GetName())
 			del = true
 		}
 	}
@@ -190,12 +204,14 @@ func (c *Controller) removeIstioConfigurationForVirtualService(listOps *metav1.L
 	return del, nil
 }
 
-func (c *Controller) reconcileIstioCreateConfigurations(instance *dynatracev1alpha1.DynaKube,
+func (c *Controller) reconcileIstioCreateConfigurations(instance *dynatracev1beta1.DynaKube,
 	communicationHosts []dtclient.CommunicationHost, role string) (bool, error) {
 
 	crdProbe := c.verifyIstioCrdAvailability(instance)
 	if crdProbe != probeTypeFound {
 		c.logger.Info("istio: failed to lookup CRD for ServiceEntry/VirtualService: Did you install Istio recently? Please restart the Operator.")
+		c.recorder.Event(instance, corev1.EventTypeWarning, eventReasonIstioCRDsNotFound,
+			"CRDs for ServiceEntry/VirtualService not found. If Istio was installed recently, restart the Operator.")
 		return false, nil
 	}
 
@@ -218,7 +234,7 @@ func (c *Controller) reconcileIstioCreateConfigurations(instance *dynatracev1alp
 	return configurationUpdated, nil
 }
 
-func (c *Controller) verifyIstioCrdAvailability(instance *dynatracev1alpha1.DynaKube) probeResult {
+func (c *Controller) verifyIstioCrdAvailability(instance *dynatracev1beta1.DynaKube) probeResult {
 	var probe probeResult
 
 	probe, _ = c.kubernetesObjectProbe(ServiceEntryGVK, instance.GetNamespace(), "")
@@ -234,7 +250,7 @@ func (c *Controller) verifyIstioCrdAvailability(instance *dynatracev1alpha1.Dyna
 	return probeTypeFound
 }
 
-func (c *Controller) handleIstioConfigurationForVirtualService(instance *dynatracev1alpha1.DynaKube,
+func (c *Controller) handleIstioConfigurationForVirtualService(instance *dynatracev1beta1.DynaKube,
 	name string, communicationHost dtclient.CommunicationHost, role string) (bool, error) {
 
 	probe, err := c.kubernetesObjectProbe(VirtualServiceGVK, instance.GetNamespace(), name)
@@ -258,11 +274,12 @@ func (c *Controller) handleIstioConfigurationForVirtualService(instance *dynatra
 	}
 	c.logger.Info("istio: VirtualService created", "objectName", name, "host", communicationHost.Host,
 		"port", communicationHost.Port, "protocol", communicationHost.Protocol)
+	c.recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonIstioObjectCreated, "Created VirtualService %s for %s", name, communicationHost.Host)
 
 	return true, nil
 }
 
-func (c *Controller) handleIstioConfigurationForServiceEntry(instance *dynatracev1alpha1.DynaKube,
+func (c *Controller) handleIstioConfigurationForServiceEntry(instance *dynatracev1beta1.DynaKube,
 	name string, communicationHost dtclient.CommunicationHost, role string) (bool, error) {
 
 	probe, err := c.kubernetesObjectProbe(ServiceEntryGVK, instance.GetNamespace(), name)
@@ -280,11 +297,12 @@ func (c *Controller) handleIstioConfigurationForServiceEntry(instance *dynatrace
 		return false, err
 	}
 	c.logger.Info("istio: ServiceEntry created", "objectName", name, "host", communicationHost.Host, "port", communicationHost.Port)
+	c.recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonIstioObjectCreated, "Created ServiceEntry %s for %s", name, communicationHost.Host)
 
 	return true, nil
 }
 
-func (c *Controller) createIstioConfigurationForServiceEntry(dynaKube *dynatracev1alpha1.DynaKube,
+func (c *Controller) createIstioConfigurationForServiceEntry(dynaKube *dynatracev1beta1.DynaKube,
 	serviceEntry *istiov1alpha3.ServiceEntry, role string) error {
 
 	serviceEntry.Labels = buildIstioLabels(dynaKube.GetName(), role)
@@ -302,7 +320,7 @@ func (c *Controller) createIstioConfigurationForServiceEntry(dynaKube *dynatrace
 	return nil
 }
 
-func (c *Controller) createIstioConfigurationForVirtualService(dynaKube *dynatracev1alpha1.DynaKube,
+func (c *Controller) createIstioConfigurationForVirtualService(dynaKube *dynatracev1beta1.DynaKube,
 	virtualService *istiov1alpha3.VirtualService, role string) error {
 
 	virtualService.Labels = buildIstioLabels(dynaKube.GetName(), role)
@@ -11,7 +11,7 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	"github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/logger"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
 	"github.com/stretchr/testify/assert"
@@ -20,6 +20,7 @@ import (
 	fakeistio "istio.io/client-go/pkg/clientset/versioned/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -81,10 +82,11 @@ func TestController_ReconcileIstio(t *testing.T) {
 	require.NoError(t, err)
 
 	virtualService := buildVirtualService(testVirtualServiceName, "localhost", serverUrl.Scheme, uint32(port))
-	instance := &v1alpha1.DynaKube{}
+	instance := &v1beta1.DynaKube{}
 	controller := Controller{
 		istioClient: fakeistio.NewSimpleClientset(virtualService),
 		scheme:      scheme.Scheme,
+		recorder:    &record.FakeRecorder{},
 		logger:      logger.NewDTLogger(),
 		config: &rest.Config{
 			Host:    server.URL,
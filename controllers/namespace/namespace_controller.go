@@ -8,7 +8,7 @@ import (
 	"text/template"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
 	"github.com/Dynatrace/dynatrace-operator/webhook"
 	"github.com/go-logr/logr"
@@ -93,25 +93,25 @@ func (r *ReconcileNamespaces) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, nil
 	}
 
-	var dk dynatracev1alpha1.DynaKube
+	var dk dynatracev1beta1.DynaKube
 	if err := r.client.Get(ctx, client.ObjectKey{Name: oaName, Namespace: r.namespace}, &dk); err != nil {
 		return reconcile.Result{}, errors.WithMessage(err, "failed to query DynaKubes")
 	}
 
 	tokenName := dk.Tokens()
-	if !dk.Spec.CodeModules.Enabled {
+	if !dk.NeedsAppInjection() {
 		_ = r.ensureSecretDeleted(tokenName, targetNS)
 		return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 	}
 
-	var ims dynatracev1alpha1.DynaKubeList
+	var ims dynatracev1beta1.DynaKubeList
 	if err := r.client.List(ctx, &ims, client.InNamespace(r.namespace)); err != nil {
 		return reconcile.Result{}, errors.WithMessage(err, "failed to query DynaKubeList")
 	}
 
 	imNodes := map[string]string{}
 	for i := range ims.Items {
-		if s := &ims.Items[i].Status; s.ConnectionInfo.TenantUUID != "" && ims.Items[i].Spec.InfraMonitoring.Enabled {
+		if s := &ims.Items[i].Status; s.ConnectionInfo.TenantUUID != "" && (ims.Items[i].HostMonitoringMode() || ims.Items[i].CloudNativeFullStackMode()) {
 			for key := range s.OneAgent.Instances {
 				if key != "" {
 					imNodes[key] = s.ConnectionInfo.TenantUUID
@@ -146,12 +146,14 @@ func (r *ReconcileNamespaces) Reconcile(ctx context.Context, request reconcile.R
 }
 
 type script struct {
-	DynaKube   *dynatracev1alpha1.DynaKube
-	PaaSToken  string
-	Proxy      string
-	TrustedCAs []byte
-	ClusterID  string
-	IMNodes    map[string]string
+	DynaKube           *dynatracev1beta1.DynaKube
+	PaaSToken          string
+	Proxy              string
+	NoProxy            string
+	CommunicationProxy string
+	TrustedCAs         []byte
+	ClusterID          string
+	IMNodes            map[string]string
 }
 
 func (r *ReconcileNamespaces) ensureSecretDeleted(name string, ns string) error {
@@ -162,23 +164,20 @@ func (r *ReconcileNamespaces) ensureSecretDeleted(name string, ns string) error
 	return nil
 }
 
-func newScript(ctx context.Context, c client.Client, dynaKube dynatracev1alpha1.DynaKube, tkns corev1.Secret, imNodes map[string]string, ns string) (*script, error) {
+func newScript(ctx context.Context, c client.Client, dynaKube dynatracev1beta1.DynaKube, tkns corev1.Secret, imNodes map[string]string, ns string) (*script, error) {
 	var kubeSystemNS corev1.Namespace
 	if err := c.Get(ctx, client.ObjectKey{Name: "kube-system"}, &kubeSystemNS); err != nil {
 		return nil, fmt.Errorf("failed to query for cluster ID: %w", err)
 	}
 
-	var proxy string
-	if dynaKube.Spec.Proxy != nil {
-		if dynaKube.Spec.Proxy.ValueFrom != "" {
-			var ps corev1.Secret
-			if err := c.Get(ctx, client.ObjectKey{Name: dynaKube.Spec.Proxy.ValueFrom, Namespace: ns}, &ps); err != nil {
-				return nil, fmt.Errorf("failed to query proxy: %w", err)
-			}
-			proxy = string(ps.Data["proxy"])
-		} else if dynaKube.Spec.Proxy.Value != "" {
-			proxy = dynaKube.Spec.Proxy.Value
-		}
+	proxy, err := getProxy(ctx, c, dynaKube.APIProxy(), ns)
+	if err != nil {
+		return nil, err
+	}
+
+	communicationProxy, err := getProxy(ctx, c, dynaKube.CommunicationProxy(), ns)
+	if err != nil {
+		return nil, err
 	}
 
 	var trustedCAs []byte
@@ -191,15 +190,33 @@ func newScript(ctx context.Context, c client.Client, dynaKube dynatracev1alpha1.
 	}
 
 	return &script{
-		DynaKube:   &dynaKube,
-		PaaSToken:  string(tkns.Data[utils.DynatracePaasToken]),
-		Proxy:      proxy,
-		TrustedCAs: trustedCAs,
-		ClusterID:  string(kubeSystemNS.UID),
-		IMNodes:    imNodes,
+		DynaKube:           &dynaKube,
+		PaaSToken:          string(tkns.Data[utils.DynatracePaasToken]),
+		Proxy:              proxy,
+		NoProxy:            dynaKube.NoProxy(),
+		CommunicationProxy: communicationProxy,
+		TrustedCAs:         trustedCAs,
+		ClusterID:          string(kubeSystemNS.UID),
+		IMNodes:            imNodes,
 	}, nil
 }
 
+// getProxy returns the proxy URL given by the proxy source, or an empty string if it is nil.
+func getProxy(ctx context.Context, c client.Client, src *dynatracev1beta1.DynaKubeProxySource, ns string) (string, error) {
+	if src == nil {
+		return "", nil
+	}
+
+	if src.ValueFrom != "" {
+		var ps corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey{Name: src.ValueFrom, Namespace: ns}, &ps); err != nil {
+			return "", fmt.Errorf("failed to query proxy: %w", err)
+		}
+		return string(ps.Data["proxy"]), nil
+	}
+	return src.Value, nil
+}
+
 func (s *script) generate() (map[string][]byte, error) {
 	var buf bytes.Buffer
 
@@ -215,8 +232,9 @@ func (s *script) generate() (map[string][]byte, error) {
 		data["ca.pem"] = s.TrustedCAs
 	}
 
-	if s.Proxy != "" {
-		data["proxy"] = []byte(s.Proxy)
+	// The proxy is used by the code modules to connect to the communication endpoints
+	if s.CommunicationProxy != "" {
+		data["proxy"] = []byte(s.CommunicationProxy)
 	}
 
 	return data, nil
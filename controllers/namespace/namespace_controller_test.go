@@ -6,7 +6,7 @@ import (
 	"os"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,23 +23,23 @@ var scriptSample string
 
 func TestReconcileNamespace(t *testing.T) {
 	c := fake.NewClient(
-		&dynatracev1alpha1.DynaKube{
+		&dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{Name: "oneagent", Namespace: "dynatrace"},
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				APIURL: "https://test-url/api",
-				CodeModules: dynatracev1alpha1.CodeModulesSpec{
-					Enabled: true,
-				},
-				InfraMonitoring: dynatracev1alpha1.FullStackSpec{
-					Enabled: true,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{
+						HostInjectSpec:   dynatracev1beta1.HostInjectSpec{},
+						AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{},
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				ConnectionInfo: dynatracev1alpha1.ConnectionInfoStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				ConnectionInfo: dynatracev1beta1.ConnectionInfoStatus{
 					TenantUUID: "abc12345",
 				},
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
-					Instances: map[string]dynatracev1alpha1.OneAgentInstance{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					Instances: map[string]dynatracev1beta1.OneAgentInstance{
 						"node1": {},
 					},
 				},
@@ -84,3 +84,33 @@ func TestReconcileNamespace(t *testing.T) {
 	require.NotEmpty(t, scriptSample) // sanity check to confirm that the sample script has been embedded
 	require.Equal(t, scriptSample, string(nsSecret.Data["init.sh"]))
 }
+
+func TestNewScript_Proxy(t *testing.T) {
+	c := fake.NewClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "42"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "communication-proxy", Namespace: "dynatrace"},
+			Data:       map[string][]byte{"proxy": []byte("http://communication-proxy:8080")},
+		},
+	)
+	dk := dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: "oneagent", Namespace: "dynatrace"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: "https://test-url/api",
+			Proxy: &dynatracev1beta1.DynaKubeProxy{
+				Value:         "http://api-proxy:8080",
+				Communication: &dynatracev1beta1.DynaKubeProxySource{ValueFrom: "communication-proxy"},
+				NoProxy:       []string{"localhost", ".cluster.local"},
+			},
+		},
+	}
+
+	s, err := newScript(context.TODO(), c, dk, corev1.Secret{}, nil, "dynatrace")
+	require.NoError(t, err)
+
+	data, err := s.generate()
+	require.NoError(t, err)
+	assert.Contains(t, string(data["init.sh"]), `proxy="http://api-proxy:8080"`)
+	assert.Contains(t, string(data["init.sh"]), `no_proxy="localhost,.cluster.local"`)
+	assert.Equal(t, "http://communication-proxy:8080", string(data["proxy"]))
+}
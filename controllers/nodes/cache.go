@@ -3,9 +3,21 @@ package nodes
 import (
 	"encoding/json"
 	"errors"
+	"hash/fnv"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// cacheShards is the number of ConfigMaps the nodes are spread over. Each entry takes about 200 bytes, so the cache
+	// holds around 80000 nodes before a ConfigMap reaches its size limit of 1 MiB.
+	cacheShards = 16
+
+	// cacheLabel is set on the ConfigMaps of the cache.
+	cacheLabel = "operator.dynatrace.com/node-cache"
 )
 
 // ErrNotFound is returned when entry hasn't been found on the cache.
@@ -17,22 +29,100 @@ type CacheEntry struct {
 	IPAddress                string    `json:"ip"`
 	LastSeen                 time.Time `json:"seen"`
 	LastMarkedForTermination time.Time `json:"marked"`
+	// MarkedReason is the reason of the termination notice the node was last marked for termination with, empty if it
+	// was marked without one, e.g. because it has been cordoned
+	MarkedReason string `json:"markedReason,omitempty"`
+
+	// Cordoned is when the node was found to be unschedulable, zero if it's schedulable
+	Cordoned time.Time `json:"cordoned,omitempty"`
+	// DrainStarted is when pods started to be evicted from the cordoned node
+	DrainStarted time.Time `json:"drainStarted,omitempty"`
+	// OneAgentEvicted is when the OneAgent pod was evicted from the cordoned node
+	OneAgentEvicted time.Time `json:"oneAgentEvicted,omitempty"`
+	// Reason is why the node has been cordoned, one of the lifecycle reasons
+	Reason string `json:"reason,omitempty"`
 }
 
-// Cache manages information about Nodes.
+// Cache manages information about Nodes. Nodes are spread over several ConfigMaps by the hash of their names, so the
+// cache doesn't hit the size limit of ConfigMaps on big clusters, and updates of different nodes rarely conflict.
 type Cache struct {
+	Shards []*CacheShard
+}
+
+// CacheShard is one of the ConfigMaps of the cache.
+type CacheShard struct {
 	Obj    *corev1.ConfigMap
 	Create bool
 	upd    bool
 }
 
+// cacheShardName returns the name of the ConfigMap of the shard.
+func cacheShardName(shard int) string {
+	return cacheName + "-" + strconv.Itoa(shard)
+}
+
+// newCacheShard returns the ConfigMap of the shard which doesn't exist yet.
+func newCacheShard(shard int, namespace string) *CacheShard {
+	return &CacheShard{
+		Create: true,
+		Obj: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cacheShardName(shard),
+				Namespace: namespace,
+				Labels:    map[string]string{cacheLabel: "true"},
+			},
+			Data: map[string]string{},
+		},
+	}
+}
+
+func (c *Cache) shard(node string) *CacheShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(node))
+	return c.Shards[h.Sum32()%uint32(len(c.Shards))]
+}
+
 // Get returns the information about node, or error if not found or failed to unmarshall the data.
 func (c *Cache) Get(node string) (CacheEntry, error) {
-	if c.Obj.Data == nil {
+	return c.shard(node).Get(node)
+}
+
+// Set updates the information about node, or error if failed to marshall the data.
+func (c *Cache) Set(node string, entry CacheEntry) error {
+	return c.shard(node).Set(node, entry)
+}
+
+// Delete removes the node from the cache.
+func (c *Cache) Delete(node string) {
+	c.shard(node).Delete(node)
+}
+
+// Keys returns a list of node names on the cache.
+func (c *Cache) Keys() []string {
+	out := []string{}
+	for _, shard := range c.Shards {
+		out = append(out, shard.Keys()...)
+	}
+	return out
+}
+
+// Changed returns true if changes have been made to the cache instance.
+func (c *Cache) Changed() bool {
+	for _, shard := range c.Shards {
+		if shard.Changed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the information about node, or error if not found or failed to unmarshall the data.
+func (s *CacheShard) Get(node string) (CacheEntry, error) {
+	if s.Obj.Data == nil {
 		return CacheEntry{}, ErrNotFound
 	}
 
-	raw, ok := c.Obj.Data[node]
+	raw, ok := s.Obj.Data[node]
 	if !ok {
 		return CacheEntry{}, ErrNotFound
 	}
@@ -46,41 +136,38 @@ func (c *Cache) Get(node string) (CacheEntry, error) {
 }
 
 // Set updates the information about node, or error if failed to marshall the data.
-func (c *Cache) Set(node string, entry CacheEntry) error {
+func (s *CacheShard) Set(node string, entry CacheEntry) error {
 	raw, err := json.Marshal(entry)
 	if err != nil {
 		return err
 	}
-	if c.Obj.Data == nil {
-		c.Obj.Data = map[string]string{}
+	if s.Obj.Data == nil {
+		s.Obj.Data = map[string]string{}
 	}
-	c.Obj.Data[node] = string(raw)
-	c.upd = true
+	s.Obj.Data[node] = string(raw)
+	s.upd = true
 	return nil
 }
 
-// Delete removes the node from the cache.
-func (c *Cache) Delete(node string) {
-	if c.Obj.Data != nil {
-		delete(c.Obj.Data, node)
-		c.upd = true
+// Delete removes the node from the shard.
+func (s *CacheShard) Delete(node string) {
+	if _, ok := s.Obj.Data[node]; ok {
+		delete(s.Obj.Data, node)
+		s.upd = true
 	}
 }
 
-// Keys returns a list of node names on the cache.
-func (c *Cache) Keys() []string {
-	if c.Obj.Data == nil {
-		return []string{}
-	}
-
-	out := make([]string, 0, len(c.Obj.Data))
-	for k := range c.Obj.Data {
+// Keys returns a list of node names on the shard.
+func (s *CacheShard) Keys() []string {
+	out := make([]string, 0, len(s.Obj.Data))
+	for k := range s.Obj.Data {
 		out = append(out, k)
 	}
 	return out
 }
 
-// Changed returns true if changes have been made to the cache instance.
-func (c *Cache) Changed() bool {
-	return c.Create || c.upd
+// Changed returns true if changes have been made to the shard. Shards which don't exist yet are only created once
+// nodes have been added to them.
+func (s *CacheShard) Changed() bool {
+	return s.upd
 }
@@ -0,0 +1,97 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCache_Shards(t *testing.T) {
+	c := &Cache{Shards: make([]*CacheShard, cacheShards)}
+	for i := range c.Shards {
+		c.Shards[i] = newCacheShard(i, testNamespace)
+	}
+	assert.False(t, c.Changed())
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, c.Set(fmt.Sprintf("node-%d", i), CacheEntry{Instance: "oneagent1"}))
+	}
+	assert.True(t, c.Changed())
+	assert.Len(t, c.Keys(), 100)
+
+	var used int
+	for _, shard := range c.Shards {
+		if len(shard.Obj.Data) > 0 {
+			used++
+		}
+	}
+	assert.True(t, used > 1, "nodes should be spread over several shards")
+
+	entry, err := c.Get("node-42")
+	require.NoError(t, err)
+	assert.Equal(t, "oneagent1", entry.Instance)
+
+	c.Delete("node-42")
+	_, err = c.Get("node-42")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Len(t, c.Keys(), 99)
+}
+
+func TestNodesReconciler_ShardedCache(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	ctrl := createDefaultReconciler(fakeClient, &dtclient.MockDynatraceClient{})
+
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+
+	// Only shards holding nodes are created
+	var cmList corev1.ConfigMapList
+	require.NoError(t, fakeClient.List(context.TODO(), &cmList, client.InNamespace(testNamespace), client.MatchingLabels{cacheLabel: "true"}))
+	require.NotEmpty(t, cmList.Items)
+	assert.True(t, len(cmList.Items) <= 2)
+
+	var nodes int
+	for _, cm := range cmList.Items {
+		nodes += len(cm.Data)
+	}
+	assert.Equal(t, 2, nodes)
+}
+
+func TestNodesReconciler_MigrateCache(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	ctrl := createDefaultReconciler(fakeClient, &dtclient.MockDynatraceClient{})
+
+	marked := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	old := &CacheShard{Obj: &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cacheName, Namespace: testNamespace},
+	}}
+	require.NoError(t, old.Set("node1", CacheEntry{Instance: "oneagent1", IPAddress: "1.2.3.4", LastMarkedForTermination: marked}))
+	require.NoError(t, old.Set("node3", CacheEntry{Instance: "oneagent1", IPAddress: "9.9.9.9"}))
+	old.Obj.Data["node4"] = "invalid"
+	require.NoError(t, fakeClient.Create(context.TODO(), old.Obj))
+
+	require.NoError(t, ctrl.migrateCache(context.TODO()))
+
+	err := fakeClient.Get(context.TODO(), client.ObjectKey{Name: cacheName, Namespace: testNamespace}, &corev1.ConfigMap{})
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"node1", "node3"}, c.Keys())
+
+	entry, err := c.Get("node1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", entry.IPAddress)
+	assert.True(t, marked.Equal(entry.LastMarkedForTermination))
+
+	// Nothing to migrate anymore
+	require.NoError(t, ctrl.migrateCache(context.TODO()))
+}
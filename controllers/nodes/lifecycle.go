@@ -0,0 +1,218 @@
+package nodes
+
+import (
+	"context"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonAutoscalerScaleDown is the reason for nodes tainted by the cluster autoscaler before it removes them
+	reasonAutoscalerScaleDown = "autoscaler-scale-down"
+	// reasonManualDrain is the reason for nodes cordoned otherwise, e.g. by kubectl drain
+	reasonManualDrain = "manual-drain"
+	// reasonSpotTermination is the reason for spot and preemptible nodes reclaimed by the cloud provider
+	reasonSpotTermination = "spot-termination"
+	// reasonNodeTermination is the reason for nodes with another termination signal
+	reasonNodeTermination = "node-termination"
+
+	phaseCordon   = "cordon"
+	phaseDrain    = "drain"
+	phaseEviction = "oneagent-eviction"
+	phaseRemoval  = "removal"
+	phaseUncordon = "uncordon"
+
+	eventSource             = "OneAgent Operator"
+	lifecycleAnnotationType = "Kubernetes node lifecycle"
+)
+
+var lifecycleDescriptions = map[string]string{
+	phaseCordon:   "Kubernetes node cordoned",
+	phaseDrain:    "Kubernetes node drain started",
+	phaseEviction: "OneAgent pod evicted from Kubernetes node",
+	phaseRemoval:  "Kubernetes node removed",
+	phaseUncordon: "Kubernetes node uncordoned",
+}
+
+// reconcileLifecycle tracks the cached node from being cordoned until it's uncordoned, and sends an event to Dynatrace
+// whenever the node enters a phase of its lifecycle. Events ending a phase span the time since the node was cordoned.
+// Node removals are handled by removeNode, since the node can't be read anymore.
+func (r *ReconcileNodes) reconcileLifecycle(ctx context.Context, c *Cache, node *corev1.Node) error {
+	entry, err := c.Get(node.Name)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var dk dynatracev1beta1.DynaKube
+	if err := r.client.Get(ctx, client.ObjectKey{Name: entry.Instance, Namespace: r.namespace}, &dk); errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var events []*dtclient.EventData
+	if !r.isUnschedulable(node) {
+		if entry.Cordoned.IsZero() {
+			return nil
+		}
+		events = append(events, newLifecycleEvent(phaseUncordon, node.Name, entry, entry.Cordoned, now))
+		resetLifecycle(&entry)
+	} else {
+		if entry.Cordoned.IsZero() {
+			// Use the time of the termination notice if known, the node might have been updated a while later
+			entry.Cordoned = now
+			if notice, ok := r.signals.notice(node); ok && !notice.time.IsZero() && notice.time.Before(now) {
+				entry.Cordoned = notice.time.UTC()
+			}
+			entry.Reason = r.cordonReason(node)
+			events = append(events, newLifecycleEvent(phaseCordon, node.Name, entry, entry.Cordoned, entry.Cordoned),
+				newLifecycleAnnotation(node.Name, entry, entry.Cordoned))
+		}
+
+		if entry.DrainStarted.IsZero() {
+			draining, err := r.isDraining(ctx, node)
+			if err != nil {
+				return err
+			}
+			if draining {
+				entry.DrainStarted = now
+				events = append(events, newLifecycleEvent(phaseDrain, node.Name, entry, now, now))
+			}
+		}
+
+		if entry.OneAgentEvicted.IsZero() {
+			evicted, err := r.isOneAgentEvicted(ctx, &dk, node.Name)
+			if err != nil {
+				return err
+			}
+			if evicted {
+				entry.OneAgentEvicted = now
+				events = append(events, newLifecycleEvent(phaseEviction, node.Name, entry, now, now))
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	// The entry is only updated once the events have been sent, so they're sent again on the next reconcile otherwise
+	r.logger.Info("sending node lifecycle events to dynatrace server", "dynakube", dk.Name, "node", node.Name,
+		"reason", entry.Reason, "events", len(events))
+	if err := r.sendEvents(ctx, &dk, entry.IPAddress, events...); err != nil {
+		return err
+	}
+	return c.Set(node.Name, entry)
+}
+
+// sendRemovalEvent notifies Dynatrace that the node has been removed, spanning the time since it was cordoned.
+func (r *ReconcileNodes) sendRemovalEvent(ctx context.Context, dk *dynatracev1beta1.DynaKube, node string, entry CacheEntry) error {
+	now := time.Now().UTC()
+	start := entry.Cordoned
+	if start.IsZero() {
+		start = now
+	}
+	return r.sendEvents(ctx, dk, entry.IPAddress, newLifecycleEvent(phaseRemoval, node, entry, start, now))
+}
+
+// isDraining returns true if pods not managed by a DaemonSet are being evicted from the node. Nodes tainted by the
+// cluster autoscaler are drained right away.
+func (r *ReconcileNodes) isDraining(ctx context.Context, node *corev1.Node) (bool, error) {
+	if r.cordonReason(node) == reasonAutoscalerScaleDown {
+		return true, nil
+	}
+
+	var pods corev1.PodList
+	if err := r.apiReader.List(ctx, &pods, client.MatchingFields{"spec.nodeName": node.Name}); err != nil {
+		return false, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != node.Name || pod.DeletionTimestamp == nil {
+			continue
+		}
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// isOneAgentEvicted returns true if the OneAgent pod last seen on the node is terminating or has been deleted.
+func (r *ReconcileNodes) isOneAgentEvicted(ctx context.Context, dk *dynatracev1beta1.DynaKube, node string) (bool, error) {
+	instance, ok := dk.Status.OneAgent.Instances[node]
+	if !ok || instance.PodName == "" {
+		return false, nil
+	}
+
+	var pod corev1.Pod
+	err := r.apiReader.Get(ctx, client.ObjectKey{Name: instance.PodName, Namespace: dk.Namespace}, &pod)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return pod.DeletionTimestamp != nil, nil
+}
+
+// cordonReason returns why the unschedulable node has been cordoned, manual-drain unless it's about to be terminated.
+func (r *ReconcileNodes) cordonReason(node *corev1.Node) string {
+	if reason, ok := r.signals.reason(node); ok {
+		return reason
+	}
+	return reasonManualDrain
+}
+
+func resetLifecycle(entry *CacheEntry) {
+	entry.Cordoned = time.Time{}
+	entry.DrainStarted = time.Time{}
+	entry.OneAgentEvicted = time.Time{}
+	entry.Reason = ""
+}
+
+func newLifecycleEvent(phase string, node string, entry CacheEntry, start time.Time, end time.Time) *dtclient.EventData {
+	return &dtclient.EventData{
+		EventType:     dtclient.CustomInfoEvent,
+		Source:        eventSource,
+		Description:   lifecycleDescriptions[phase],
+		StartInMillis: toMillis(start),
+		EndInMillis:   toMillis(end),
+		CustomProperties: map[string]string{
+			"node":   node,
+			"phase":  phase,
+			"reason": entry.Reason,
+		},
+	}
+}
+
+// newLifecycleAnnotation annotates the host with the reason the node has been cordoned.
+func newLifecycleAnnotation(node string, entry CacheEntry, ts time.Time) *dtclient.EventData {
+	return &dtclient.EventData{
+		EventType:             dtclient.CustomAnnotationEvent,
+		Source:                eventSource,
+		Description:           lifecycleDescriptions[phaseCordon] + ": " + entry.Reason,
+		StartInMillis:         toMillis(ts),
+		EndInMillis:           toMillis(ts),
+		AnnotationType:        lifecycleAnnotationType,
+		AnnotationDescription: entry.Reason,
+		CustomProperties: map[string]string{
+			"node":   node,
+			"reason": entry.Reason,
+		},
+	}
+}
+
+func toMillis(t time.Time) uint64 {
+	return uint64(t.UnixNano()) / uint64(time.Millisecond)
+}
@@ -0,0 +1,177 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newLifecycleTestClient(objs ...client.Object) client.Client {
+	return fake.NewClient(append([]client.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "oneagent-abc", Namespace: testNamespace}, Spec: corev1.PodSpec{NodeName: "node1"}},
+		&dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: "oneagent1", Namespace: testNamespace},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					Instances: map[string]dynatracev1beta1.OneAgentInstance{"node1": {IPAddress: "1.2.3.4", PodName: "oneagent-abc"}},
+				},
+			},
+		},
+	}, objs...)...)
+}
+
+// recordEvents returns a mock client recording the events sent for the host with the IP address.
+func recordEvents(ip string, events *[]dtclient.EventData) *dtclient.MockDynatraceClient {
+	dtClient := &dtclient.MockDynatraceClient{}
+	dtClient.On("GetEntityIDForIP", ip).Return("HOST-42", nil)
+	dtClient.On("SendEvent", mock.Anything).Run(func(args mock.Arguments) {
+		*events = append(*events, *args.Get(0).(*dtclient.EventData))
+	}).Return(nil)
+	return dtClient
+}
+
+func lifecycleEvents(events []dtclient.EventData) []string {
+	var out []string
+	for _, event := range events {
+		if event.EventType == dtclient.CustomInfoEvent {
+			out = append(out, event.CustomProperties["phase"])
+		} else {
+			out = append(out, event.EventType)
+		}
+	}
+	return out
+}
+
+func updateNode(t *testing.T, cl client.Client, name string, update func(node *corev1.Node)) {
+	var node corev1.Node
+	require.NoError(t, cl.Get(context.TODO(), client.ObjectKey{Name: name}, &node))
+	update(&node)
+	require.NoError(t, cl.Update(context.TODO(), &node))
+}
+
+func TestReconcileLifecycle_ManualDrain(t *testing.T) {
+	now := metav1.Now()
+	fakeClient := newLifecycleTestClient(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", DeletionTimestamp: &now},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+	})
+	var events []dtclient.EventData
+	ctrl := createDefaultReconciler(fakeClient, recordEvents("1.2.3.4", &events))
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+	assert.Empty(t, events)
+
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) { node.Spec.Unschedulable = true })
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+
+	assert.Equal(t, []string{dtclient.MarkedForTerminationEvent, phaseCordon, dtclient.CustomAnnotationEvent, phaseDrain}, lifecycleEvents(events))
+	assert.Equal(t, reasonManualDrain, events[1].CustomProperties["reason"])
+	assert.Equal(t, reasonManualDrain, events[2].AnnotationDescription)
+	assert.Equal(t, []string{"HOST-42"}, events[1].AttachRules.EntityIDs)
+
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	entry, err := c.Get("node1")
+	require.NoError(t, err)
+	assert.False(t, entry.Cordoned.IsZero())
+	assert.False(t, entry.DrainStarted.IsZero())
+	assert.True(t, entry.OneAgentEvicted.IsZero())
+
+	// Phases are only reported once
+	events = nil
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	assert.Empty(t, events)
+
+	require.NoError(t, fakeClient.Delete(context.TODO(), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "oneagent-abc", Namespace: testNamespace}}))
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	assert.Equal(t, []string{phaseEviction}, lifecycleEvents(events))
+
+	events = nil
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) { node.Spec.Unschedulable = false })
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	require.Equal(t, []string{phaseUncordon}, lifecycleEvents(events))
+	assert.Equal(t, toMillis(entry.Cordoned), events[0].StartInMillis)
+	assert.True(t, events[0].EndInMillis >= events[0].StartInMillis)
+
+	c, err = ctrl.getCache()
+	require.NoError(t, err)
+	entry, err = c.Get("node1")
+	require.NoError(t, err)
+	assert.True(t, entry.Cordoned.IsZero())
+	assert.Empty(t, entry.Reason)
+}
+
+func TestReconcileLifecycle_AutoscalerScaleDown(t *testing.T) {
+	fakeClient := newLifecycleTestClient()
+	var events []dtclient.EventData
+	ctrl := createDefaultReconciler(fakeClient, recordEvents("1.2.3.4", &events))
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) {
+		node.Spec.Taints = []corev1.Taint{{Key: autoscalerTaint}}
+	})
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	assert.Equal(t, []string{dtclient.MarkedForTerminationEvent, phaseCordon, dtclient.CustomAnnotationEvent, phaseDrain}, lifecycleEvents(events))
+	assert.Equal(t, reasonAutoscalerScaleDown, events[1].CustomProperties["reason"])
+
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	entry, err := c.Get("node1")
+	require.NoError(t, err)
+
+	events = nil
+	require.NoError(t, fakeClient.Delete(context.TODO(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}))
+	require.NoError(t, ctrl.onDeletion(context.TODO(), "node1"))
+
+	// The node has just been marked for termination, so only the removal is reported
+	require.Equal(t, []string{phaseRemoval}, lifecycleEvents(events))
+	assert.Equal(t, toMillis(entry.Cordoned), events[0].StartInMillis)
+	assert.Equal(t, reasonAutoscalerScaleDown, events[0].CustomProperties["reason"])
+	assert.True(t, time.Since(entry.Cordoned) < time.Minute)
+}
+
+func TestReconcileLifecycle_RetryFailedEvents(t *testing.T) {
+	fakeClient := newLifecycleTestClient()
+	var events []dtclient.EventData
+	dtClient := &dtclient.MockDynatraceClient{}
+	dtClient.On("GetEntityIDForIP", "1.2.3.4").Return("HOST-42", nil)
+	dtClient.On("SendEvent", mock.MatchedBy(func(e *dtclient.EventData) bool {
+		return e.EventType == dtclient.CustomInfoEvent
+	})).Return(errors.New("connection reset")).Once()
+	dtClient.On("SendEvent", mock.Anything).Run(func(args mock.Arguments) {
+		events = append(events, *args.Get(0).(*dtclient.EventData))
+	}).Return(nil)
+	ctrl := createDefaultReconciler(fakeClient, dtClient)
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) { node.Spec.Unschedulable = true })
+	assert.Error(t, ctrl.onUpdate(context.TODO(), "node1"))
+
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	entry, err := c.Get("node1")
+	require.NoError(t, err)
+	assert.True(t, entry.Cordoned.IsZero())
+
+	// The sweep sends the events again and reports the failure of single nodes
+	events = nil
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+	assert.Equal(t, []string{phaseCordon, dtclient.CustomAnnotationEvent}, lifecycleEvents(events))
+
+	c, err = ctrl.getCache()
+	require.NoError(t, err)
+	entry, err = c.Get("node1")
+	require.NoError(t, err)
+	assert.False(t, entry.Cordoned.IsZero())
+}
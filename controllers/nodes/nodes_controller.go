@@ -2,113 +2,138 @@ package nodes
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
+	// cacheName is the name of the ConfigMap holding all nodes, as used by previous versions, and the prefix of the
+	// ConfigMaps of the sharded cache.
 	cacheName = "dynatrace-node-cache"
-)
 
-var unschedulableTaints = []string{"ToBeDeletedByClusterAutoscaler"}
+	autoscalerTaint = "ToBeDeletedByClusterAutoscaler"
+
+	// resyncPeriod is how often all nodes are reconciled, catching up on missed or skipped node events.
+	resyncPeriod = 5 * time.Minute
+)
 
 type ReconcileNodes struct {
 	namespace    string
 	client       client.Client
-	cache        cache.Cache
+	apiReader    client.Reader
 	scheme       *runtime.Scheme
 	logger       logr.Logger
 	dtClientFunc dynakube.DynatraceClientFunc
 	local        bool
+
+	// signals tell which nodes are about to be terminated, besides the ones which have been cordoned
+	signals terminationSignals
+
+	// rateLimitedUntil holds, per DynaKube, until when no requests should be sent to the Dynatrace API after it
+	// rejected requests because of rate limiting.
+	rateLimitedUntil map[string]time.Time
 }
 
 // Add creates a new Nodes Controller and adds it to the Manager. The Manager will set fields on the Controller
-// and Start it when the Manager is Started.
+// and Start it when the Manager is Started. Nodes about to be terminated are detected by the presets in
+// NODE_TERMINATION_PRESETS, cluster-autoscaler if unset, and by the taints, conditions and annotations listed in
+// NODE_TERMINATION_TAINTS, NODE_TERMINATION_CONDITIONS and NODE_TERMINATION_ANNOTATIONS.
 func Add(mgr manager.Manager, ns string) error {
-	return mgr.Add(&ReconcileNodes{
+	presets, ok := os.LookupEnv(envTerminationPresets)
+	if !ok {
+		presets = presetClusterAutoscaler
+	}
+	signals, err := newTerminationSignals(presets, os.Getenv(envTerminationTaints), os.Getenv(envTerminationConditions), os.Getenv(envTerminationAnnotations))
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, &ReconcileNodes{
 		namespace:    ns,
 		client:       mgr.GetClient(),
-		cache:        mgr.GetCache(),
+		apiReader:    mgr.GetAPIReader(),
 		scheme:       mgr.GetScheme(),
 		logger:       log.Log.WithName("nodes.controller"),
 		dtClientFunc: dynakube.BuildDynatraceClient,
 		local:        os.Getenv("RUN_LOCAL") == "true",
+		signals:      signals,
 	})
 }
 
-// Start starts the Nodes Reconciler, and will block until a stop signal is sent.
-func (r *ReconcileNodes) Start(stop context.Context) error {
-	r.cache.WaitForCacheSync(stop)
-
-	chDels, err := r.watchDeletions(stop.Done())
+func add(mgr manager.Manager, r *ReconcileNodes) error {
+	// Only one reconcile runs at a time, since all of them update the nodes cache. The controller only runs on the
+	// leader, so there are no concurrent updates by other replicas either.
+	c, err := controller.New("nodes-controller", mgr, controller.Options{Reconciler: r})
 	if err != nil {
-		// I've seen watchDeletions() fail because the Cache Informers weren't ready. WaitForCacheSync()
-		// should block until they are, however, but I believe I saw this not being true once.
-		//
-		// Start() failing would exit the Operator process. Since this is a minor feature, let's disable
-		// for now until further investigation is done.
-		r.logger.Info("failed to initialize watcher for deleted nodes - disabled", "error", err)
-		chDels = make(chan string)
-	}
-	chUpdates, err := r.watchUpdates()
-	if err != nil {
-		r.logger.Info("failed to initialize watcher for updating nodes - disabled", "error", err)
-		chUpdates = make(chan string)
+		return err
 	}
 
-	chAll := watchTicks(stop.Done(), 5*time.Minute)
+	if err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{}, r.nodePredicate()); err != nil {
+		return err
+	}
 
-	for {
-		select {
-		case <-stop.Done():
-			r.logger.Info("stopping nodes controller")
-			return nil
-		case node := <-chDels:
-			if err := r.onDeletion(node); err != nil {
-				r.logger.Error(err, "failed to reconcile deletion", "node", node)
-			}
-		case node := <-chUpdates:
-			if err := r.onUpdate(node); err != nil {
-				r.logger.Error(err, "failed to reconcile updates", "node", node)
-			}
-		case <-chAll:
-			if err := r.reconcileAll(); err != nil {
-				r.logger.Error(err, "failed to reconcile nodes")
-			}
+	return c.Watch(r.sweepSource(), &handler.EnqueueRequestForObject{})
+}
+
+// Reconcile handles the update or deletion of the node in the request. The sweep request reconciles all nodes instead,
+// and is requeued to run every resyncPeriod. Failed requests are retried with a backoff.
+func (r *ReconcileNodes) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request == r.sweepRequest() {
+		if err := r.migrateCache(ctx); err != nil {
+			r.logger.Error(err, "failed to migrate nodes cache")
 		}
+
+		if err := r.reconcileAll(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: resyncPeriod}, nil
+	}
+
+	var node corev1.Node
+	if err := r.client.Get(ctx, client.ObjectKey{Name: request.Name}, &node); errors.IsNotFound(err) {
+		return reconcile.Result{}, r.onDeletion(ctx, request.Name)
+	} else if err != nil {
+		return reconcile.Result{}, err
 	}
+
+	return reconcile.Result{}, r.onUpdate(ctx, request.Name)
 }
 
-func (r *ReconcileNodes) onUpdate(node string) error {
+func (r *ReconcileNodes) onUpdate(ctx context.Context, node string) error {
 	c, err := r.getCache()
 	if err != nil {
 		return err
 	}
 
-	if err = r.updateNode(c, node); err != nil {
+	// Changes made before a failure are saved, the failed steps are retried with the request
+	updateErr := r.updateNode(ctx, c, node)
+	if err = r.updateCache(c); err != nil {
 		return err
 	}
-
-	return r.updateCache(c)
+	return updateErr
 }
 
-func (r *ReconcileNodes) onDeletion(node string) error {
+func (r *ReconcileNodes) onDeletion(ctx context.Context, node string) error {
 	logger := r.logger.WithValues("node", node)
 
 	logger.Info("node deletion notification received")
@@ -118,9 +143,9 @@ func (r *ReconcileNodes) onDeletion(node string) error {
 		return err
 	}
 
-	if err = r.removeNode(c, node, func(oaName string) (*dynatracev1alpha1.DynaKube, error) {
-		var dynaKube dynatracev1alpha1.DynaKube
-		if err := r.client.Get(context.TODO(), client.ObjectKey{Name: oaName, Namespace: r.namespace}, &dynaKube); err != nil {
+	if err = r.removeNode(ctx, c, node, func(oaName string) (*dynatracev1beta1.DynaKube, error) {
+		var dynaKube dynatracev1beta1.DynaKube
+		if err := r.client.Get(ctx, client.ObjectKey{Name: oaName, Namespace: r.namespace}, &dynaKube); err != nil {
 			return nil, err
 		}
 		return &dynaKube, nil
@@ -131,15 +156,15 @@ func (r *ReconcileNodes) onDeletion(node string) error {
 	return r.updateCache(c)
 }
 
-func (r *ReconcileNodes) reconcileAll() error {
+func (r *ReconcileNodes) reconcileAll(ctx context.Context) error {
 	r.logger.Info("reconciling nodes")
 
-	var oaLst dynatracev1alpha1.DynaKubeList
-	if err := r.client.List(context.TODO(), &oaLst, client.InNamespace(r.namespace)); err != nil {
+	var oaLst dynatracev1beta1.DynaKubeList
+	if err := r.client.List(ctx, &oaLst, client.InNamespace(r.namespace)); err != nil {
 		return err
 	}
 
-	oas := make(map[string]*dynatracev1alpha1.DynaKube, len(oaLst.Items))
+	oas := make(map[string]*dynatracev1beta1.DynaKube, len(oaLst.Items))
 	for i := range oaLst.Items {
 		oas[oaLst.Items[i].Name] = &oaLst.Items[i]
 	}
@@ -150,7 +175,7 @@ func (r *ReconcileNodes) reconcileAll() error {
 	}
 
 	var nodeLst corev1.NodeList
-	if err := r.client.List(context.TODO(), &nodeLst); err != nil {
+	if err := r.client.List(ctx, &nodeLst); err != nil {
 		return err
 	}
 
@@ -161,8 +186,8 @@ func (r *ReconcileNodes) reconcileAll() error {
 
 		// Sometimes Azure does not cordon off nodes before deleting them since they use taints,
 		// this case is handled in the update event handler
-		if isUnschedulable(&node) {
-			if err = r.reconcileUnschedulableNode(&node, c); err != nil {
+		if r.isUnschedulable(&node) {
+			if err = r.reconcileUnschedulableNode(ctx, &node, c); err != nil {
 				return err
 			}
 		}
@@ -176,30 +201,37 @@ func (r *ReconcileNodes) reconcileAll() error {
 					continue
 				}
 
-				info := CacheEntry{
-					Instance:  oa.Name,
-					IPAddress: info.IPAddress,
-					LastSeen:  time.Now().UTC(),
-				}
-
-				if cached, err := c.Get(node); err == nil {
-					info.LastMarkedForTermination = cached.LastMarkedForTermination
+				cached, err := c.Get(node)
+				if err != nil && err != ErrNotFound {
+					return err
 				}
+				cached.Instance = oa.Name
+				cached.IPAddress = info.IPAddress
+				cached.LastSeen = time.Now().UTC()
 
-				if err := c.Set(node, info); err != nil {
+				if err := c.Set(node, cached); err != nil {
 					return err
 				}
 			}
 		}
 	}
 
+	// Lifecycle failures of single nodes don't hold up the others, the sweep is retried once the cache has been saved
+	var lifecycleErr error
+	for i := range nodeLst.Items {
+		if err := r.reconcileLifecycle(ctx, c, &nodeLst.Items[i]); err != nil {
+			r.logger.Error(err, "failed to reconcile node lifecycle", "node", nodeLst.Items[i].Name)
+			lifecycleErr = err
+		}
+	}
+
 	// Notify and remove all nodes on the c that aren't in the cluster.
 	for _, node := range c.Keys() {
 		if _, ok := nodes[node]; ok {
 			continue
 		}
 
-		if err := r.removeNode(c, node, func(name string) (*dynatracev1alpha1.DynaKube, error) {
+		if err := r.removeNode(ctx, c, node, func(name string) (*dynatracev1beta1.DynaKube, error) {
 			if oa, ok := oas[name]; ok {
 				return oa, nil
 			}
@@ -213,58 +245,114 @@ func (r *ReconcileNodes) reconcileAll() error {
 		}
 	}
 
-	return r.updateCache(c)
+	if err := r.updateCache(c); err != nil {
+		return err
+	}
+	return lifecycleErr
 }
 
 func (r *ReconcileNodes) getCache() (*Cache, error) {
-	var cm corev1.ConfigMap
+	var cmList corev1.ConfigMapList
+	if err := r.client.List(context.TODO(), &cmList, client.InNamespace(r.namespace), client.MatchingLabels{cacheLabel: "true"}); err != nil {
+		return nil, err
+	}
 
-	err := r.client.Get(context.TODO(), client.ObjectKey{Name: cacheName, Namespace: r.namespace}, &cm)
-	if err == nil {
-		return &Cache{Obj: &cm}, nil
+	existing := make(map[string]*corev1.ConfigMap, len(cmList.Items))
+	for i := range cmList.Items {
+		existing[cmList.Items[i].Name] = &cmList.Items[i]
 	}
 
-	if errors.IsNotFound(err) {
-		r.logger.Info("no cache found, creating")
-
-		cm := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      cacheName,
-				Namespace: r.namespace,
-			},
-			Data: map[string]string{},
+	c := &Cache{Shards: make([]*CacheShard, cacheShards)}
+	for i := range c.Shards {
+		if cm, ok := existing[cacheShardName(i)]; ok {
+			c.Shards[i] = &CacheShard{Obj: cm}
+		} else {
+			c.Shards[i] = newCacheShard(i, r.namespace)
+		}
+	}
+	return c, nil
+}
+
+func (r *ReconcileNodes) updateCache(c *Cache) error {
+	var deploy *appsv1.Deployment
+	for _, shard := range c.Shards {
+		if !shard.Changed() {
+			continue
 		}
 
+		if !shard.Create {
+			if err := r.client.Update(context.TODO(), shard.Obj); err != nil {
+				return err
+			}
+			continue
+		}
+
+		r.logger.Info("no cache shard found, creating", "name", shard.Obj.Name)
 		if !r.local { // If running locally, don't set the controller.
-			deploy, err := utils.GetDeployment(r.client, r.namespace)
-			if err != nil {
-				return nil, err
+			if deploy == nil {
+				var err error
+				if deploy, err = utils.GetDeployment(r.client, r.namespace); err != nil {
+					return err
+				}
 			}
 
-			if err = controllerutil.SetControllerReference(deploy, cm, r.scheme); err != nil {
-				return nil, err
+			if err := controllerutil.SetControllerReference(deploy, shard.Obj, r.scheme); err != nil {
+				return err
 			}
 		}
 
-		return &Cache{Create: true, Obj: cm}, nil
+		if err := r.client.Create(context.TODO(), shard.Obj); err != nil {
+			return err
+		}
 	}
-
-	return nil, err
+	return nil
 }
 
-func (r *ReconcileNodes) updateCache(c *Cache) error {
-	if !c.Changed() {
+// migrateCache moves the nodes of the cache used by previous versions, a single ConfigMap for all nodes, to the
+// sharded cache. Nodes already found on the sharded cache are kept.
+func (r *ReconcileNodes) migrateCache(ctx context.Context) error {
+	var cm corev1.ConfigMap
+	err := r.client.Get(ctx, client.ObjectKey{Name: cacheName, Namespace: r.namespace}, &cm)
+	if errors.IsNotFound(err) {
 		return nil
+	} else if err != nil {
+		return err
 	}
 
-	if c.Create {
-		return r.client.Create(context.TODO(), c.Obj)
+	r.logger.Info("migrating nodes cache", "nodes", len(cm.Data))
+
+	c, err := r.getCache()
+	if err != nil {
+		return err
+	}
+
+	old := &CacheShard{Obj: &cm}
+	for _, node := range old.Keys() {
+		if _, err := c.Get(node); err != ErrNotFound {
+			continue
+		}
+
+		entry, err := old.Get(node)
+		if err != nil {
+			r.logger.Info("dropping invalid nodes cache entry", "node", node, "error", err)
+			continue
+		}
+		if err := c.Set(node, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := r.updateCache(c); err != nil {
+		return err
 	}
 
-	return r.client.Update(context.TODO(), c.Obj)
+	if err := r.client.Delete(ctx, &cm); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
 }
 
-func (r *ReconcileNodes) removeNode(c *Cache, node string, oaFunc func(name string) (*dynatracev1alpha1.DynaKube, error)) error {
+func (r *ReconcileNodes) removeNode(ctx context.Context, c *Cache, node string, oaFunc func(name string) (*dynatracev1beta1.DynaKube, error)) error {
 	logger := r.logger.WithValues("node", node)
 
 	nodeInfo, err := c.Get(node)
@@ -290,33 +378,70 @@ func (r *ReconcileNodes) removeNode(c *Cache, node string, oaFunc func(name stri
 			return err
 		}
 
-		err = r.markForTermination(c, oa, nodeInfo.IPAddress, node)
+		err = r.markForTermination(ctx, c, oa, nodeInfo.IPAddress, node, terminationNotice{})
 		if err != nil {
 			return err
 		}
+
+		if err = r.sendRemovalEvent(ctx, oa, node, nodeInfo); err != nil {
+			return err
+		}
 	}
 
 	c.Delete(node)
 	return nil
 }
 
-func (r *ReconcileNodes) updateNode(c *Cache, nodeName string) error {
+func (r *ReconcileNodes) updateNode(ctx context.Context, c *Cache, nodeName string) error {
 	node := &corev1.Node{}
-	err := r.client.Get(context.TODO(), client.ObjectKey{Name: nodeName}, node)
+	err := r.client.Get(ctx, client.ObjectKey{Name: nodeName}, node)
 	if err != nil {
 		return err
 	}
 
-	if !isUnschedulable(node) {
-		return nil
+	if r.isUnschedulable(node) {
+		if err = r.reconcileUnschedulableNode(ctx, node, c); err != nil {
+			return err
+		}
 	}
 
-	return r.reconcileUnschedulableNode(node, c)
+	return r.reconcileLifecycle(ctx, c, node)
 }
 
-func (r *ReconcileNodes) sendMarkedForTermination(dk *dynatracev1alpha1.DynaKube, nodeIP string, lastSeen time.Time) error {
+func (r *ReconcileNodes) sendMarkedForTermination(ctx context.Context, dk *dynatracev1beta1.DynaKube, nodeIP string, lastSeen time.Time) error {
+	ts := toMillis(lastSeen.Add(-10 * time.Minute))
+	return r.sendEvents(ctx, dk, nodeIP, &dtclient.EventData{
+		EventType:     dtclient.MarkedForTerminationEvent,
+		Source:        eventSource,
+		Description:   "Kubernetes node cordoned. Node might be drained or terminated.",
+		StartInMillis: ts,
+		EndInMillis:   ts,
+	})
+}
+
+// sendTerminationNotice marks the host for termination at the time the node received the notice, or now if unknown.
+func (r *ReconcileNodes) sendTerminationNotice(ctx context.Context, dk *dynatracev1beta1.DynaKube, nodeIP string, notice terminationNotice) error {
+	ts := notice.time
+	if now := time.Now().UTC(); ts.IsZero() || ts.After(now) {
+		ts = now
+	}
+	return r.sendEvents(ctx, dk, nodeIP, &dtclient.EventData{
+		EventType:     dtclient.MarkedForTerminationEvent,
+		Source:        eventSource,
+		Description:   "Kubernetes node received a termination notice: " + notice.reason,
+		StartInMillis: toMillis(ts),
+		EndInMillis:   toMillis(ts),
+	})
+}
+
+// sendEvents sends the events to Dynatrace, attached to the host with the IP address.
+func (r *ReconcileNodes) sendEvents(ctx context.Context, dk *dynatracev1beta1.DynaKube, nodeIP string, events ...*dtclient.EventData) error {
+	if until, ok := r.rateLimitedUntil[dk.Name]; ok && time.Now().Before(until) {
+		return fmt.Errorf("requests to Dynatrace API for DynaKube %s are rate limited until %s", dk.Name, until.Format(time.RFC3339))
+	}
+
 	var secret corev1.Secret
-	if err := r.client.Get(context.TODO(), client.ObjectKey{Name: dk.Tokens(), Namespace: dk.Namespace}, &secret); err != nil {
+	if err := r.client.Get(ctx, client.ObjectKey{Name: dk.Tokens(), Namespace: dk.Namespace}, &secret); err != nil {
 		r.logger.Error(err, "Failed to query for tokens")
 	}
 
@@ -325,28 +450,45 @@ func (r *ReconcileNodes) sendMarkedForTermination(dk *dynatracev1alpha1.DynaKube
 		return err
 	}
 
-	entityID, err := dtc.GetEntityIDForIP(nodeIP)
-	if err != nil {
-		r.logger.Info("failed to send mark for termination event",
+	entityID, err := dtc.GetEntityIDForIP(ctx, nodeIP)
+	if r.postponeIfRateLimited(dk, err) {
+		return err
+	} else if err != nil {
+		r.logger.Info("failed to send events",
 			"reason", "failed to determine entity id", "dynakube", dk.Name, "nodeIP", nodeIP, "cause", err)
 
 		return nil
 	}
 
-	ts := uint64(lastSeen.Add(-10*time.Minute).UnixNano()) / uint64(time.Millisecond)
-	return dtc.SendEvent(&dtclient.EventData{
-		EventType:     dtclient.MarkedForTerminationEvent,
-		Source:        "OneAgent Operator",
-		Description:   "Kubernetes node cordoned. Node might be drained or terminated.",
-		StartInMillis: ts,
-		EndInMillis:   ts,
-		AttachRules: dtclient.EventDataAttachRules{
+	for _, event := range events {
+		event.AttachRules = dtclient.EventDataAttachRules{
 			EntityIDs: []string{entityID},
-		},
-	})
+		}
+		if err = dtc.SendEvent(ctx, event); err != nil {
+			r.postponeIfRateLimited(dk, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// postponeIfRateLimited returns true and holds off further requests for the DynaKube if err was caused by the
+// Dynatrace API rate limiting requests.
+func (r *ReconcileNodes) postponeIfRateLimited(dk *dynatracev1beta1.DynaKube, err error) bool {
+	retryAfter, ok := dtclient.RetryAfter(err)
+	if !ok {
+		return false
+	}
+
+	if r.rateLimitedUntil == nil {
+		r.rateLimitedUntil = map[string]time.Time{}
+	}
+	r.rateLimitedUntil[dk.Name] = time.Now().Add(retryAfter)
+	r.logger.Info("Request limit for Dynatrace API reached! Postponing requests", "dynakube", dk.Name, "retryAfter", retryAfter)
+	return true
 }
 
-func (r *ReconcileNodes) reconcileUnschedulableNode(node *corev1.Node, c *Cache) error {
+func (r *ReconcileNodes) reconcileUnschedulableNode(ctx context.Context, node *corev1.Node, c *Cache) error {
 	oneAgent, err := r.determineOneAgentForNode(node.Name)
 	if err != nil {
 		return err
@@ -374,43 +516,55 @@ func (r *ReconcileNodes) reconcileUnschedulableNode(node *corev1.Node, c *Cache)
 		}
 	}
 
-	return r.markForTermination(c, oneAgent, instance.IPAddress, node.Name)
+	notice, _ := r.signals.notice(node)
+	return r.markForTermination(ctx, c, oneAgent, instance.IPAddress, node.Name, notice)
 }
 
-func (r *ReconcileNodes) markForTermination(c *Cache, dk *dynatracev1alpha1.DynaKube,
-	ipAddress string, nodeName string) error {
+// markForTermination notifies Dynatrace that the host is going away, so no host-unavailable alerts are raised. Nodes
+// with a termination notice, e.g. reclaimed spot instances, are marked at the time of the notice.
+func (r *ReconcileNodes) markForTermination(ctx context.Context, c *Cache, dk *dynatracev1beta1.DynaKube,
+	ipAddress string, nodeName string, notice terminationNotice) error {
 	cachedNode, err := c.Get(nodeName)
 	if err != nil {
 		return err
 	}
 
-	if !isMarkableForTermination(&cachedNode) {
+	// A termination notice is sent even if the node has been marked less than an hour ago because it was cordoned, as
+	// it tells when the node is actually terminated.
+	isNotice := isTerminationNotice(notice.reason)
+	if !isMarkableForTermination(&cachedNode) && (!isNotice || isTerminationNotice(cachedNode.MarkedReason)) {
 		return nil
 	}
 
-	if err = updateLastMarkedForTerminationTimestamp(c, &cachedNode, nodeName); err != nil {
+	if isNotice {
+		r.logger.Info("sending termination notice event to dynatrace server", "dynakube", dk.Name, "ip", ipAddress,
+			"node", nodeName, "reason", notice.reason)
+
+		err = r.sendTerminationNotice(ctx, dk, ipAddress, notice)
+	} else {
+		r.logger.Info("sending mark for termination event to dynatrace server", "dynakube", dk.Name, "ip", ipAddress,
+			"node", nodeName)
+
+		err = r.sendMarkedForTermination(ctx, dk, ipAddress, cachedNode.LastSeen)
+	}
+	if err != nil {
 		return err
 	}
 
-	r.logger.Info("sending mark for termination event to dynatrace server", "dynakube", dk.Name, "ip", ipAddress,
-		"node", nodeName)
-
-	return r.sendMarkedForTermination(dk, ipAddress, cachedNode.LastSeen)
+	// The node is only remembered as marked once the event has been sent, so failed events are retried
+	cachedNode.MarkedReason = notice.reason
+	return updateLastMarkedForTerminationTimestamp(c, &cachedNode, nodeName)
 }
 
-func isUnschedulable(node *corev1.Node) bool {
-	return node.Spec.Unschedulable || hasUnschedulableTaint(node)
+// isUnschedulable returns true if the node has been cordoned or is about to be terminated.
+func (r *ReconcileNodes) isUnschedulable(node *corev1.Node) bool {
+	_, terminating := r.signals.reason(node)
+	return node.Spec.Unschedulable || terminating
 }
 
-func hasUnschedulableTaint(node *corev1.Node) bool {
-	for _, taint := range node.Spec.Taints {
-		for _, unschedulableTaint := range unschedulableTaints {
-			if taint.Key == unschedulableTaint {
-				return true
-			}
-		}
-	}
-	return false
+// isTerminationNotice returns true if the reason stands for a notice that the node is about to be terminated.
+func isTerminationNotice(reason string) bool {
+	return reason == reasonSpotTermination || reason == reasonNodeTermination
 }
 
 // isMarkableForTermination checks if the timestamp from last mark is at least one hour old
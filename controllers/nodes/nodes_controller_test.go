@@ -2,11 +2,13 @@ package nodes
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
@@ -16,14 +18,14 @@ import (
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const testNamespace = "dynatrace"
 
-var testCacheKey = client.ObjectKey{Name: cacheName, Namespace: testNamespace}
-
 func TestNodesReconciler_CreateCache(t *testing.T) {
 	fakeClient := createDefaultFakeClient()
 
@@ -32,11 +34,10 @@ func TestNodesReconciler_CreateCache(t *testing.T) {
 
 	ctrl := createDefaultReconciler(fakeClient, dtClient)
 
-	require.NoError(t, ctrl.reconcileAll())
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
 
-	var cm corev1.ConfigMap
-	require.NoError(t, fakeClient.Get(context.TODO(), testCacheKey, &cm))
-	nodesCache := &Cache{Obj: &cm}
+	nodesCache, err := ctrl.getCache()
+	require.NoError(t, err)
 
 	if info, err := nodesCache.Get("node1"); assert.NoError(t, err) {
 		assert.Equal(t, "1.2.3.4", info.IPAddress)
@@ -57,14 +58,13 @@ func TestNodesReconciler_DeleteNode(t *testing.T) {
 
 	ctrl := createDefaultReconciler(fakeClient, dtClient)
 
-	require.NoError(t, ctrl.reconcileAll())
-	require.NoError(t, ctrl.onDeletion("node1"))
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+	require.NoError(t, ctrl.onDeletion(context.TODO(), "node1"))
 
-	var cm corev1.ConfigMap
-	require.NoError(t, fakeClient.Get(context.TODO(), testCacheKey, &cm))
-	nodesCache := &Cache{Obj: &cm}
+	nodesCache, err := ctrl.getCache()
+	require.NoError(t, err)
 
-	_, err := nodesCache.Get("node1")
+	_, err = nodesCache.Get("node1")
 	assert.Equal(t, err, ErrNotFound)
 
 	if info, err := nodesCache.Get("node2"); assert.NoError(t, err) {
@@ -81,22 +81,21 @@ func TestNodesReconciler_NodeNotFound(t *testing.T) {
 
 	ctrl := createDefaultReconciler(fakeClient, dtClient)
 
-	require.NoError(t, ctrl.reconcileAll())
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
 	var node2 corev1.Node
 	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "node2"}, &node2))
 	require.NoError(t, fakeClient.Delete(context.TODO(), &node2))
-	require.NoError(t, ctrl.reconcileAll())
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
 
-	var cm corev1.ConfigMap
-	require.NoError(t, fakeClient.Get(context.TODO(), testCacheKey, &cm))
-	nodesCache := &Cache{Obj: &cm}
+	nodesCache, err := ctrl.getCache()
+	require.NoError(t, err)
 
 	if info, err := nodesCache.Get("node1"); assert.NoError(t, err) {
 		assert.Equal(t, "1.2.3.4", info.IPAddress)
 		assert.Equal(t, "oneagent1", info.Instance)
 	}
 
-	_, err := nodesCache.Get("node2")
+	_, err = nodesCache.Get("node2")
 	assert.Equal(t, err, ErrNotFound)
 }
 
@@ -118,11 +117,11 @@ func TestNodeReconciler_NodeHasTaint(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Reconcile all to build cache
-	err = ctrl.reconcileAll()
+	err = ctrl.reconcileAll(context.TODO())
 	assert.NoError(t, err)
 
 	// Execute on update which triggers mark for termination
-	err = ctrl.onUpdate("node1")
+	err = ctrl.onUpdate(context.TODO(), "node1")
 	assert.NoError(t, err)
 
 	// Get node from cache
@@ -140,14 +139,115 @@ func TestNodeReconciler_NodeHasTaint(t *testing.T) {
 	assert.True(t, node.LastMarkedForTermination.Add(time.Minute).After(now))
 }
 
+func TestNodeReconciler_RetryMarkForTermination(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	var events []dtclient.EventData
+	dtClient := &dtclient.MockDynatraceClient{}
+	dtClient.On("GetEntityIDForIP", "1.2.3.4").Return("HOST-42", nil)
+	dtClient.On("SendEvent", mock.MatchedBy(func(e *dtclient.EventData) bool {
+		return e.EventType == dtclient.MarkedForTerminationEvent
+	})).Return(errors.New("connection reset")).Once()
+	dtClient.On("SendEvent", mock.Anything).Run(func(args mock.Arguments) {
+		events = append(events, *args.Get(0).(*dtclient.EventData))
+	}).Return(nil)
+	ctrl := createDefaultReconciler(fakeClient, dtClient)
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) {
+		node.Spec.Taints = []corev1.Taint{{Key: "ToBeDeletedByClusterAutoscaler"}}
+	})
+	assert.Error(t, ctrl.onUpdate(context.TODO(), "node1"))
+
+	// The failed event isn't recorded as sent
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	node, err := c.Get("node1")
+	require.NoError(t, err)
+	assert.True(t, node.LastMarkedForTermination.IsZero())
+	assert.Empty(t, node.MarkedReason)
+
+	// The next reconcile sends the event again
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	var marked int
+	for _, event := range events {
+		if event.EventType == dtclient.MarkedForTerminationEvent {
+			marked++
+		}
+	}
+	assert.Equal(t, 1, marked)
+
+	c, err = ctrl.getCache()
+	require.NoError(t, err)
+	node, err = c.Get("node1")
+	require.NoError(t, err)
+	assert.False(t, node.LastMarkedForTermination.IsZero())
+}
+
+func TestNodeReconciler_RateLimited(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	dtClient := &dtclient.MockDynatraceClient{}
+	dtClient.On("GetEntityIDForIP", "1.2.3.4").
+		Return("", dtclient.ServerError{Code: http.StatusTooManyRequests, RetryAfter: time.Hour}).Once()
+	ctrl := createDefaultReconciler(fakeClient, dtClient)
+
+	var dk dynatracev1beta1.DynaKube
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "oneagent1", Namespace: testNamespace}, &dk))
+
+	err := ctrl.sendMarkedForTermination(context.TODO(), &dk, "1.2.3.4", time.Now())
+	assert.Error(t, err)
+	assert.True(t, ctrl.rateLimitedUntil["oneagent1"].After(time.Now().Add(59*time.Minute)))
+
+	// Further requests are postponed without calling the Dynatrace API
+	err = ctrl.sendMarkedForTermination(context.TODO(), &dk, "1.2.3.4", time.Now())
+	assert.Error(t, err)
+	dtClient.AssertNumberOfCalls(t, "GetEntityIDForIP", 1)
+}
+
+func TestNodesReconciler_Reconcile(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+
+	dtClient := createDTMockClient("1.2.3.4", "HOST-42")
+	defer mock.AssertExpectationsForObjects(t, dtClient)
+
+	ctrl := createDefaultReconciler(fakeClient, dtClient)
+
+	// The sweep builds the cache and is requeued
+	result, err := ctrl.Reconcile(context.TODO(), ctrl.sweepRequest())
+	require.NoError(t, err)
+	assert.Equal(t, resyncPeriod, result.RequeueAfter)
+
+	nodesCache, err := ctrl.getCache()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"node1", "node2"}, nodesCache.Keys())
+
+	// Updated nodes are kept
+	result, err = ctrl.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "node1"}})
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, result)
+
+	// Deleted nodes are marked for termination and removed
+	var node1 corev1.Node
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "node1"}, &node1))
+	require.NoError(t, fakeClient.Delete(context.TODO(), &node1))
+
+	_, err = ctrl.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "node1"}})
+	require.NoError(t, err)
+
+	nodesCache, err = ctrl.getCache()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"node2"}, nodesCache.Keys())
+}
+
 func createDefaultReconciler(fakeClient client.Client, dtClient *dtclient.MockDynatraceClient) *ReconcileNodes {
 	return &ReconcileNodes{
 		namespace:    testNamespace,
 		client:       fakeClient,
+		apiReader:    fakeClient,
 		scheme:       scheme.Scheme,
 		logger:       zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stdout)),
 		dtClientFunc: dynakube.StaticDynatraceClient(dtClient),
 		local:        true,
+		signals:      defaultTerminationSignals(),
 	}
 }
 
@@ -157,6 +257,9 @@ func createDTMockClient(ip, host string) *dtclient.MockDynatraceClient {
 	dtClient.On("SendEvent", mock.MatchedBy(func(e *dtclient.EventData) bool {
 		return e.EventType == "MARKED_FOR_TERMINATION"
 	})).Return(nil)
+	dtClient.On("SendEvent", mock.MatchedBy(func(e *dtclient.EventData) bool {
+		return e.EventType == dtclient.CustomInfoEvent || e.EventType == dtclient.CustomAnnotationEvent
+	})).Return(nil).Maybe()
 	return dtClient
 }
 
@@ -164,19 +267,19 @@ func createDefaultFakeClient() client.Client {
 	return fake.NewClient(
 		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
 		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}},
-		&dynatracev1alpha1.DynaKube{
+		&dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{Name: "oneagent1", Namespace: testNamespace},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
-					Instances: map[string]dynatracev1alpha1.OneAgentInstance{"node1": {IPAddress: "1.2.3.4"}},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					Instances: map[string]dynatracev1beta1.OneAgentInstance{"node1": {IPAddress: "1.2.3.4"}},
 				},
 			},
 		},
-		&dynatracev1alpha1.DynaKube{
+		&dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{Name: "oneagent2", Namespace: testNamespace},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
-					Instances: map[string]dynatracev1alpha1.OneAgentInstance{"node2": {IPAddress: "5.6.7.8"}},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
+					Instances: map[string]dynatracev1beta1.OneAgentInstance{"node2": {IPAddress: "5.6.7.8"}},
 				},
 			},
 		})
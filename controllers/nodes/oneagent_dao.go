@@ -4,11 +4,11 @@ import (
 	"context"
 	"os"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (r *ReconcileNodes) determineOneAgentForNode(nodeName string) (*dynatracev1alpha1.DynaKube, error) {
+func (r *ReconcileNodes) determineOneAgentForNode(nodeName string) (*dynatracev1beta1.DynaKube, error) {
 	oneAgentList, err := r.getOneAgentList()
 	if err != nil {
 		return nil, err
@@ -17,10 +17,10 @@ func (r *ReconcileNodes) determineOneAgentForNode(nodeName string) (*dynatracev1
 	return r.filterOneAgentFromList(oneAgentList, nodeName), nil
 }
 
-func (r *ReconcileNodes) getOneAgentList() (*dynatracev1alpha1.DynaKubeList, error) {
+func (r *ReconcileNodes) getOneAgentList() (*dynatracev1beta1.DynaKubeList, error) {
 	watchNamespace := os.Getenv("POD_NAMESPACE")
 
-	var oneAgentList dynatracev1alpha1.DynaKubeList
+	var oneAgentList dynatracev1beta1.DynaKubeList
 	err := r.client.List(context.TODO(), &oneAgentList, client.InNamespace(watchNamespace))
 	if err != nil {
 		return nil, err
@@ -29,8 +29,8 @@ func (r *ReconcileNodes) getOneAgentList() (*dynatracev1alpha1.DynaKubeList, err
 	return &oneAgentList, nil
 }
 
-func (r *ReconcileNodes) filterOneAgentFromList(oneAgentList *dynatracev1alpha1.DynaKubeList,
-	nodeName string) *dynatracev1alpha1.DynaKube {
+func (r *ReconcileNodes) filterOneAgentFromList(oneAgentList *dynatracev1beta1.DynaKubeList,
+	nodeName string) *dynatracev1beta1.DynaKube {
 
 	for _, oneAgent := range oneAgentList.Items {
 		items := oneAgent.Status.OneAgent.Instances
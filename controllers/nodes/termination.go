@@ -0,0 +1,164 @@
+package nodes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// envTerminationPresets lists the presets of signals for nodes about to be terminated, see terminationPresets.
+	// Defaults to cluster-autoscaler.
+	envTerminationPresets = "NODE_TERMINATION_PRESETS"
+	// envTerminationTaints lists additional taint keys of nodes about to be terminated.
+	envTerminationTaints = "NODE_TERMINATION_TAINTS"
+	// envTerminationConditions lists additional node conditions which are true for nodes about to be terminated.
+	envTerminationConditions = "NODE_TERMINATION_CONDITIONS"
+	// envTerminationAnnotations lists additional annotation keys of nodes about to be terminated.
+	envTerminationAnnotations = "NODE_TERMINATION_ANNOTATIONS"
+
+	presetClusterAutoscaler = "cluster-autoscaler"
+)
+
+// terminationSignals are the taints, conditions and annotations of nodes which are about to be terminated, with the
+// lifecycle reason they stand for.
+type terminationSignals struct {
+	taints      map[string]string
+	conditions  map[corev1.NodeConditionType]string
+	annotations map[string]string
+}
+
+// terminationPresets are the built-in signals of common autoscalers and of spot and preemptible nodes.
+var terminationPresets = map[string]terminationSignals{
+	presetClusterAutoscaler: {
+		taints: map[string]string{autoscalerTaint: reasonAutoscalerScaleDown},
+	},
+	"karpenter": {
+		taints: map[string]string{
+			"karpenter.sh/disruption": reasonAutoscalerScaleDown,
+			"karpenter.sh/disrupted":  reasonAutoscalerScaleDown,
+		},
+	},
+	"gke-spot": {
+		taints: map[string]string{"cloud.google.com/impending-node-termination": reasonSpotTermination},
+	},
+	"aks-spot": {
+		conditions: map[corev1.NodeConditionType]string{"VMEventScheduled": reasonSpotTermination},
+	},
+	"aws-node-termination-handler": {
+		taints: map[string]string{
+			"aws-node-termination-handler/spot-itn":                  reasonSpotTermination,
+			"aws-node-termination-handler/asg-lifecycle-termination": reasonNodeTermination,
+			"aws-node-termination-handler/scheduled-maintenance":     reasonNodeTermination,
+			"aws-node-termination-handler/rebalance-recommendation":  reasonSpotTermination,
+		},
+	},
+}
+
+// newTerminationSignals combines the signals of the comma-separated presets with the comma-separated taint keys,
+// condition types and annotation keys.
+func newTerminationSignals(presets string, taints string, conditions string, annotations string) (terminationSignals, error) {
+	signals := terminationSignals{
+		taints:      map[string]string{},
+		conditions:  map[corev1.NodeConditionType]string{},
+		annotations: map[string]string{},
+	}
+
+	for _, name := range splitList(presets) {
+		preset, ok := terminationPresets[name]
+		if !ok {
+			return terminationSignals{}, fmt.Errorf("unknown node termination preset '%s'", name)
+		}
+		for k, reason := range preset.taints {
+			signals.taints[k] = reason
+		}
+		for k, reason := range preset.conditions {
+			signals.conditions[k] = reason
+		}
+		for k, reason := range preset.annotations {
+			signals.annotations[k] = reason
+		}
+	}
+
+	for _, k := range splitList(taints) {
+		signals.taints[k] = reasonNodeTermination
+	}
+	for _, k := range splitList(conditions) {
+		signals.conditions[corev1.NodeConditionType(k)] = reasonNodeTermination
+	}
+	for _, k := range splitList(annotations) {
+		signals.annotations[k] = reasonNodeTermination
+	}
+	return signals, nil
+}
+
+// defaultTerminationSignals returns the signals used if nothing has been configured.
+func defaultTerminationSignals() terminationSignals {
+	signals, _ := newTerminationSignals(presetClusterAutoscaler, "", "", "")
+	return signals
+}
+
+// terminationNotice is a signal found on a node about to be terminated.
+type terminationNotice struct {
+	// reason is the lifecycle reason the signal stands for
+	reason string
+
+	// time is when the signal has been set on the node, zero if unknown
+	time time.Time
+}
+
+// notice returns the first signal found on the node, or false if the node isn't about to be terminated. The time of
+// the notice is taken from the taint or condition, or from the annotation if its value is a RFC 3339 timestamp.
+func (s terminationSignals) notice(node *corev1.Node) (terminationNotice, bool) {
+	for _, taint := range node.Spec.Taints {
+		if reason, ok := s.taints[taint.Key]; ok {
+			notice := terminationNotice{reason: reason}
+			if taint.TimeAdded != nil {
+				notice.time = taint.TimeAdded.Time
+			}
+			return notice, true
+		}
+	}
+	for _, condition := range node.Status.Conditions {
+		if reason, ok := s.conditions[condition.Type]; ok && condition.Status == corev1.ConditionTrue {
+			return terminationNotice{reason: reason, time: condition.LastTransitionTime.Time}, true
+		}
+	}
+	// Annotations are checked in the order of their keys, as they have no order on the node.
+	keys := make([]string, 0, len(s.annotations))
+	for k := range s.annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if v, ok := node.Annotations[k]; ok {
+			notice := terminationNotice{reason: s.annotations[k]}
+			if ts, err := time.Parse(time.RFC3339, v); err == nil {
+				notice.time = ts
+			}
+			return notice, true
+		}
+	}
+	return terminationNotice{}, false
+}
+
+// reason returns the lifecycle reason of the first signal found on the node, or false if the node isn't about to be
+// terminated.
+func (s terminationSignals) reason(node *corev1.Node) (string, bool) {
+	notice, ok := s.notice(node)
+	return notice.reason, ok
+}
+
+func splitList(list string) []string {
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,206 @@
+package nodes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestTerminationSignals(t *testing.T) {
+	newNode := func(taints []corev1.Taint, conditions []corev1.NodeCondition, annotations map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: annotations},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status:     corev1.NodeStatus{Conditions: conditions},
+		}
+	}
+
+	t.Run(`default signals only detect the cluster autoscaler`, func(t *testing.T) {
+		signals := defaultTerminationSignals()
+
+		reason, ok := signals.reason(newNode([]corev1.Taint{{Key: autoscalerTaint}}, nil, nil))
+		assert.True(t, ok)
+		assert.Equal(t, reasonAutoscalerScaleDown, reason)
+
+		_, ok = signals.reason(newNode([]corev1.Taint{{Key: "cloud.google.com/impending-node-termination"}}, nil, nil))
+		assert.False(t, ok)
+	})
+	t.Run(`presets and custom signals are combined`, func(t *testing.T) {
+		signals, err := newTerminationSignals("gke-spot, aks-spot,karpenter", "example.com/terminating", "Terminating", "example.com/shutdown")
+		require.NoError(t, err)
+
+		tests := []struct {
+			node   *corev1.Node
+			reason string
+		}{
+			{newNode([]corev1.Taint{{Key: "cloud.google.com/impending-node-termination"}}, nil, nil), reasonSpotTermination},
+			{newNode([]corev1.Taint{{Key: "karpenter.sh/disruption", Value: "disrupting"}}, nil, nil), reasonAutoscalerScaleDown},
+			{newNode(nil, []corev1.NodeCondition{{Type: "VMEventScheduled", Status: corev1.ConditionTrue}}, nil), reasonSpotTermination},
+			{newNode([]corev1.Taint{{Key: "example.com/terminating"}}, nil, nil), reasonNodeTermination},
+			{newNode(nil, []corev1.NodeCondition{{Type: "Terminating", Status: corev1.ConditionTrue}}, nil), reasonNodeTermination},
+			{newNode(nil, nil, map[string]string{"example.com/shutdown": ""}), reasonNodeTermination},
+		}
+		for _, test := range tests {
+			reason, ok := signals.reason(test.node)
+			assert.True(t, ok)
+			assert.Equal(t, test.reason, reason)
+		}
+
+		_, ok := signals.reason(newNode(nil, []corev1.NodeCondition{{Type: "VMEventScheduled", Status: corev1.ConditionFalse}}, nil))
+		assert.False(t, ok)
+		_, ok = signals.reason(newNode([]corev1.Taint{{Key: autoscalerTaint}}, nil, nil))
+		assert.False(t, ok)
+	})
+	t.Run(`unknown presets are rejected`, func(t *testing.T) {
+		_, err := newTerminationSignals("cluster-autoscaler,unknown", "", "", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestReconcileNodes_IsUnschedulable(t *testing.T) {
+	signals, err := newTerminationSignals("", "", "", "example.com/shutdown")
+	require.NoError(t, err)
+	r := &ReconcileNodes{signals: signals}
+
+	assert.False(t, r.isUnschedulable(&corev1.Node{}))
+	assert.True(t, r.isUnschedulable(&corev1.Node{Spec: corev1.NodeSpec{Unschedulable: true}}))
+	assert.True(t, r.isUnschedulable(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"example.com/shutdown": "true"}}}))
+
+	node := &corev1.Node{Spec: corev1.NodeSpec{Unschedulable: true}}
+	assert.Equal(t, reasonManualDrain, r.cordonReason(node))
+	node.Annotations = map[string]string{"example.com/shutdown": "true"}
+	assert.Equal(t, reasonNodeTermination, r.cordonReason(node))
+}
+
+func TestTerminationSignals_Notice(t *testing.T) {
+	signals, err := newTerminationSignals("gke-spot,aks-spot", "", "", "example.com/shutdown")
+	require.NoError(t, err)
+
+	ts := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	notice, ok := signals.notice(&corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+		{Key: "cloud.google.com/impending-node-termination", TimeAdded: &metav1.Time{Time: ts}},
+	}}})
+	assert.True(t, ok)
+	assert.Equal(t, reasonSpotTermination, notice.reason)
+	assert.True(t, ts.Equal(notice.time))
+
+	notice, ok = signals.notice(&corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+		{Type: "VMEventScheduled", Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: ts}},
+	}}})
+	assert.True(t, ok)
+	assert.True(t, ts.Equal(notice.time))
+
+	notice, ok = signals.notice(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"example.com/shutdown": ts.Format(time.RFC3339),
+	}}})
+	assert.True(t, ok)
+	assert.True(t, ts.Equal(notice.time))
+
+	notice, ok = signals.notice(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"example.com/shutdown": "true",
+	}}})
+	assert.True(t, ok)
+	assert.True(t, notice.time.IsZero())
+
+	// The first of several configured annotations found on the node is always the same one
+	signals, err = newTerminationSignals("", "", "", "example.com/shutdown,example.com/a-shutdown,example.com/z-shutdown")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		notice, ok = signals.notice(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"example.com/z-shutdown": "true",
+			"example.com/shutdown":   "true",
+			"example.com/a-shutdown": ts.Format(time.RFC3339),
+		}}})
+		assert.True(t, ok)
+		assert.True(t, ts.Equal(notice.time))
+	}
+}
+
+func TestNodeReconciler_SpotTerminationNotice(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	var events []dtclient.EventData
+	ctrl := createDefaultReconciler(fakeClient, recordEvents("1.2.3.4", &events))
+	ctrl.signals, _ = newTerminationSignals("aks-spot", "", "", "")
+
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+
+	ts := time.Now().UTC().Add(-20 * time.Second).Truncate(time.Second)
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) {
+		node.Status.Conditions = []corev1.NodeCondition{
+			{Type: "VMEventScheduled", Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: ts}},
+		}
+	})
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+
+	require.Equal(t, []string{dtclient.MarkedForTerminationEvent, phaseCordon, dtclient.CustomAnnotationEvent}, lifecycleEvents(events))
+	assert.Equal(t, toMillis(ts), events[0].StartInMillis)
+	assert.Equal(t, toMillis(ts), events[0].EndInMillis)
+	assert.Equal(t, toMillis(ts), events[1].StartInMillis)
+
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	entry, err := c.Get("node1")
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(entry.Cordoned))
+	assert.Equal(t, reasonSpotTermination, entry.Reason)
+}
+
+func TestNodeReconciler_TerminationNoticeAfterCordon(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	var events []dtclient.EventData
+	ctrl := createDefaultReconciler(fakeClient, recordEvents("1.2.3.4", &events))
+	ctrl.signals, _ = newTerminationSignals("aks-spot", "", "", "")
+
+	require.NoError(t, ctrl.reconcileAll(context.TODO()))
+
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) {
+		node.Spec.Unschedulable = true
+	})
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	require.Equal(t, dtclient.MarkedForTerminationEvent, lifecycleEvents(events)[0])
+
+	// The termination notice is sent although the node has been marked less than an hour ago
+	events = nil
+	ts := time.Now().UTC().Add(-20 * time.Second).Truncate(time.Second)
+	updateNode(t, fakeClient, "node1", func(node *corev1.Node) {
+		node.Status.Conditions = []corev1.NodeCondition{
+			{Type: "VMEventScheduled", Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: ts}},
+		}
+	})
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	require.Equal(t, []string{dtclient.MarkedForTerminationEvent}, lifecycleEvents(events))
+	assert.Equal(t, toMillis(ts), events[0].StartInMillis)
+
+	// Further updates are skipped by the one hour gate again
+	events = nil
+	require.NoError(t, ctrl.onUpdate(context.TODO(), "node1"))
+	assert.Empty(t, events)
+}
+
+func TestReconcileNodes_NodePredicate(t *testing.T) {
+	ctrl := createDefaultReconciler(createDefaultFakeClient(), &dtclient.MockDynatraceClient{})
+	pred := ctrl.nodePredicate()
+
+	schedulable := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	tainted := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: autoscalerTaint}}},
+	}
+
+	// Heartbeats of schedulable nodes are skipped
+	assert.False(t, pred.Update(event.UpdateEvent{ObjectOld: schedulable, ObjectNew: schedulable}))
+	assert.True(t, pred.Update(event.UpdateEvent{ObjectOld: schedulable, ObjectNew: tainted}))
+	assert.True(t, pred.Update(event.UpdateEvent{ObjectOld: tainted, ObjectNew: schedulable}))
+
+	assert.False(t, pred.Create(event.CreateEvent{Object: schedulable}))
+	assert.True(t, pred.Create(event.CreateEvent{Object: tainted}))
+	assert.True(t, pred.Delete(event.DeleteEvent{Object: schedulable}))
+}
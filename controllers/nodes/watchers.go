@@ -2,83 +2,52 @@ package nodes
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/meta"
-	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-// watchDeletions returns a channel where Node deletion operations will be notified.
-func (r *ReconcileNodes) watchDeletions(stop <-chan struct{}) (chan string, error) {
-	ifm, err := r.cache.GetInformer(context.TODO(), &corev1.Node{})
-	if err != nil {
-		return nil, err
-	}
-
-	// Don't close this channel and leak it on purpose to avoid panicking if the Informer sends a notification
-	// after we stop, but it's not worth it to have it synchronized.
-	chDels := make(chan string, 20)
-
-	ifm.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
-		DeleteFunc: func(obj interface{}) {
-			if o, err := meta.Accessor(obj); err == nil {
-				chDels <- o.GetName()
-			} else {
-				r.logger.Error(err, "missing Meta", "object", obj, "type", fmt.Sprintf("%T", obj))
-			}
-		},
-	})
-
-	return chDels, nil
+// sweepRequest is the request to reconcile all nodes. Nodes aren't namespaced, so it can't clash with their requests.
+func (r *ReconcileNodes) sweepRequest() reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: cacheName, Namespace: r.namespace}}
 }
 
-func (r *ReconcileNodes) watchUpdates() (chan string, error) {
-	informer, err := r.cache.GetInformer(context.TODO(), &corev1.Node{})
-	if err != nil {
-		return nil, err
-	}
-
-	chUpdates := make(chan string, 20)
-
-	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
-		UpdateFunc: r.handleUpdate(chUpdates),
+// sweepSource enqueues the first sweep once the controller has been started, later sweeps are requeued by Reconcile.
+func (r *ReconcileNodes) sweepSource() source.Source {
+	return source.Func(func(_ context.Context, _ handler.EventHandler, queue workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+		queue.Add(r.sweepRequest())
+		return nil
 	})
-
-	return chUpdates, nil
 }
 
-func (r *ReconcileNodes) handleUpdate(chUpdates chan string) func(oldObj, newObj interface{}) {
-	return func(oldObj, newObj interface{}) {
-		newMeta, err := meta.Accessor(newObj)
-		if err != nil {
-			r.logger.Error(err, "missing Meta",
-				"new object", newObj, "type", fmt.Sprintf("%T", newObj))
-			return
-		}
-
-		chUpdates <- newMeta.GetName()
+// nodePredicate passes deletions and changes of nodes which are or have been unschedulable, e.g. cordoned or with a
+// termination notice. Other updates, mostly status heartbeats, are skipped so notices aren't queued behind them, and
+// are handled by the sweep.
+func (r *ReconcileNodes) nodePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return r.isUnschedulableObject(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return r.isUnschedulableObject(e.ObjectOld) || r.isUnschedulableObject(e.ObjectNew)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
 	}
 }
 
-// watchTicks returns a channel where tick messages will be sent periodically.
-//
-// Unlike time.Ticker, this function will also send an initial tick.
-func watchTicks(stop <-chan struct{}, d time.Duration) <-chan struct{} {
-	chAll := make(chan struct{}, 1)
-	chAll <- struct{}{}
-
-	go func() {
-		defer close(chAll)
-
-		ticker := time.NewTicker(d)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			chAll <- struct{}{}
-		}
-	}()
-
-	return chAll
+func (r *ReconcileNodes) isUnschedulableObject(obj client.Object) bool {
+	node, ok := obj.(*corev1.Node)
+	return ok && r.isUnschedulable(node)
 }
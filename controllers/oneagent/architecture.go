@@ -0,0 +1,121 @@
+package oneagent
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// archMultiArch stands for the DaemonSet deploying a multi-arch image to the nodes of all supported architectures.
+	archMultiArch = ""
+	archAMD64     = "amd64"
+
+	labelArchitecture = "operator.dynatrace.com/architecture"
+	labelNodeArch     = "kubernetes.io/arch"
+	labelNodeBetaArch = "beta.kubernetes.io/arch"
+)
+
+// getNodeArchitectures returns the supported architectures of the nodes of the node pool, in the order of
+// dynatracev1beta1.OneAgentArchitectures. Defaults to amd64 if there are no such nodes.
+func getNodeArchitectures(ctx context.Context, cl client.Reader, pool nodePool) ([]string, error) {
+	var nodes corev1.NodeList
+	if err := cl.List(ctx, &nodes, client.MatchingLabels(pool.spec.NodeSelector)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	found := map[string]bool{}
+	for i := range nodes.Items {
+		if pool.matchesNode(&nodes.Items[i]) {
+			found[nodeArchitecture(&nodes.Items[i])] = true
+		}
+	}
+
+	var archs []string
+	for _, arch := range dynatracev1beta1.OneAgentArchitectures {
+		if found[arch] {
+			archs = append(archs, arch)
+		}
+	}
+
+	if len(archs) == 0 {
+		return []string{archAMD64}, nil
+	}
+	return archs, nil
+}
+
+// nodeArchitecture returns the architecture of the node given by its labels, falling back to the beta label set by
+// older Kubernetes versions.
+func nodeArchitecture(node *corev1.Node) string {
+	if arch, ok := node.Labels[labelNodeArch]; ok {
+		return arch
+	}
+	return node.Labels[labelNodeBetaArch]
+}
+
+// daemonSetName returns the name of the DaemonSet deploying OneAgent to the nodes of the node pool with the
+// architecture. DaemonSets of the default node pool for amd64 and multi-arch images keep the name used before OneAgent
+// has been deployed per node pool and architecture.
+func daemonSetName(instanceName string, feature string, poolName string, arch string) string {
+	name := instanceName + "-" + feature
+	if poolName != "" {
+		name += "-" + poolName
+	}
+	if arch != archMultiArch && arch != archAMD64 {
+		name += "-" + arch
+	}
+	return name
+}
+
+// buildSelectorLabels returns the labels selecting the OneAgent pods of the DaemonSet for the node pool and
+// architecture. The selector of DaemonSets of the default node pool for amd64 and multi-arch images can't include them,
+// since selectors are immutable.
+func buildSelectorLabels(instanceName string, feature string, poolName string, arch string) map[string]string {
+	labels := buildLabels(instanceName, feature)
+	if poolName != "" {
+		labels[labelNodePool] = poolName
+	}
+	if arch != archMultiArch && arch != archAMD64 {
+		labels[labelArchitecture] = arch
+	}
+	return labels
+}
+
+// ListDaemonSets returns the DaemonSets deploying OneAgent for the feature, one per node architecture unless a
+// multi-arch image is used.
+func ListDaemonSets(ctx context.Context, cl client.Reader, instance *dynatracev1beta1.DynaKube, feature string) ([]appsv1.DaemonSet, error) {
+	var dsList appsv1.DaemonSetList
+	if err := cl.List(ctx, &dsList, client.InNamespace(instance.Namespace), client.MatchingLabels(buildLabels(instance.Name, feature))); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return dsList.Items, nil
+}
+
+// ListPods returns the OneAgent pods deployed for the feature on the nodes of all architectures.
+func ListPods(ctx context.Context, cl client.Reader, instance *dynatracev1beta1.DynaKube, feature string) ([]corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := cl.List(ctx, &pods, client.InNamespace(instance.Namespace), client.MatchingLabels(buildLabels(instance.Name, feature))); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pods.Items, nil
+}
+
+// DeleteDaemonSets deletes the DaemonSets deploying OneAgent for the feature.
+func DeleteDaemonSets(ctx context.Context, cl client.Client, instance *dynatracev1beta1.DynaKube, feature string) error {
+	dsList, err := ListDaemonSets(ctx, cl, instance, feature)
+	if err != nil {
+		return err
+	}
+
+	for i := range dsList {
+		if err := cl.Delete(ctx, &dsList[i]); err != nil && !k8serrors.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package oneagent
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/scheme"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newArchNode(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestGetNodeArchitectures(t *testing.T) {
+	t.Run(`defaults to amd64 without nodes`, func(t *testing.T) {
+		archs, err := getNodeArchitectures(context.TODO(), fake.NewClient(), nodePool{spec: &dynatracev1beta1.HostInjectSpec{}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{archAMD64}, archs)
+	})
+	t.Run(`supported architectures of selected nodes are returned`, func(t *testing.T) {
+		cl := fake.NewClient(
+			newArchNode("node-arm", map[string]string{labelNodeArch: "arm64", "oneagent": "true"}),
+			newArchNode("node-s390", map[string]string{labelNodeBetaArch: "s390x", "oneagent": "true"}),
+			newArchNode("node-amd", map[string]string{labelNodeArch: "amd64", "oneagent": "true"}),
+			newArchNode("node-amd-2", map[string]string{labelNodeArch: "amd64", "oneagent": "true"}),
+			newArchNode("node-ppc", map[string]string{labelNodeArch: "ppc64le"}),
+			newArchNode("node-unsupported", map[string]string{labelNodeArch: "mips", "oneagent": "true"}))
+
+		archs, err := getNodeArchitectures(context.TODO(), cl, nodePool{spec: &dynatracev1beta1.HostInjectSpec{NodeSelector: map[string]string{"oneagent": "true"}}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{archAMD64, "arm64", "s390x"}, archs)
+	})
+}
+
+func TestNewDaemonSetForCR_Architecture(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: "dynatrace"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
+			},
+		},
+	}
+	fs := instance.Spec.OneAgent.ClassicFullStack
+
+	t.Run(`amd64 keeps the name and selector`, func(t *testing.T) {
+		ds, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: fs}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+		assert.Equal(t, testName+"-"+ClassicFeature, ds.Name)
+		assert.Equal(t, buildLabels(testName, ClassicFeature), ds.Spec.Selector.MatchLabels)
+		assert.Equal(t, archAMD64, ds.Spec.Template.Labels[labelArchitecture])
+		assert.Equal(t, []string{archAMD64}, nodeAffinityArchitectures(ds))
+	})
+	t.Run(`other architectures get their own daemonset and installer`, func(t *testing.T) {
+		ds, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: fs}, testClusterID, ClassicFeature, "arm64")
+		require.NoError(t, err)
+		assert.Equal(t, testName+"-"+ClassicFeature+"-arm64", ds.Name)
+		assert.Equal(t, "arm64", ds.Spec.Selector.MatchLabels[labelArchitecture])
+		assert.Equal(t, []string{"arm64"}, nodeAffinityArchitectures(ds))
+
+		for _, ev := range ds.Spec.Template.Spec.Containers[0].Env {
+			if ev.Name == "ONEAGENT_INSTALLER_SCRIPT_URL" {
+				assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/latest?arch=arm&flavor=default", ev.Value)
+			}
+		}
+	})
+	t.Run(`multi-arch daemonset is deployed to all supported architectures`, func(t *testing.T) {
+		ds, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: fs}, testClusterID, ClassicFeature, archMultiArch)
+		require.NoError(t, err)
+		assert.Equal(t, testName+"-"+ClassicFeature, ds.Name)
+		assert.NotContains(t, ds.Spec.Template.Labels, labelArchitecture)
+		assert.Equal(t, dynatracev1beta1.OneAgentArchitectures, nodeAffinityArchitectures(ds))
+	})
+	t.Run(`installer per architecture`, func(t *testing.T) {
+		assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/latest?arch=ppcle&flavor=default", installerScriptURL(instance, "ppc64le"))
+		assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/latest?arch=s390&flavor=default", installerScriptURL(instance, "s390x"))
+		assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/latest?arch=x86&flavor=default", installerScriptURL(instance, archMultiArch))
+	})
+}
+
+func nodeAffinityArchitectures(ds *appsv1.DaemonSet) []string {
+	term := ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[1]
+	return term.MatchExpressions[0].Values
+}
+
+func TestReconcileRollout_Architectures(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: "dynatrace"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
+			},
+		},
+	}
+	cl := fake.NewClient(
+		sampleKubeSystemNS,
+		newArchNode("node-amd", map[string]string{labelNodeArch: "amd64"}),
+		newArchNode("node-arm", map[string]string{labelNodeArch: "arm64"}),
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      daemonSetName(testName, ClassicFeature, "", "s390x"),
+				Namespace: "dynatrace",
+				Labels:    buildSelectorLabels(testName, ClassicFeature, "", "s390x"),
+			},
+		})
+	reconciler := &ReconcileOneAgent{
+		client:    cl,
+		apiReader: cl,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+		logger:    consoleLogger,
+		fullStack: instance.Spec.OneAgent.ClassicFullStack,
+		feature:   ClassicFeature,
+		instance:  instance,
+	}
+
+	_, err := reconciler.reconcileRollout(context.TODO(), &utils.Reconciliation{Log: consoleLogger, Instance: instance})
+	require.NoError(t, err)
+
+	dsList, err := ListDaemonSets(context.TODO(), cl, instance, ClassicFeature)
+	require.NoError(t, err)
+
+	var names []string
+	for _, ds := range dsList {
+		names = append(names, ds.Name)
+	}
+	assert.ElementsMatch(t, []string{testName + "-classic", testName + "-classic-arm64"}, names)
+
+	err = cl.Get(context.TODO(), client.ObjectKey{Name: testName + "-classic-s390x", Namespace: "dynatrace"}, &appsv1.DaemonSet{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestGetArchitectureStatuses(t *testing.T) {
+	newPod := func(name, node, daemonSet string) corev1.Pod {
+		controller := true
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: daemonSet, Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+	pods := []corev1.Pod{
+		newPod("pod-1", "node-1", testName+"-classic"),
+		newPod("pod-2", "node-2", testName+"-classic"),
+		newPod("pod-3", "node-3", testName+"-classic-arm64"),
+	}
+	instanceStatuses := map[string]dynatracev1beta1.OneAgentInstance{
+		"node-1": {Architecture: "amd64", Ready: true},
+		"node-2": {Architecture: "amd64"},
+		"node-3": {Architecture: "arm64", Ready: true},
+	}
+
+	assert.Equal(t, map[string]dynatracev1beta1.OneAgentArchitectureStatus{
+		"amd64": {DaemonSet: testName + "-classic", Pods: 2, ReadyPods: 1},
+		"arm64": {DaemonSet: testName + "-classic-arm64", Pods: 1, ReadyPods: 1},
+	}, getArchitectureStatuses(pods, instanceStatuses))
+}
@@ -2,16 +2,17 @@ package oneagent
 
 import (
 	"fmt"
+	"sort"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/deploymentmetadata"
 	"github.com/Dynatrace/dynatrace-operator/version"
 )
 
-func prepareArgs(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.FullStackSpec, feature string, clusterID string) []string {
+func prepareArgs(instance *dynatracev1beta1.DynaKube, fs *dynatracev1beta1.HostInjectSpec, feature string, clusterID string) []string {
 	args := fs.Args
-	if instance.Spec.Proxy != nil && (instance.Spec.Proxy.ValueFrom != "" || instance.Spec.Proxy.Value != "") {
-		args = append(args, "--set-proxy=$(https_proxy)")
+	if instance.CommunicationProxy() != nil {
+		args = append(args, "--set-proxy=$(DT_PROXY)")
 	}
 
 	if instance.Spec.NetworkZone != "" {
@@ -24,9 +25,37 @@ func prepareArgs(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.Ful
 		args = append(args, "--set-host-id-source=auto")
 	}
 
+	args = append(args, prepareHostConfigArgs(fs.HostConfig)...)
 	args = append(args, "--set-host-property=OperatorVersion="+version.Version)
 
 	metadata := deploymentmetadata.NewDeploymentMetadata(clusterID)
 	args = append(args, metadata.AsArgs()...)
 	return args
 }
+
+// prepareHostConfigArgs returns the installer and oneagentctl arguments setting the host group, tags, properties and
+// monitoring mode. Host properties are sorted by their keys, so the arguments don't change between reconciliations.
+func prepareHostConfigArgs(hc dynatracev1beta1.HostConfig) []string {
+	var args []string
+	if hc.HostGroup != "" {
+		args = append(args, "--set-host-group="+hc.HostGroup)
+	}
+
+	for _, tag := range hc.HostTags {
+		args = append(args, "--set-host-tag="+tag)
+	}
+
+	keys := make([]string, 0, len(hc.HostProperties))
+	for k := range hc.HostProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, fmt.Sprintf("--set-host-property=%s=%s", k, hc.HostProperties[k]))
+	}
+
+	if hc.MonitoringMode != "" {
+		args = append(args, fmt.Sprintf("--set-monitoring-mode=%s", hc.MonitoringMode))
+	}
+	return args
+}
@@ -1,9 +1,10 @@
 package oneagent
 
 import (
+	"strings"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/deploymentmetadata"
 	"github.com/Dynatrace/dynatrace-operator/logger"
 	"github.com/Dynatrace/dynatrace-operator/version"
@@ -22,21 +23,24 @@ const (
 
 func TestNewPodSpecForCR_Arguments(t *testing.T) {
 	log := logger.NewDTLogger()
-	instance := &dynatracev1alpha1.DynaKube{
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				Args: []string{testKey, testValue, testUID},
-			}},
-		Status: dynatracev1alpha1.DynaKubeStatus{
-			OneAgent: dynatracev1alpha1.OneAgentStatus{
-				VersionStatus: dynatracev1alpha1.VersionStatus{
+	instance := &dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					Args: []string{testKey, testValue, testUID},
+				},
+			},
+		},
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
+				VersionStatus: dynatracev1beta1.VersionStatus{
 					Version: testContainerImageVersion,
 				},
 			},
 		}}
 	metadata := deploymentmetadata.NewDeploymentMetadata(testUID)
-	fullStackSpecs := &instance.Spec.ClassicFullStack
-	podSpecs := newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID)
+	fullStackSpecs := instance.Spec.OneAgent.ClassicFullStack
+	podSpecs := newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID, archAMD64)
 	require.NotNil(t, podSpecs)
 	require.NotEmpty(t, podSpecs.Containers)
 
@@ -51,28 +55,60 @@ func TestNewPodSpecForCR_Arguments(t *testing.T) {
 	}
 
 	t.Run(`has proxy arg`, func(t *testing.T) {
-		instance.Spec.Proxy = &dynatracev1alpha1.DynaKubeProxy{Value: testValue}
-		podSpecs := newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID)
-		assert.Contains(t, podSpecs.Containers[0].Args, "--set-proxy=$(https_proxy)")
+		instance.Spec.Proxy = &dynatracev1beta1.DynaKubeProxy{Value: testValue}
+		podSpecs := newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID, archAMD64)
+		assert.Contains(t, podSpecs.Containers[0].Args, "--set-proxy=$(DT_PROXY)")
 
 		instance.Spec.Proxy = nil
-		podSpecs = newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID)
-		assert.NotContains(t, podSpecs.Containers[0].Args, "--set-proxy=$(https_proxy)")
+		podSpecs = newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID, archAMD64)
+		assert.NotContains(t, podSpecs.Containers[0].Args, "--set-proxy=$(DT_PROXY)")
 	})
 	t.Run(`has network zone arg`, func(t *testing.T) {
 		instance.Spec.NetworkZone = testValue
-		podSpecs := newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID)
+		podSpecs := newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID, archAMD64)
 		assert.Contains(t, podSpecs.Containers[0].Args, "--set-network-zone="+testValue)
 
 		instance.Spec.NetworkZone = ""
-		podSpecs = newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID)
+		podSpecs = newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID, archAMD64)
 		assert.NotContains(t, podSpecs.Containers[0].Args, "--set-network-zone="+testValue)
 	})
 	t.Run(`has webhook injection arg`, func(t *testing.T) {
-		podSpecs = newPodSpecForCR(instance, fullStackSpecs, InframonFeature, true, log, testUID)
+		podSpecs = newPodSpecForCR(instance, fullStackSpecs, InframonFeature, true, log, testUID, archAMD64)
 		assert.Contains(t, podSpecs.Containers[0].Args, "--set-host-id-source=k8s-node-name")
 
-		podSpecs = newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID)
+		podSpecs = newPodSpecForCR(instance, fullStackSpecs, ClassicFeature, true, log, testUID, archAMD64)
 		assert.Contains(t, podSpecs.Containers[0].Args, "--set-host-id-source=auto")
 	})
 }
+
+func TestPrepareArgs_HostConfig(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{}
+	fs := &dynatracev1beta1.HostInjectSpec{
+		HostConfig: dynatracev1beta1.HostConfig{
+			HostGroup:      "prod",
+			HostTags:       []string{"team=platform", "critical"},
+			HostProperties: map[string]string{"zone": "b", "owner": "platform"},
+			MonitoringMode: dynatracev1beta1.MonitoringModeInfraOnly,
+		},
+	}
+
+	args := prepareArgs(instance, fs, ClassicFeature, testUID)
+	assert.Subset(t, args, []string{
+		"--set-host-group=prod",
+		"--set-host-tag=team=platform",
+		"--set-host-tag=critical",
+		"--set-monitoring-mode=infra-only",
+	})
+
+	var properties []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--set-host-property=") {
+			properties = append(properties, arg)
+		}
+	}
+	assert.Equal(t, []string{
+		"--set-host-property=owner=platform",
+		"--set-host-property=zone=b",
+		"--set-host-property=OperatorVersion=" + version.Version,
+	}, properties)
+}
@@ -0,0 +1,128 @@
+package oneagent
+
+import (
+	"sort"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const labelNodePool = "operator.dynatrace.com/node-pool"
+
+// nodePool is a set of nodes OneAgent is deployed to with the same configuration, by a DaemonSet per node architecture.
+type nodePool struct {
+	// name is empty for the default node pool, which contains all nodes not belonging to another node pool
+	name string
+
+	// spec is the configuration of OneAgent on the nodes of the pool, with the node selector matching them
+	spec *dynatracev1beta1.HostInjectSpec
+
+	// excluded are the node selectors of the nodes matched by spec.NodeSelector which belong to other node pools
+	excluded []map[string]string
+}
+
+// getNodePools returns the default node pool and one per host config override. Nodes belong to the node pool of the
+// first override matching them, or to the default one if none matches.
+func getNodePools(fs *dynatracev1beta1.HostInjectSpec) []nodePool {
+	pools := []nodePool{{spec: fs}}
+	var excluded []map[string]string
+	for _, override := range fs.HostConfigOverrides {
+		spec := fs.DeepCopy()
+		spec.HostConfigOverrides = nil
+		spec.NodeSelector = mergeLabels(fs.NodeSelector, override.NodeSelector)
+		if override.Tolerations != nil {
+			spec.Tolerations = override.Tolerations
+		}
+		if override.Resources != nil {
+			spec.Resources = *override.Resources
+		}
+		if override.PriorityClassName != "" {
+			spec.PriorityClassName = override.PriorityClassName
+		}
+		if override.Labels != nil {
+			spec.Labels = mergeLabels(fs.Labels, override.Labels)
+		}
+		spec.HostConfig = mergeHostConfig(fs.HostConfig, override.HostConfig)
+
+		pools = append(pools, nodePool{
+			name:     override.Name,
+			spec:     spec,
+			excluded: append([]map[string]string{}, excluded...),
+		})
+		excluded = append(excluded, override.NodeSelector)
+	}
+
+	pools[0].excluded = excluded
+	return pools
+}
+
+// mergeHostConfig returns the host config with the fields set on the override replaced, and the host properties merged.
+func mergeHostConfig(hc dynatracev1beta1.HostConfig, override dynatracev1beta1.HostConfig) dynatracev1beta1.HostConfig {
+	if override.HostGroup != "" {
+		hc.HostGroup = override.HostGroup
+	}
+	if override.HostTags != nil {
+		hc.HostTags = override.HostTags
+	}
+	if override.HostProperties != nil {
+		hc.HostProperties = mergeLabels(hc.HostProperties, override.HostProperties)
+	}
+	if override.MonitoringMode != "" {
+		hc.MonitoringMode = override.MonitoringMode
+	}
+	return hc
+}
+
+// matchesNode returns true if the node is matched by the node selector of the pool, and not by one of the excluded ones.
+func (pool nodePool) matchesNode(node *corev1.Node) bool {
+	if !matchesLabels(node.Labels, pool.spec.NodeSelector) {
+		return false
+	}
+	for _, selector := range pool.excluded {
+		if matchesLabels(node.Labels, selector) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesLabels(labels map[string]string, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// excludeNodes restricts the node affinity to the nodes not matched by any of the excluded node selectors. A node isn't
+// matched by a selector if one of its labels differs, so each term is split into one term per label of the selector.
+func excludeNodes(affinity *corev1.Affinity, excluded []map[string]string) {
+	if len(excluded) == 0 {
+		return
+	}
+
+	nodeSelector := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	terms := nodeSelector.NodeSelectorTerms
+	for _, selector := range excluded {
+		keys := make([]string, 0, len(selector))
+		for k := range selector {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var split []corev1.NodeSelectorTerm
+		for _, term := range terms {
+			for _, k := range keys {
+				expressions := append(append([]corev1.NodeSelectorRequirement{}, term.MatchExpressions...), corev1.NodeSelectorRequirement{
+					Key:      k,
+					Operator: corev1.NodeSelectorOpNotIn,
+					Values:   []string{selector[k]},
+				})
+				split = append(split, corev1.NodeSelectorTerm{MatchExpressions: expressions, MatchFields: term.MatchFields})
+			}
+		}
+		terms = split
+	}
+	nodeSelector.NodeSelectorTerms = terms
+}
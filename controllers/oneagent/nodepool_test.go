@@ -0,0 +1,218 @@
+package oneagent
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/scheme"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestGetNodePools(t *testing.T) {
+	fs := &dynatracev1beta1.HostInjectSpec{
+		NodeSelector: map[string]string{"oneagent": "true"},
+		HostConfig: dynatracev1beta1.HostConfig{
+			HostGroup:      "default",
+			HostTags:       []string{"team=platform"},
+			HostProperties: map[string]string{"owner": "platform", "zone": "a"},
+		},
+		HostConfigOverrides: []dynatracev1beta1.HostConfigOverride{
+			{
+				Name:         "gpu",
+				NodeSelector: map[string]string{"gpu": "true"},
+				HostConfig: dynatracev1beta1.HostConfig{
+					HostGroup:      "gpu",
+					HostProperties: map[string]string{"zone": "b"},
+				},
+			},
+			{
+				Name:         "infra",
+				NodeSelector: map[string]string{"role": "infra"},
+				HostConfig:   dynatracev1beta1.HostConfig{MonitoringMode: dynatracev1beta1.MonitoringModeInfraOnly},
+			},
+		},
+	}
+
+	pools := getNodePools(fs)
+	require.Len(t, pools, 3)
+
+	assert.Equal(t, "", pools[0].name)
+	assert.Same(t, fs, pools[0].spec)
+	assert.Equal(t, []map[string]string{{"gpu": "true"}, {"role": "infra"}}, pools[0].excluded)
+
+	gpu := pools[1]
+	assert.Equal(t, "gpu", gpu.name)
+	assert.Empty(t, gpu.excluded)
+	assert.Empty(t, gpu.spec.HostConfigOverrides)
+	assert.Equal(t, map[string]string{"oneagent": "true", "gpu": "true"}, gpu.spec.NodeSelector)
+	assert.Equal(t, dynatracev1beta1.HostConfig{
+		HostGroup:      "gpu",
+		HostTags:       []string{"team=platform"},
+		HostProperties: map[string]string{"owner": "platform", "zone": "b"},
+	}, gpu.spec.HostConfig)
+
+	infra := pools[2]
+	assert.Equal(t, "infra", infra.name)
+	assert.Equal(t, []map[string]string{{"gpu": "true"}}, infra.excluded)
+	assert.Equal(t, "default", infra.spec.HostGroup)
+	assert.Equal(t, dynatracev1beta1.MonitoringModeInfraOnly, infra.spec.MonitoringMode)
+
+	// Overrides must not change the spec of the default node pool
+	assert.Equal(t, map[string]string{"owner": "platform", "zone": "a"}, fs.HostProperties)
+	assert.Equal(t, map[string]string{"oneagent": "true"}, fs.NodeSelector)
+
+	node := func(labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+	}
+	assert.True(t, pools[0].matchesNode(node(map[string]string{"oneagent": "true"})))
+	assert.False(t, pools[0].matchesNode(node(map[string]string{"oneagent": "true", "gpu": "true"})))
+	assert.True(t, gpu.matchesNode(node(map[string]string{"oneagent": "true", "gpu": "true", "role": "infra"})))
+	assert.False(t, infra.matchesNode(node(map[string]string{"oneagent": "true", "gpu": "true", "role": "infra"})))
+}
+
+func TestExcludeNodes(t *testing.T) {
+	archTerm := corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: labelNodeArch, Operator: corev1.NodeSelectorOpIn, Values: []string{archAMD64}},
+	}}
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{archTerm}},
+	}}
+
+	excludeNodes(affinity, []map[string]string{{"role": "infra", "gpu": "true"}})
+
+	notIn := func(key, value string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpNotIn, Values: []string{value}}
+	}
+	assert.Equal(t, []corev1.NodeSelectorTerm{
+		{MatchExpressions: []corev1.NodeSelectorRequirement{archTerm.MatchExpressions[0], notIn("gpu", "true")}},
+		{MatchExpressions: []corev1.NodeSelectorRequirement{archTerm.MatchExpressions[0], notIn("role", "infra")}},
+	}, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+}
+
+func TestReconcileRollout_NodePools(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: "dynatrace"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					HostConfig: dynatracev1beta1.HostConfig{HostGroup: "default"},
+					HostConfigOverrides: []dynatracev1beta1.HostConfigOverride{{
+						Name:         "gpu",
+						NodeSelector: map[string]string{"gpu": "true"},
+						HostConfig:   dynatracev1beta1.HostConfig{HostGroup: "gpu"},
+					}},
+				},
+			},
+		},
+	}
+	cl := fake.NewClient(
+		sampleKubeSystemNS,
+		newArchNode("node-amd", map[string]string{labelNodeArch: "amd64"}),
+		newArchNode("node-gpu", map[string]string{labelNodeArch: "arm64", "gpu": "true"}))
+	reconciler := &ReconcileOneAgent{
+		client:    cl,
+		apiReader: cl,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+		logger:    consoleLogger,
+		fullStack: instance.Spec.OneAgent.ClassicFullStack,
+		feature:   ClassicFeature,
+		instance:  instance,
+	}
+
+	_, err := reconciler.reconcileRollout(context.TODO(), &utils.Reconciliation{Log: consoleLogger, Instance: instance})
+	require.NoError(t, err)
+
+	dsList, err := ListDaemonSets(context.TODO(), cl, instance, ClassicFeature)
+	require.NoError(t, err)
+
+	args := map[string][]string{}
+	for _, ds := range dsList {
+		args[ds.Name] = ds.Spec.Template.Spec.Containers[0].Args
+	}
+	require.Len(t, args, 2)
+	assert.Contains(t, args[testName+"-classic"], "--set-host-group=default")
+	assert.Contains(t, args[testName+"-classic-gpu-arm64"], "--set-host-group=gpu")
+}
+
+func TestGetNodePools_Overrides(t *testing.T) {
+	resources := corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}
+	tolerations := []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+	fs := &dynatracev1beta1.HostInjectSpec{
+		Tolerations:       []corev1.Toleration{{Key: "default", Operator: corev1.TolerationOpExists}},
+		PriorityClassName: "default",
+		Labels:            map[string]string{"team": "platform"},
+		HostConfigOverrides: []dynatracev1beta1.HostConfigOverride{
+			{Name: "infra", NodeSelector: map[string]string{"role": "infra"}},
+			{
+				Name:         "gpu",
+				NodeSelector: map[string]string{"gpu": "true"},
+				Tolerations:  tolerations,
+				Resources:    &resources,
+				Labels:       map[string]string{"pool": "gpu"},
+				HostConfig:   dynatracev1beta1.HostConfig{HostGroup: "gpu"},
+			},
+			{Name: "system", NodeSelector: map[string]string{"role": "system"}},
+		},
+	}
+
+	pools := getNodePools(fs)
+	require.Len(t, pools, 4)
+	assert.Equal(t, []map[string]string{{"role": "infra"}, {"gpu": "true"}, {"role": "system"}}, pools[0].excluded)
+
+	gpu := pools[2]
+	assert.Equal(t, "gpu", gpu.name)
+	assert.Equal(t, []map[string]string{{"role": "infra"}}, gpu.excluded)
+	assert.Empty(t, gpu.spec.HostConfigOverrides)
+	assert.Equal(t, tolerations, gpu.spec.Tolerations)
+	assert.Equal(t, resources, gpu.spec.Resources)
+	assert.Equal(t, "default", gpu.spec.PriorityClassName)
+	assert.Equal(t, map[string]string{"team": "platform", "pool": "gpu"}, gpu.spec.Labels)
+	assert.Equal(t, "gpu", gpu.spec.HostGroup)
+
+	system := pools[3]
+	assert.Equal(t, fs.Tolerations, system.spec.Tolerations)
+	assert.Equal(t, fs.Labels, system.spec.Labels)
+	assert.Equal(t, []map[string]string{{"role": "infra"}, {"gpu": "true"}}, system.excluded)
+}
+
+func TestGetNodeGroupStatuses(t *testing.T) {
+	newPod := func(name, node, daemonSet string) corev1.Pod {
+		controller := true
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: daemonSet, Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+	pods := []corev1.Pod{
+		newPod("pod-1", "node-1", testName+"-classic"),
+		newPod("pod-2", "node-2", testName+"-classic-gpu"),
+		newPod("pod-3", "node-3", testName+"-classic-gpu-arm64"),
+	}
+	instanceStatuses := map[string]dynatracev1beta1.OneAgentInstance{
+		"node-1": {Architecture: "amd64", Ready: true},
+		"node-2": {Architecture: "amd64", NodeGroup: "gpu", Ready: true},
+		"node-3": {Architecture: "arm64", NodeGroup: "gpu"},
+	}
+
+	assert.Equal(t, map[string]dynatracev1beta1.OneAgentNodeGroupStatus{
+		"gpu": {DaemonSets: []string{testName + "-classic-gpu", testName + "-classic-gpu-arm64"}, Pods: 2, ReadyPods: 1},
+	}, getNodeGroupStatuses(pods, instanceStatuses))
+
+	archStatuses := getArchitectureStatuses(pods, instanceStatuses)
+	assert.Equal(t, testName+"-classic", archStatuses["amd64"].DaemonSet)
+	assert.Equal(t, int32(2), archStatuses["amd64"].Pods)
+	assert.Empty(t, archStatuses["arm64"].DaemonSet)
+}
@@ -10,10 +10,11 @@ import (
 	"strconv"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
 	"github.com/Dynatrace/dynatrace-operator/controllers/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
+	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -23,6 +24,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -37,14 +40,19 @@ const (
 	defaultOneAgentImage                  = "docker.io/dynatrace/oneagent:latest"
 	defaultServiceAccountName             = "dynatrace-dynakube-oneagent"
 	defaultUnprivilegedServiceAccountName = "dynatrace-dynakube-oneagent-unprivileged"
+
+	eventReasonDaemonSetCreated = "DaemonSetCreated"
+	eventReasonDaemonSetUpdated = "DaemonSetUpdated"
 )
 
 // NewOneAgentReconciler initializes a new ReconcileOneAgent instance
-func NewOneAgentReconciler(client client.Client, apiReader client.Reader, scheme *runtime.Scheme, logger logr.Logger, instance *dynatracev1alpha1.DynaKube, fullStack *dynatracev1alpha1.FullStackSpec, feature string) *ReconcileOneAgent {
+func NewOneAgentReconciler(client client.Client, apiReader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, dtc dtclient.Client, logger logr.Logger, instance *dynatracev1beta1.DynaKube, fullStack *dynatracev1beta1.HostInjectSpec, feature string) *ReconcileOneAgent {
 	return &ReconcileOneAgent{
 		client:    client,
 		apiReader: apiReader,
 		scheme:    scheme,
+		recorder:  recorder,
+		dtc:       dtc,
 		logger:    logger,
 		instance:  instance,
 		fullStack: fullStack,
@@ -59,9 +67,11 @@ type ReconcileOneAgent struct {
 	client    client.Client
 	apiReader client.Reader
 	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+	dtc       dtclient.Client
 	logger    logr.Logger
-	instance  *dynatracev1alpha1.DynaKube
-	fullStack *dynatracev1alpha1.FullStackSpec
+	instance  *dynatracev1beta1.DynaKube
+	fullStack *dynatracev1beta1.HostInjectSpec
 	feature   string
 }
 
@@ -95,19 +105,22 @@ func (r *ReconcileOneAgent) Reconcile(ctx context.Context, rec *utils.Reconcilia
 		}
 	}
 
-	if rec.IsOutdated(r.instance.Status.OneAgent.LastHostsRequestTimestamp, updInterval) {
+	// The health of the instances is taken from the pods on every reconcile, while their hosts are only looked up in
+	// Dynatrace once per update interval.
+	lookupHosts := rec.IsOutdated(r.instance.Status.OneAgent.LastHostsRequestTimestamp, updInterval)
+	if lookupHosts {
 		r.instance.Status.OneAgent.LastHostsRequestTimestamp = rec.Now.DeepCopy()
 		rec.Update(true, 5*time.Minute, "updated last host request time stamp")
+	}
 
-		upd, err = r.reconcileInstanceStatuses(ctx, r.logger, r.instance)
-		rec.Update(upd, 5*time.Minute, "Instance statuses reconciled")
-		if rec.Error(err) {
-			return false, err
-		}
+	upd, err = r.reconcileInstanceStatuses(ctx, r.logger, r.instance, lookupHosts)
+	rec.Update(upd, 5*time.Minute, "Instance statuses reconciled")
+	if rec.Error(err) {
+		return false, err
 	}
 
-	// Finally we have to determine the correct non error phase
-	_, err = r.determineOneAgentPhase(r.instance)
+	updCondition, err := r.reconcileOneAgentCondition(ctx, r.instance)
+	rec.Update(updCondition, 5*time.Minute, "OneAgent condition updated")
 	rec.Error(err)
 
 	return upd, nil
@@ -116,57 +129,100 @@ func (r *ReconcileOneAgent) Reconcile(ctx context.Context, rec *utils.Reconcilia
 func (r *ReconcileOneAgent) reconcileRollout(ctx context.Context, rec *utils.Reconciliation) (bool, error) {
 	updateCR := false
 
-	// Define a new DaemonSet object
-	dsDesired, err := r.getDesiredDaemonSet(rec)
+	// Define the new DaemonSet objects, one per node architecture
+	dsDesiredList, err := r.getDesiredDaemonSets(ctx, rec)
 	if err != nil {
-		rec.Log.Info("Failed to get desired daemonset")
+		rec.Log.Info("Failed to get desired daemonsets")
 		return false, err
 	}
 
+	desiredNames := map[string]bool{}
+	for _, dsDesired := range dsDesiredList {
+		desiredNames[dsDesired.Name] = true
+		if err := r.reconcileDaemonSet(ctx, rec, dsDesired); err != nil {
+			return false, err
+		}
+	}
+
+	// Delete the DaemonSets for architectures which aren't found on the nodes anymore
+	dsActualList, err := ListDaemonSets(ctx, r.client, rec.Instance, r.feature)
+	if err != nil {
+		return false, err
+	}
+	for i := range dsActualList {
+		if dsActual := &dsActualList[i]; !desiredNames[dsActual.Name] {
+			rec.Log.Info("Deleting stale daemonset", "name", dsActual.Name)
+			if err := r.client.Delete(ctx, dsActual); err != nil && !k8serrors.IsNotFound(err) {
+				return false, err
+			}
+		}
+	}
+
+	if rec.Instance.Status.Tokens != rec.Instance.Tokens() {
+		rec.Instance.Status.Tokens = rec.Instance.Tokens()
+		updateCR = true
+	}
+
+	return updateCR, nil
+}
+
+func (r *ReconcileOneAgent) reconcileDaemonSet(ctx context.Context, rec *utils.Reconciliation, dsDesired *appsv1.DaemonSet) error {
 	// Set OneAgent instance as the owner and controller
 	if err := controllerutil.SetControllerReference(rec.Instance, dsDesired, r.scheme); err != nil {
-		return false, err
+		return err
 	}
 
 	// Check if this DaemonSet already exists
 	dsActual := &appsv1.DaemonSet{}
-	err = r.client.Get(ctx, types.NamespacedName{Name: dsDesired.Name, Namespace: dsDesired.Namespace}, dsActual)
+	err := r.client.Get(ctx, types.NamespacedName{Name: dsDesired.Name, Namespace: dsDesired.Namespace}, dsActual)
 	if err != nil && k8serrors.IsNotFound(err) {
-		rec.Log.Info("Creating new daemonset")
+		rec.Log.Info("Creating new daemonset", "name", dsDesired.Name)
 		if err = r.client.Create(ctx, dsDesired); err != nil {
-			return false, err
+			return err
 		}
+		r.recorder.Eventf(rec.Instance, corev1.EventTypeNormal, eventReasonDaemonSetCreated, "Created DaemonSet %s", dsDesired.Name)
 	} else if err != nil {
-		return false, err
+		return err
 	} else if hasDaemonSetChanged(dsDesired, dsActual) {
-		rec.Log.Info("Updating existing daemonset")
+		rec.Log.Info("Updating existing daemonset", "name", dsDesired.Name)
 		if err = r.client.Update(ctx, dsDesired); err != nil {
-			return false, err
+			return err
 		}
+		r.recorder.Eventf(rec.Instance, corev1.EventTypeNormal, eventReasonDaemonSetUpdated, "Updated DaemonSet %s, rolling out OneAgent pods", dsDesired.Name)
 	}
-
-	if rec.Instance.Status.Tokens != rec.Instance.Tokens() {
-		rec.Instance.Status.Tokens = rec.Instance.Tokens()
-		updateCR = true
-	}
-
-	return updateCR, nil
+	return nil
 }
 
-func (r *ReconcileOneAgent) getDesiredDaemonSet(rec *utils.Reconciliation) (*appsv1.DaemonSet, error) {
+// getDesiredDaemonSets returns the DaemonSets of each node pool, one per architecture of its nodes when the installer
+// is used. Immutable images are multi-arch images, so a single DaemonSet is deployed to the nodes of all architectures.
+func (r *ReconcileOneAgent) getDesiredDaemonSets(ctx context.Context, rec *utils.Reconciliation) ([]*appsv1.DaemonSet, error) {
 	kubeSysUID, err := kubesystem.GetUID(r.apiReader)
 	if err != nil {
 		return nil, err
 	}
 
-	dsDesired, err := newDaemonSetForCR(rec.Log, rec.Instance, r.fullStack, string(kubeSysUID), r.feature)
-	if err != nil {
-		return nil, err
+	var dsDesiredList []*appsv1.DaemonSet
+	for _, pool := range getNodePools(r.fullStack) {
+		archs := []string{archMultiArch}
+		if !rec.Instance.Status.OneAgent.UseImmutableImage {
+			archs, err = getNodeArchitectures(ctx, r.client, pool)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, arch := range archs {
+			dsDesired, err := newDaemonSetForPool(rec.Log, rec.Instance, pool, string(kubeSysUID), r.feature, arch)
+			if err != nil {
+				return nil, err
+			}
+			dsDesiredList = append(dsDesiredList, dsDesired)
+		}
 	}
-	return dsDesired, nil
+	return dsDesiredList, nil
 }
 
-func (r *ReconcileOneAgent) getPods(ctx context.Context, instance *dynatracev1alpha1.DynaKube, feature string) ([]corev1.Pod, []client.ListOption, error) {
+func (r *ReconcileOneAgent) getPods(ctx context.Context, instance *dynatracev1beta1.DynaKube, feature string) ([]corev1.Pod, []client.ListOption, error) {
 	podList := &corev1.PodList{}
 	listOps := []client.ListOption{
 		client.InNamespace((*instance).GetNamespace()),
@@ -176,16 +232,21 @@ func (r *ReconcileOneAgent) getPods(ctx context.Context, instance *dynatracev1al
 	return podList.Items, listOps, err
 }
 
-func newDaemonSetForCR(logger logr.Logger, instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.FullStackSpec, clusterID string, feature string) (*appsv1.DaemonSet, error) {
+func newDaemonSetForPool(logger logr.Logger, instance *dynatracev1beta1.DynaKube, pool nodePool, clusterID string, feature string, arch string) (*appsv1.DaemonSet, error) {
+	fs := pool.spec
 	unprivileged := true
 	if ptr := fs.UseUnprivilegedMode; ptr != nil {
 		unprivileged = *ptr
 	}
 
-	name := instance.GetName() + "-" + feature
-	podSpec := newPodSpecForCR(instance, fs, feature, unprivileged, logger, clusterID)
-	selectorLabels := buildLabels(instance.GetName(), feature)
+	name := daemonSetName(instance.GetName(), feature, pool.name, arch)
+	podSpec := newPodSpecForCR(instance, fs, feature, unprivileged, logger, clusterID, arch)
+	excludeNodes(podSpec.Affinity, pool.excluded)
+	selectorLabels := buildSelectorLabels(instance.GetName(), feature, pool.name, arch)
 	mergedLabels := mergeLabels(fs.Labels, selectorLabels)
+	if arch != archMultiArch {
+		mergedLabels[labelArchitecture] = arch
+	}
 
 	maxUnavailable := intstr.FromInt(instance.FeatureOneAgentMaxUnavailable())
 
@@ -202,7 +263,7 @@ func newDaemonSetForCR(logger logr.Logger, instance *dynatracev1alpha1.DynaKube,
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: mergedLabels,
 					Annotations: map[string]string{
-						statefulset.AnnotationVersion: instance.Status.OneAgent.Version,
+						statefulset.AnnotationVersion: instance.OneAgentRolloutVersion(),
 					},
 				},
 				Spec: podSpec,
@@ -215,10 +276,25 @@ func newDaemonSetForCR(logger logr.Logger, instance *dynatracev1alpha1.DynaKube,
 		},
 	}
 
+	if instance.Status.OneAgent.Canary != nil {
+		// While a new version is rolled out to the canary nodes, pods on the other nodes are kept until it is promoted.
+		ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+	}
+
 	if unprivileged {
 		ds.Spec.Template.ObjectMeta.Annotations["container.apparmor.security.beta.kubernetes.io/dynatrace-oneagent"] = "unconfined"
 	}
 
+	if err := dynatracev1beta1.ApplyPodTemplateOverride(&ds.Spec.Template, fs.PodTemplateOverride); err != nil {
+		return nil, err
+	}
+	// The pods must keep the labels selected by the DaemonSet, and the version annotation read by canary and rollback
+	ds.Spec.Template.Labels = mergeLabels(ds.Spec.Template.Labels, selectorLabels)
+	if ds.Spec.Template.Annotations == nil {
+		ds.Spec.Template.Annotations = map[string]string{}
+	}
+	ds.Spec.Template.Annotations[statefulset.AnnotationVersion] = instance.OneAgentRolloutVersion()
+
 	dsHash, err := generateDaemonSetHash(ds)
 	if err != nil {
 		return nil, err
@@ -228,7 +304,7 @@ func newDaemonSetForCR(logger logr.Logger, instance *dynatracev1alpha1.DynaKube,
 	return ds, nil
 }
 
-func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.FullStackSpec, feature string, unprivileged bool, logger logr.Logger, clusterID string) corev1.PodSpec {
+func newPodSpecForCR(instance *dynatracev1beta1.DynaKube, fs *dynatracev1beta1.HostInjectSpec, feature string, unprivileged bool, logger logr.Logger, clusterID string, arch string) corev1.PodSpec {
 	p := corev1.PodSpec{}
 
 	sa := "dynatrace-dynakube-oneagent"
@@ -254,6 +330,10 @@ func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1
 
 	// K8s 1.18+ is expected to drop the "beta.kubernetes.io" labels in favor of "kubernetes.io" which was added on K8s 1.14.
 	// To support both older and newer K8s versions we use node affinity.
+	archs := dynatracev1beta1.OneAgentArchitectures
+	if arch != archMultiArch {
+		archs = []string{arch}
+	}
 
 	var secCtx *corev1.SecurityContext
 	if unprivileged {
@@ -291,7 +371,7 @@ func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1
 	p = corev1.PodSpec{
 		Containers: []corev1.Container{{
 			Args:            prepareArgs(instance, fs, feature, clusterID),
-			Env:             prepareEnvVars(instance, fs, feature, clusterID),
+			Env:             prepareEnvVars(instance, fs, feature, clusterID, arch),
 			Image:           "",
 			ImagePullPolicy: corev1.PullAlways,
 			Name:            "dynatrace-oneagent",
@@ -328,7 +408,7 @@ func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1
 								{
 									Key:      "beta.kubernetes.io/arch",
 									Operator: corev1.NodeSelectorOpIn,
-									Values:   []string{"amd64", "arm64"},
+									Values:   archs,
 								},
 								{
 									Key:      "beta.kubernetes.io/os",
@@ -342,7 +422,7 @@ func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1
 								{
 									Key:      "kubernetes.io/arch",
 									Operator: corev1.NodeSelectorOpIn,
-									Values:   []string{"amd64", "arm64"},
+									Values:   archs,
 								},
 								{
 									Key:      "kubernetes.io/os",
@@ -364,7 +444,7 @@ func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1
 			logger.Error(err, "failed to prepare pod spec v2")
 		}
 	} else {
-		err := preparePodSpecInstaller(&p, instance)
+		err := preparePodSpecInstaller(&p, fs)
 		if err != nil {
 			logger.Error(err, "failed to prepare pod spec v1")
 		}
@@ -373,12 +453,12 @@ func newPodSpecForCR(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1
 	return p
 }
 
-func preparePodSpecInstaller(p *corev1.PodSpec, instance *dynatracev1alpha1.DynaKube) error {
+func preparePodSpecInstaller(p *corev1.PodSpec, fs *dynatracev1beta1.HostInjectSpec) error {
 	img := "docker.io/dynatrace/oneagent:latest"
 	envVarImg := os.Getenv("RELATED_IMAGE_DYNATRACE_ONEAGENT")
 
-	if instance.Spec.OneAgent.Image != "" {
-		img = instance.Spec.OneAgent.Image
+	if fs.Image != "" {
+		img = fs.Image
 	} else if envVarImg != "" {
 		img = envVarImg
 	}
@@ -387,7 +467,7 @@ func preparePodSpecInstaller(p *corev1.PodSpec, instance *dynatracev1alpha1.Dyna
 	return nil
 }
 
-func preparePodSpecImmutableImage(p *corev1.PodSpec, instance *dynatracev1alpha1.DynaKube) error {
+func preparePodSpecImmutableImage(p *corev1.PodSpec, instance *dynatracev1beta1.DynaKube) error {
 	pullSecretName := instance.GetName() + "-pull-secret"
 	if instance.Spec.CustomPullSecret != "" {
 		pullSecretName = instance.Spec.CustomPullSecret
@@ -397,16 +477,27 @@ func preparePodSpecImmutableImage(p *corev1.PodSpec, instance *dynatracev1alpha1
 		Name: pullSecretName,
 	})
 
-	if instance.Spec.OneAgent.Image != "" {
-		p.Containers[0].Image = instance.Spec.OneAgent.Image
-		return nil
-	}
-
 	p.Containers[0].Image = instance.ImmutableOneAgentImage()
 	return nil
 }
 
-func prepareVolumes(instance *dynatracev1alpha1.DynaKube) []corev1.Volume {
+// installerScriptURL returns the URL of the OneAgent installer to download. The installer for the version rolled out is
+// downloaded, so that pods restarted during an update or after a rollback keep the version, or the latest one if no
+// version is known yet. The installer for the architecture of the nodes is downloaded, x86 if it isn't known.
+func installerScriptURL(instance *dynatracev1beta1.DynaKube, arch string) string {
+	version := "latest"
+	if v := instance.OneAgentRolloutVersion(); v != "" {
+		version = "version/" + v
+	}
+
+	installerArch := dtclient.InstallerArch(arch)
+	if installerArch == "" {
+		installerArch = dtclient.ArchX86
+	}
+	return fmt.Sprintf("%s/v1/deployment/installer/agent/unix/default/%s?arch=%s&flavor=default", instance.Spec.APIURL, version, installerArch)
+}
+
+func prepareVolumes(instance *dynatracev1beta1.DynaKube) []corev1.Volume {
 	volumes := []corev1.Volume{
 		{
 			Name: "host-root",
@@ -440,7 +531,7 @@ func prepareVolumes(instance *dynatracev1alpha1.DynaKube) []corev1.Volume {
 	return volumes
 }
 
-func prepareVolumeMounts(instance *dynatracev1alpha1.DynaKube) []corev1.VolumeMount {
+func prepareVolumeMounts(instance *dynatracev1beta1.DynaKube) []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "host-root",
@@ -458,7 +549,7 @@ func prepareVolumeMounts(instance *dynatracev1alpha1.DynaKube) []corev1.VolumeMo
 	return volumeMounts
 }
 
-func prepareEnvVars(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.FullStackSpec, feature string, clusterID string) []corev1.EnvVar {
+func prepareEnvVars(instance *dynatracev1beta1.DynaKube, fs *dynatracev1beta1.HostInjectSpec, feature string, clusterID string, arch string) []corev1.EnvVar {
 	type reservedEnvVar struct {
 		Name    string
 		Default func(ev *corev1.EnvVar)
@@ -506,7 +597,7 @@ func prepareEnvVars(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.
 			reservedEnvVar{
 				Name: "ONEAGENT_INSTALLER_SCRIPT_URL",
 				Default: func(ev *corev1.EnvVar) {
-					ev.Value = fmt.Sprintf("%s/v1/deployment/installer/agent/unix/default/latest?arch=x86&flavor=default", instance.Spec.APIURL)
+					ev.Value = installerScriptURL(instance, arch)
 				},
 			},
 			reservedEnvVar{
@@ -516,23 +607,32 @@ func prepareEnvVars(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.
 				},
 			})
 
-		if p := instance.Spec.Proxy; p != nil && (p.Value != "" || p.ValueFrom != "") {
+		if p := instance.APIProxy(); p != nil {
 			reserved = append(reserved, reservedEnvVar{
 				Name: "https_proxy",
 				Default: func(ev *corev1.EnvVar) {
-					if p.ValueFrom != "" {
-						ev.ValueFrom = &corev1.EnvVarSource{
-							SecretKeyRef: &corev1.SecretKeySelector{
-								LocalObjectReference: corev1.LocalObjectReference{Name: instance.Spec.Proxy.ValueFrom},
-								Key:                  "proxy",
-							},
-						}
-					} else {
-						p.Value = instance.Spec.Proxy.Value
-					}
+					setProxyEnvVar(ev, p)
 				},
 			})
 		}
+
+		if noProxy := instance.NoProxy(); noProxy != "" {
+			reserved = append(reserved, reservedEnvVar{
+				Name: "no_proxy",
+				Default: func(ev *corev1.EnvVar) {
+					ev.Value = noProxy
+				},
+			})
+		}
+	}
+
+	if p := instance.CommunicationProxy(); p != nil {
+		reserved = append(reserved, reservedEnvVar{
+			Name: "DT_PROXY",
+			Default: func(ev *corev1.EnvVar) {
+				setProxyEnvVar(ev, p)
+			},
+		})
 	}
 
 	reservedMap := map[string]*reservedEnvVar{}
@@ -568,6 +668,20 @@ func prepareEnvVars(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.
 	return append(env, remaining...)
 }
 
+// setProxyEnvVar sets the proxy URL either directly or from the field 'proxy' of the secret given by the proxy source.
+func setProxyEnvVar(ev *corev1.EnvVar, p *dynatracev1beta1.DynaKubeProxySource) {
+	if p.ValueFrom != "" {
+		ev.ValueFrom = &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: p.ValueFrom},
+				Key:                  "proxy",
+			},
+		}
+	} else {
+		ev.Value = p.Value
+	}
+}
+
 func hasDaemonSetChanged(a, b *appsv1.DaemonSet) bool {
 	return getTemplateHash(a) != getTemplateHash(b)
 }
@@ -594,36 +708,164 @@ func getTemplateHash(a metav1.Object) string {
 	return ""
 }
 
-func (r *ReconcileOneAgent) reconcileInstanceStatuses(ctx context.Context, logger logr.Logger, instance *dynatracev1alpha1.DynaKube) (bool, error) {
+// reconcileInstanceStatuses updates the status of the OneAgent instances from their pods. Their hosts are looked up in
+// Dynatrace if lookupHosts is set, otherwise the host info of the previous status is kept.
+func (r *ReconcileOneAgent) reconcileInstanceStatuses(ctx context.Context, logger logr.Logger, instance *dynatracev1beta1.DynaKube, lookupHosts bool) (bool, error) {
 	pods, listOpts, err := r.getPods(ctx, instance, r.feature)
 	if err != nil {
 		handlePodListError(logger, err, listOpts)
 	}
 
-	instanceStatuses, err := getInstanceStatuses(pods)
+	nodeArchs, err := r.getNodeArchitecturesByName(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	instanceStatuses, err := r.getInstanceStatuses(ctx, logger, pods, nodeArchs, instance.Status.OneAgent.Instances, lookupHosts)
 	if err != nil {
 		if instanceStatuses == nil || len(instanceStatuses) <= 0 {
 			return false, err
 		}
 	}
+	archStatuses := getArchitectureStatuses(pods, instanceStatuses)
+	nodeGroupStatuses := getNodeGroupStatuses(pods, instanceStatuses)
 
+	upd := false
 	if instance.Status.OneAgent.Instances == nil || !reflect.DeepEqual(instance.Status.OneAgent.Instances, instanceStatuses) {
 		instance.Status.OneAgent.Instances = instanceStatuses
-		return true, err
+		upd = true
+	}
+	if !reflect.DeepEqual(instance.Status.OneAgent.Architectures, archStatuses) {
+		instance.Status.OneAgent.Architectures = archStatuses
+		upd = true
+	}
+	if !reflect.DeepEqual(instance.Status.OneAgent.NodeGroups, nodeGroupStatuses) {
+		instance.Status.OneAgent.NodeGroups = nodeGroupStatuses
+		upd = true
 	}
 
-	return false, err
+	return upd, err
 }
 
-func getInstanceStatuses(pods []corev1.Pod) (map[string]dynatracev1alpha1.OneAgentInstance, error) {
-	instanceStatuses := make(map[string]dynatracev1alpha1.OneAgentInstance)
+// getNodeArchitecturesByName returns the architectures of the nodes by their names.
+func (r *ReconcileOneAgent) getNodeArchitecturesByName(ctx context.Context) (map[string]string, error) {
+	var nodes corev1.NodeList
+	if err := r.client.List(ctx, &nodes); err != nil {
+		return nil, err
+	}
 
+	nodeArchs := make(map[string]string, len(nodes.Items))
+	for i := range nodes.Items {
+		nodeArchs[nodes.Items[i].Name] = nodeArchitecture(&nodes.Items[i])
+	}
+	return nodeArchs, nil
+}
+
+// getArchitectureStatuses sums up the OneAgent pods per node architecture. Returns nil if the architectures of the nodes
+// aren't known.
+func getArchitectureStatuses(pods []corev1.Pod, instanceStatuses map[string]dynatracev1beta1.OneAgentInstance) map[string]dynatracev1beta1.OneAgentArchitectureStatus {
+	var archStatuses map[string]dynatracev1beta1.OneAgentArchitectureStatus
 	for _, pod := range pods {
-		instanceStatuses[pod.Spec.NodeName] = dynatracev1alpha1.OneAgentInstance{
-			PodName:   pod.Name,
-			IPAddress: pod.Status.HostIP,
+		instanceStatus := instanceStatuses[pod.Spec.NodeName]
+		if instanceStatus.Architecture == "" {
+			continue
+		}
+		if archStatuses == nil {
+			archStatuses = map[string]dynatracev1beta1.OneAgentArchitectureStatus{}
+		}
+
+		archStatus := archStatuses[instanceStatus.Architecture]
+		if owner := metav1.GetControllerOf(&pod); owner != nil && instanceStatus.NodeGroup == "" {
+			archStatus.DaemonSet = owner.Name
+		}
+		archStatus.Pods++
+		if instanceStatus.Ready {
+			archStatus.ReadyPods++
 		}
+		archStatuses[instanceStatus.Architecture] = archStatus
+	}
+	return archStatuses
+}
+
+// getNodeGroupStatuses sums up the OneAgent pods per node group. Returns nil if there are no pods on nodes of node groups.
+func getNodeGroupStatuses(pods []corev1.Pod, instanceStatuses map[string]dynatracev1beta1.OneAgentInstance) map[string]dynatracev1beta1.OneAgentNodeGroupStatus {
+	var nodeGroupStatuses map[string]dynatracev1beta1.OneAgentNodeGroupStatus
+	for _, pod := range pods {
+		instanceStatus := instanceStatuses[pod.Spec.NodeName]
+		if instanceStatus.NodeGroup == "" {
+			continue
+		}
+		if nodeGroupStatuses == nil {
+			nodeGroupStatuses = map[string]dynatracev1beta1.OneAgentNodeGroupStatus{}
+		}
+
+		nodeGroupStatus := nodeGroupStatuses[instanceStatus.NodeGroup]
+		if owner := metav1.GetControllerOf(&pod); owner != nil {
+			nodeGroupStatus.DaemonSets = sets.NewString(nodeGroupStatus.DaemonSets...).Insert(owner.Name).List()
+		}
+		nodeGroupStatus.Pods++
+		if instanceStatus.Ready {
+			nodeGroupStatus.ReadyPods++
+		}
+		nodeGroupStatuses[instanceStatus.NodeGroup] = nodeGroupStatus
+	}
+	return nodeGroupStatuses
+}
+
+func (r *ReconcileOneAgent) getInstanceStatuses(ctx context.Context, logger logr.Logger, pods []corev1.Pod, nodeArchs map[string]string,
+	previous map[string]dynatracev1beta1.OneAgentInstance, lookupHosts bool) (map[string]dynatracev1beta1.OneAgentInstance, error) {
+	instanceStatuses := make(map[string]dynatracev1beta1.OneAgentInstance)
+
+	for _, pod := range pods {
+		instanceStatus := dynatracev1beta1.OneAgentInstance{
+			PodName:      pod.Name,
+			IPAddress:    pod.Status.HostIP,
+			PodPhase:     pod.Status.Phase,
+			Architecture: nodeArchs[pod.Spec.NodeName],
+			NodeGroup:    pod.Labels[labelNodePool],
+		}
+
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady {
+				instanceStatus.Ready = c.Status == corev1.ConditionTrue
+				instanceStatus.LastTransitionTime = c.LastTransitionTime.DeepCopy()
+			}
+		}
+		for _, c := range pod.Status.ContainerStatuses {
+			instanceStatus.RestartCount += c.RestartCount
+		}
+
+		if lookupHosts {
+			if r.dtc != nil && pod.Status.HostIP != "" {
+				r.setHostInfo(ctx, logger, &instanceStatus)
+			}
+		} else if prev, ok := previous[pod.Spec.NodeName]; ok && prev.IPAddress == instanceStatus.IPAddress {
+			instanceStatus.EntityID = prev.EntityID
+			instanceStatus.VisibleInDynatrace = prev.VisibleInDynatrace
+			instanceStatus.Version = prev.Version
+		}
+
+		instanceStatuses[pod.Spec.NodeName] = instanceStatus
 	}
 
 	return instanceStatuses, nil
 }
+
+// setHostInfo looks up the host of the OneAgent instance in Dynatrace. Hosts which can't be found are not visible in
+// Dynatrace yet, or their OneAgent fails to connect.
+func (r *ReconcileOneAgent) setHostInfo(ctx context.Context, logger logr.Logger, instanceStatus *dynatracev1beta1.OneAgentInstance) {
+	entityID, err := r.dtc.GetEntityIDForIP(ctx, instanceStatus.IPAddress)
+	if err != nil {
+		logger.Info("host not visible in Dynatrace", "pod", instanceStatus.PodName, "ip", instanceStatus.IPAddress, "error", err.Error())
+		return
+	}
+	instanceStatus.EntityID = entityID
+	instanceStatus.VisibleInDynatrace = true
+
+	version, err := r.dtc.GetAgentVersionForIP(ctx, instanceStatus.IPAddress)
+	if err != nil {
+		logger.Info("could not determine OneAgent version of host", "pod", instanceStatus.PodName, "ip", instanceStatus.IPAddress, "error", err.Error())
+		return
+	}
+	instanceStatus.Version = version
+}
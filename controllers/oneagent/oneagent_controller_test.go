@@ -9,7 +9,8 @@ import (
 	"testing"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/logger"
@@ -17,12 +18,14 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -46,19 +49,20 @@ func TestReconcileOneAgent_ReconcileOnEmptyEnvironmentAndDNSPolicy(t *testing.T)
 	namespace := "dynatrace"
 	dkName := "dynakube"
 
-	dkSpec := dynatracev1alpha1.DynaKubeSpec{
+	dkSpec := dynatracev1beta1.DynaKubeSpec{
 		APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 		Tokens: dkName,
-		ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-			Enabled:   true,
-			DNSPolicy: corev1.DNSClusterFirstWithHostNet,
-			Labels: map[string]string{
-				"label_key": "label_value",
+		OneAgent: dynatracev1beta1.OneAgentSpec{
+			ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+				DNSPolicy: corev1.DNSClusterFirstWithHostNet,
+				Labels: map[string]string{
+					"label_key": "label_value",
+				},
 			},
 		},
 	}
 
-	dynakube := &dynatracev1alpha1.DynaKube{
+	dynakube := &dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: dkName, Namespace: namespace},
 		Spec:       dkSpec,
 	}
@@ -69,14 +73,17 @@ func TestReconcileOneAgent_ReconcileOnEmptyEnvironmentAndDNSPolicy(t *testing.T)
 
 	dtClient := &dtclient.MockDynatraceClient{}
 
+	recorder := record.NewFakeRecorder(10)
+
 	reconciler := &ReconcileOneAgent{
 		client:    fakeClient,
 		apiReader: fakeClient,
 		scheme:    scheme.Scheme,
+		recorder:  recorder,
 		logger:    consoleLogger,
 		instance:  dynakube,
 		feature:   ClassicFeature,
-		fullStack: &dynakube.Spec.ClassicFullStack,
+		fullStack: dynakube.Spec.OneAgent.ClassicFullStack,
 	}
 
 	rec := utils.Reconciliation{Log: consoleLogger, Instance: dynakube}
@@ -89,6 +96,7 @@ func TestReconcileOneAgent_ReconcileOnEmptyEnvironmentAndDNSPolicy(t *testing.T)
 	assert.Equal(t, namespace, dsActual.Namespace, "wrong namespace")
 	assert.Equal(t, dkName+"-"+reconciler.feature, dsActual.GetObjectMeta().GetName(), "wrong name")
 	assert.Equal(t, corev1.DNSClusterFirstWithHostNet, dsActual.Spec.Template.Spec.DNSPolicy, "wrong policy")
+	assert.Equal(t, "Normal DaemonSetCreated Created DaemonSet dynakube-classic", <-recorder.Events)
 	mock.AssertExpectationsForObjects(t, dtClient)
 }
 
@@ -96,26 +104,26 @@ func TestReconcile_PhaseSetCorrectly(t *testing.T) {
 	namespace := "dynatrace"
 	dkName := "dynakube"
 
-	base := dynatracev1alpha1.DynaKube{
+	base := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: dkName, Namespace: namespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 			Tokens: dkName,
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				Enabled: true,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 			},
 		},
 	}
 	meta.SetStatusCondition(&base.Status.Conditions, metav1.Condition{
-		Type:    dynatracev1alpha1.APITokenConditionType,
+		Type:    dynatracev1beta1.APITokenConditionType,
 		Status:  metav1.ConditionTrue,
-		Reason:  dynatracev1alpha1.ReasonTokenReady,
+		Reason:  dynatracev1beta1.ReasonTokenReady,
 		Message: "Ready",
 	})
 	meta.SetStatusCondition(&base.Status.Conditions, metav1.Condition{
-		Type:    dynatracev1alpha1.PaaSTokenConditionType,
+		Type:    dynatracev1beta1.PaaSTokenConditionType,
 		Status:  metav1.ConditionTrue,
-		Reason:  dynatracev1alpha1.ReasonTokenReady,
+		Reason:  dynatracev1beta1.ReasonTokenReady,
 		Message: "Ready",
 	})
 
@@ -124,13 +132,13 @@ func TestReconcile_PhaseSetCorrectly(t *testing.T) {
 
 		res := dk.Status.SetPhaseOnError(nil)
 		assert.False(t, res)
-		assert.Equal(t, dk.Status.Phase, dynatracev1alpha1.DynaKubePhaseType(""))
+		assert.Equal(t, dk.Status.Phase, dynatracev1beta1.DynaKubePhaseType(""))
 
 		res = dk.Status.SetPhaseOnError(errors.New("dummy error"))
 		assert.True(t, res)
 
 		if assert.NotNil(t, dk.Status.Phase) {
-			assert.Equal(t, dynatracev1alpha1.Error, dk.Status.Phase)
+			assert.Equal(t, dynatracev1beta1.Error, dk.Status.Phase)
 		}
 	})
 }
@@ -138,13 +146,13 @@ func TestReconcile_PhaseSetCorrectly(t *testing.T) {
 func TestReconcile_TokensSetCorrectly(t *testing.T) {
 	namespace := "dynatrace"
 	dkName := "dynakube"
-	base := dynatracev1alpha1.DynaKube{
+	base := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: dkName, Namespace: namespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 			Tokens: dkName,
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				Enabled: true,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 			},
 		},
 	}
@@ -159,8 +167,9 @@ func TestReconcile_TokensSetCorrectly(t *testing.T) {
 		client:    c,
 		apiReader: c,
 		scheme:    scheme.Scheme,
+		recorder:  &record.FakeRecorder{},
 		logger:    consoleLogger,
-		fullStack: &base.Spec.ClassicFullStack,
+		fullStack: base.Spec.OneAgent.ClassicFullStack,
 		feature:   ClassicFeature,
 		instance:  &base,
 	}
@@ -205,10 +214,11 @@ func TestReconcile_TokensSetCorrectly(t *testing.T) {
 			client:    c,
 			apiReader: c,
 			scheme:    scheme.Scheme,
+			recorder:  &record.FakeRecorder{},
 			logger:    consoleLogger,
 			instance:  &base,
 			feature:   ClassicFeature,
-			fullStack: &base.Spec.ClassicFullStack,
+			fullStack: base.Spec.OneAgent.ClassicFullStack,
 		}
 
 		// arrange
@@ -232,13 +242,13 @@ func TestReconcile_TokensSetCorrectly(t *testing.T) {
 func TestReconcile_InstancesSet(t *testing.T) {
 	namespace := "dynatrace"
 	dkName := "dynakube"
-	base := dynatracev1alpha1.DynaKube{
+	base := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: dkName, Namespace: namespace},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
 			Tokens: dkName,
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				Enabled: true,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 			},
 		},
 	}
@@ -259,9 +269,10 @@ func TestReconcile_InstancesSet(t *testing.T) {
 		client:    c,
 		apiReader: c,
 		scheme:    scheme.Scheme,
+		recorder:  &record.FakeRecorder{},
 		logger:    consoleLogger,
 		instance:  &base,
-		fullStack: &base.Spec.ClassicFullStack,
+		fullStack: base.Spec.OneAgent.ClassicFullStack,
 		feature:   ClassicFeature,
 	}
 
@@ -276,7 +287,7 @@ func TestReconcile_InstancesSet(t *testing.T) {
 		pod.Name = "oneagent-update-enabled"
 		pod.Namespace = namespace
 		pod.Labels = buildLabels(dkName, reconciler.feature)
-		pod.Spec = newPodSpecForCR(dk, &dynatracev1alpha1.FullStackSpec{}, reconciler.feature, false, consoleLogger, "cluster1")
+		pod.Spec = newPodSpecForCR(dk, &dynatracev1beta1.HostInjectSpec{}, reconciler.feature, false, consoleLogger, "cluster1", archAMD64)
 		pod.Status.HostIP = hostIP
 		dk.Status.Tokens = dk.Tokens()
 
@@ -297,7 +308,7 @@ func TestReconcile_InstancesSet(t *testing.T) {
 	t.Run("reconcileImpl Instances set, if agentUpdateDisabled is true", func(t *testing.T) {
 		dk := base.DeepCopy()
 		autoUpdate := false
-		dk.Spec.OneAgent.AutoUpdate = &autoUpdate
+		dk.Spec.OneAgent.ClassicFullStack.AutoUpdate = &autoUpdate
 		dk.Status.OneAgent.Version = oldVersion
 		pod := &corev1.Pod{
 			Status: corev1.PodStatus{
@@ -307,7 +318,7 @@ func TestReconcile_InstancesSet(t *testing.T) {
 		pod.Name = "oneagent-update-disabled"
 		pod.Namespace = namespace
 		pod.Labels = buildLabels(dkName, reconciler.feature)
-		pod.Spec = newPodSpecForCR(dk, &dynatracev1alpha1.FullStackSpec{}, reconciler.feature, false, consoleLogger, "cluster1")
+		pod.Spec = newPodSpecForCR(dk, &dynatracev1beta1.HostInjectSpec{}, reconciler.feature, false, consoleLogger, "cluster1", archAMD64)
 		pod.Status.HostIP = hostIP
 		dk.Status.Tokens = dk.Tokens()
 
@@ -317,7 +328,7 @@ func TestReconcile_InstancesSet(t *testing.T) {
 		assert.NoError(t, err)
 
 		reconciler.instance = dk
-		reconciler.fullStack = &dk.Spec.ClassicFullStack
+		reconciler.fullStack = dk.Spec.OneAgent.ClassicFullStack
 		upd, err := reconciler.Reconcile(context.TODO(), &rec)
 
 		assert.NoError(t, err)
@@ -327,6 +338,75 @@ func TestReconcile_InstancesSet(t *testing.T) {
 	})
 }
 
+func TestGetInstanceStatuses(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC))
+	newPod := func(name, node, ip string, ready corev1.ConditionStatus, restarts int32) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				Phase:  corev1.PodRunning,
+				HostIP: ip,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: ready, LastTransitionTime: transitionTime},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{{RestartCount: restarts}},
+			},
+		}
+	}
+
+	dtcMock := &dtclient.MockDynatraceClient{}
+	dtcMock.On("GetEntityIDForIP", "1.1.1.1").Return("HOST-42", nil)
+	dtcMock.On("GetAgentVersionForIP", "1.1.1.1").Return("1.215.0.20210415-123456", nil)
+	dtcMock.On("GetEntityIDForIP", "2.2.2.2").Return("", errors.New("host not found"))
+
+	reconciler := &ReconcileOneAgent{dtc: dtcMock}
+	statuses, err := reconciler.getInstanceStatuses(context.TODO(), consoleLogger, []corev1.Pod{
+		newPod("oneagent-healthy", "node-1", "1.1.1.1", corev1.ConditionTrue, 0),
+		newPod("oneagent-broken", "node-2", "2.2.2.2", corev1.ConditionFalse, 7),
+	}, map[string]string{"node-1": "amd64", "node-2": "arm64"}, nil, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, dynatracev1beta1.OneAgentInstance{
+		PodName:            "oneagent-healthy",
+		IPAddress:          "1.1.1.1",
+		Version:            "1.215.0.20210415-123456",
+		PodPhase:           corev1.PodRunning,
+		Ready:              true,
+		LastTransitionTime: &transitionTime,
+		EntityID:           "HOST-42",
+		VisibleInDynatrace: true,
+		Architecture:       "amd64",
+	}, statuses["node-1"])
+	assert.Equal(t, dynatracev1beta1.OneAgentInstance{
+		PodName:            "oneagent-broken",
+		IPAddress:          "2.2.2.2",
+		PodPhase:           corev1.PodRunning,
+		RestartCount:       7,
+		LastTransitionTime: &transitionTime,
+		Architecture:       "arm64",
+	}, statuses["node-2"])
+	mock.AssertExpectationsForObjects(t, dtcMock)
+
+	t.Run(`pod health is updated without host lookups`, func(t *testing.T) {
+		reconciler := &ReconcileOneAgent{dtc: &dtclient.MockDynatraceClient{}}
+		updated, err := reconciler.getInstanceStatuses(context.TODO(), consoleLogger, []corev1.Pod{
+			newPod("oneagent-healthy", "node-1", "1.1.1.1", corev1.ConditionFalse, 2),
+			newPod("oneagent-moved", "node-2", "3.3.3.3", corev1.ConditionTrue, 0),
+		}, map[string]string{"node-1": "amd64", "node-2": "arm64"}, statuses, false)
+		require.NoError(t, err)
+
+		assert.False(t, updated["node-1"].Ready)
+		assert.Equal(t, int32(2), updated["node-1"].RestartCount)
+		assert.Equal(t, "HOST-42", updated["node-1"].EntityID)
+		assert.True(t, updated["node-1"].VisibleInDynatrace)
+		assert.Equal(t, "1.215.0.20210415-123456", updated["node-1"].Version)
+
+		assert.True(t, updated["node-2"].Ready)
+		assert.Empty(t, updated["node-2"].EntityID, "host info isn't kept when the IP changes")
+	})
+}
+
 func NewSecret(name, namespace string, kv map[string]string) *corev1.Secret {
 	data := make(map[string][]byte)
 	for k, v := range kv {
@@ -338,65 +418,68 @@ func NewSecret(name, namespace string, kv map[string]string) *corev1.Secret {
 func TestUseImmutableImage(t *testing.T) {
 	log := logger.NewDTLogger()
 	t.Run(`if image is unset and useImmutableImage is false, default image is used`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				OneAgent:         dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{},
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
+				},
 			},
 		}
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.Equal(t, defaultOneAgentImage, podSpecs.Containers[0].Image)
 	})
 	t.Run(`if image is set and useImmutableImage is false, set image is used`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				OneAgent: dynatracev1alpha1.OneAgentSpec{
-					Image: testImage,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						Image: testImage,
+					},
 				},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{},
 			},
 		}
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.Equal(t, testImage, podSpecs.Containers[0].Image)
 	})
 	t.Run(`if image is set and useImmutableImage is true, set image is used`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				OneAgent: dynatracev1alpha1.OneAgentSpec{
-					Image: testImage,
-				},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage: true,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						Image:             testImage,
+						UseImmutableImage: true,
+					},
 				},
 			},
 		}
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.Equal(t, testImage, podSpecs.Containers[0].Image)
 	})
 	t.Run(`if image is unset and useImmutableImage is true, image is based on api url`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage: true,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.Equal(t, podSpecs.Containers[0].Image, fmt.Sprintf("%s/linux/oneagent:latest", strings.TrimPrefix(testURL, "https://")))
 
-		instance.Spec.OneAgent.Version = testValue
-		podSpecs = newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		instance.Spec.OneAgent.ClassicFullStack.Version = testValue
+		podSpecs = newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.Equal(t, podSpecs.Containers[0].Image, fmt.Sprintf("%s/linux/oneagent:%s", strings.TrimPrefix(testURL, "https://"), testValue))
 	})
@@ -404,22 +487,23 @@ func TestUseImmutableImage(t *testing.T) {
 
 func TestCustomPullSecret(t *testing.T) {
 	log := logger.NewDTLogger()
-	instance := dynatracev1alpha1.DynaKube{
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			APIURL:   testURL,
-			OneAgent: dynatracev1alpha1.OneAgentSpec{},
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				UseImmutableImage: true,
+	instance := dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					UseImmutableImage: true,
+				},
 			},
 			CustomPullSecret: testName,
 		},
-		Status: dynatracev1alpha1.DynaKubeStatus{
-			OneAgent: dynatracev1alpha1.OneAgentStatus{
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
 				UseImmutableImage: true,
 			},
 		},
 	}
-	podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+	podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 	assert.NotNil(t, podSpecs)
 	assert.NotEmpty(t, podSpecs.ImagePullSecrets)
 	assert.Equal(t, testName, podSpecs.ImagePullSecrets[0].Name)
@@ -428,21 +512,22 @@ func TestCustomPullSecret(t *testing.T) {
 func TestResources(t *testing.T) {
 	log := logger.NewDTLogger()
 	t.Run(`minimal cpu request of 100mC is set if no resources specified`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage: true,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.NotEmpty(t, podSpecs.Containers)
 
@@ -455,32 +540,33 @@ func TestResources(t *testing.T) {
 		memoryRequest := resource.NewScaledQuantity(1, 3)
 		memoryLimit := resource.NewScaledQuantity(2, 3)
 
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage: true,
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:    *cpuRequest,
-							corev1.ResourceMemory: *memoryRequest,
-						},
-						Limits: corev1.ResourceList{
-							corev1.ResourceCPU:    *cpuLimit,
-							corev1.ResourceMemory: *memoryLimit,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    *cpuRequest,
+								corev1.ResourceMemory: *memoryRequest,
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    *cpuLimit,
+								corev1.ResourceMemory: *memoryLimit,
+							},
 						},
 					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
 
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.NotNil(t, podSpecs)
 		assert.NotEmpty(t, podSpecs.Containers)
 		hasCPURequest := cpuRequest.Equal(*podSpecs.Containers[0].Resources.Requests.Cpu())
@@ -497,23 +583,24 @@ func TestResources(t *testing.T) {
 
 func TestArguments(t *testing.T) {
 	log := logger.NewDTLogger()
-	instance := dynatracev1alpha1.DynaKube{
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			APIURL:   testURL,
-			OneAgent: dynatracev1alpha1.OneAgentSpec{},
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				UseImmutableImage: true,
-				Args:              []string{testValue},
+	instance := dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					UseImmutableImage: true,
+					Args:              []string{testValue},
+				},
 			},
 		},
-		Status: dynatracev1alpha1.DynaKubeStatus{
-			OneAgent: dynatracev1alpha1.OneAgentStatus{
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
 				UseImmutableImage: true,
 			},
 		},
 	}
 
-	podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+	podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 	assert.NotNil(t, podSpecs)
 	assert.NotEmpty(t, podSpecs.Containers)
 	assert.Contains(t, podSpecs.Containers[0].Args, testValue)
@@ -522,32 +609,33 @@ func TestArguments(t *testing.T) {
 func TestEnvVars(t *testing.T) {
 	log := logger.NewDTLogger()
 	reservedVariable := "DT_K8S_NODE_NAME"
-	instance := dynatracev1alpha1.DynaKube{
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			APIURL:   testURL,
-			OneAgent: dynatracev1alpha1.OneAgentSpec{},
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				UseImmutableImage: true,
-				Env: []corev1.EnvVar{
-					{
-						Name:  testName,
-						Value: testValue,
-					},
-					{
-						Name:  reservedVariable,
-						Value: testValue,
+	instance := dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+					UseImmutableImage: true,
+					Env: []corev1.EnvVar{
+						{
+							Name:  testName,
+							Value: testValue,
+						},
+						{
+							Name:  reservedVariable,
+							Value: testValue,
+						},
 					},
 				},
 			},
 		},
-		Status: dynatracev1alpha1.DynaKubeStatus{
-			OneAgent: dynatracev1alpha1.OneAgentStatus{
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
 				UseImmutableImage: true,
 			},
 		},
 	}
 
-	podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+	podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 	assert.NotNil(t, podSpecs)
 	assert.NotEmpty(t, podSpecs.Containers)
 	assert.NotEmpty(t, podSpecs.Containers[0].Env)
@@ -555,6 +643,105 @@ func TestEnvVars(t *testing.T) {
 	assertHasEnvVar(t, reservedVariable, testValue, podSpecs.Containers[0].Env)
 }
 
+func TestProxyEnvVars(t *testing.T) {
+	newInstance := func(proxy *dynatracev1beta1.DynaKubeProxy) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				Proxy:  proxy,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
+				},
+			},
+		}
+	}
+	findEnvVar := func(envVars []corev1.EnvVar, name string) *corev1.EnvVar {
+		for i := range envVars {
+			if envVars[i].Name == name {
+				return &envVars[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run(`proxy value is used for installer and communication`, func(t *testing.T) {
+		instance := newInstance(&dynatracev1beta1.DynaKubeProxy{Value: "http://proxy:8080"})
+		envVars := prepareEnvVars(instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, testClusterID, archAMD64)
+
+		assertHasEnvVar(t, "https_proxy", "http://proxy:8080", envVars)
+		assertHasEnvVar(t, "DT_PROXY", "http://proxy:8080", envVars)
+		assert.Nil(t, findEnvVar(envVars, "no_proxy"))
+	})
+	t.Run(`separate proxies and no proxy list`, func(t *testing.T) {
+		instance := newInstance(&dynatracev1beta1.DynaKubeProxy{
+			API:           &dynatracev1beta1.DynaKubeProxySource{Value: "http://api-proxy:8080"},
+			Communication: &dynatracev1beta1.DynaKubeProxySource{ValueFrom: "communication-proxy"},
+			NoProxy:       []string{"localhost", ".cluster.local"},
+		})
+		envVars := prepareEnvVars(instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, testClusterID, archAMD64)
+
+		assertHasEnvVar(t, "https_proxy", "http://api-proxy:8080", envVars)
+		assertHasEnvVar(t, "no_proxy", "localhost,.cluster.local", envVars)
+		if ev := findEnvVar(envVars, "DT_PROXY"); assert.NotNil(t, ev) {
+			assert.Equal(t, "communication-proxy", ev.ValueFrom.SecretKeyRef.Name)
+			assert.Equal(t, "proxy", ev.ValueFrom.SecretKeyRef.Key)
+		}
+	})
+	t.Run(`immutable image only gets the communication proxy`, func(t *testing.T) {
+		instance := newInstance(&dynatracev1beta1.DynaKubeProxy{Value: "http://proxy:8080", NoProxy: []string{"localhost"}})
+		instance.Status.OneAgent.UseImmutableImage = true
+		envVars := prepareEnvVars(instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, testClusterID, archAMD64)
+
+		assertHasEnvVar(t, "DT_PROXY", "http://proxy:8080", envVars)
+		assert.Nil(t, findEnvVar(envVars, "https_proxy"))
+		assert.Nil(t, findEnvVar(envVars, "no_proxy"))
+	})
+}
+
+func TestVersionPolicy(t *testing.T) {
+	instance := dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
+			},
+		},
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
+				VersionStatus: dynatracev1beta1.VersionStatus{Version: "1.213.0.20210301-123456"},
+			},
+		},
+	}
+
+	t.Run(`latest installer is used without known version`, func(t *testing.T) {
+		instance := *instance.DeepCopy()
+		instance.Status.OneAgent.Version = ""
+		assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/latest?arch=x86&flavor=default", installerScriptURL(&instance, archAMD64))
+	})
+	t.Run(`installer for selected version is used`, func(t *testing.T) {
+		instance := *instance.DeepCopy()
+		assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/version/1.213.0.20210301-123456?arch=x86&flavor=default",
+			installerScriptURL(&instance, archAMD64))
+
+		ds, err := newDaemonSetForPool(consoleLogger, &instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+		assert.Equal(t, appsv1.DaemonSetUpdateStrategyType(""), ds.Spec.UpdateStrategy.Type)
+		assert.Equal(t, "1.213.0.20210301-123456", ds.Spec.Template.Annotations[statefulset.AnnotationVersion])
+	})
+	t.Run(`pods are only replaced on delete during canary rollout`, func(t *testing.T) {
+		instance := *instance.DeepCopy()
+		instance.Spec.OneAgent.ClassicFullStack.VersionPolicy = &dynatracev1beta1.VersionPolicySpec{}
+		instance.Status.OneAgent.Canary = &dynatracev1beta1.CanaryStatus{Version: "1.215.0.20210415-123456"}
+		assert.Equal(t, testURL+"/v1/deployment/installer/agent/unix/default/version/1.215.0.20210415-123456?arch=x86&flavor=default",
+			installerScriptURL(&instance, archAMD64))
+
+		ds, err := newDaemonSetForPool(consoleLogger, &instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+		assert.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+		assert.Equal(t, "1.215.0.20210415-123456", ds.Spec.Template.Annotations[statefulset.AnnotationVersion])
+	})
+}
+
 func assertHasEnvVar(t *testing.T, expectedName string, expectedValue string, envVars []corev1.EnvVar) {
 	hasVariable := false
 	for _, env := range envVars {
@@ -569,77 +756,81 @@ func assertHasEnvVar(t *testing.T, expectedName string, expectedValue string, en
 func TestServiceAccountName(t *testing.T) {
 	log := logger.NewDTLogger()
 	t.Run(`has default values`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage: true,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
 
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, false, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, false, log, testClusterID, archAMD64)
 		assert.Equal(t, defaultServiceAccountName, podSpecs.ServiceAccountName)
 
-		instance = dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage: true,
+		instance = dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
-		podSpecs = newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs = newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.Equal(t, defaultUnprivilegedServiceAccountName, podSpecs.ServiceAccountName)
 	})
 	t.Run(`uses custom value`, func(t *testing.T) {
-		instance := dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage:  true,
-					ServiceAccountName: testName,
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage:  true,
+						ServiceAccountName: testName,
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
-		podSpecs := newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, false, log, testClusterID)
+		podSpecs := newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, false, log, testClusterID, archAMD64)
 		assert.Equal(t, testName, podSpecs.ServiceAccountName)
 
-		instance = dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
-				APIURL:   testURL,
-				OneAgent: dynatracev1alpha1.OneAgentSpec{},
-				ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-					UseImmutableImage:  true,
-					ServiceAccountName: testName,
+		instance = dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage:  true,
+						ServiceAccountName: testName,
+					},
 				},
 			},
-			Status: dynatracev1alpha1.DynaKubeStatus{
-				OneAgent: dynatracev1alpha1.OneAgentStatus{
+			Status: dynatracev1beta1.DynaKubeStatus{
+				OneAgent: dynatracev1beta1.OneAgentStatus{
 					UseImmutableImage: true,
 				},
 			},
 		}
 
-		podSpecs = newPodSpecForCR(&instance, &instance.Spec.ClassicFullStack, ClassicFeature, true, log, testClusterID)
+		podSpecs = newPodSpecForCR(&instance, instance.Spec.OneAgent.ClassicFullStack, ClassicFeature, true, log, testClusterID, archAMD64)
 		assert.Equal(t, testName, podSpecs.ServiceAccountName)
 	})
 }
@@ -7,11 +7,9 @@ import (
 	"strings"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
-	appsv1 "k8s.io/api/apps/v1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -50,7 +48,7 @@ func getPodReadyState(p *corev1.Pod) bool {
 //
 // Return an error in the following conditions
 // - APIURL empty
-func validate(cr *dynatracev1alpha1.DynaKube) error {
+func validate(cr *dynatracev1beta1.DynaKube) error {
 	var msg []string
 	if cr.Spec.APIURL == "" {
 		msg = append(msg, ".spec.apiUrl is missing")
@@ -61,31 +59,47 @@ func validate(cr *dynatracev1alpha1.DynaKube) error {
 	return nil
 }
 
-func (r *ReconcileOneAgent) determineOneAgentPhase(instance *dynatracev1alpha1.DynaKube) (bool, error) {
-	var phaseChanged bool
-	dsActual := &appsv1.DaemonSet{}
-	instanceName := fmt.Sprintf("%s-%s", instance.Name, r.feature)
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: instanceName, Namespace: instance.Namespace}, dsActual)
-
-	if k8serrors.IsNotFound(err) {
-		return false, nil
+// reconcileOneAgentCondition sets the OneAgentReady condition from the number of ready OneAgent pods.
+func (r *ReconcileOneAgent) reconcileOneAgentCondition(ctx context.Context, instance *dynatracev1beta1.DynaKube) (bool, error) {
+	dsActualList, err := ListDaemonSets(ctx, r.client, instance, r.feature)
+	if err != nil {
+		return instance.Status.SetCondition(metav1.Condition{
+			Type:    dynatracev1beta1.OneAgentReadyConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  dynatracev1beta1.ReasonReconcileFailed,
+			Message: err.Error(),
+		}), err
 	}
 
-	if err != nil {
-		phaseChanged = instance.Status.Phase != dynatracev1alpha1.Error
-		instance.Status.Phase = dynatracev1alpha1.Error
-		return phaseChanged, err
+	if len(dsActualList) == 0 {
+		return instance.Status.SetCondition(metav1.Condition{
+			Type:    dynatracev1beta1.OneAgentReadyConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  dynatracev1beta1.ReasonNotDeployed,
+			Message: fmt.Sprintf("DaemonSet %s not found", daemonSetName(instance.Name, r.feature, "", archAMD64)),
+		}), nil
 	}
 
-	if dsActual.Status.NumberReady == dsActual.Status.CurrentNumberScheduled {
-		phaseChanged = instance.Status.Phase != dynatracev1alpha1.Running
-		instance.Status.Phase = dynatracev1alpha1.Running
-	} else {
-		phaseChanged = instance.Status.Phase != dynatracev1alpha1.Deploying
-		instance.Status.Phase = dynatracev1alpha1.Deploying
+	var desired, ready int32
+	for _, dsActual := range dsActualList {
+		desired += dsActual.Status.DesiredNumberScheduled
+		ready += dsActual.Status.NumberReady
+	}
+	if ready < desired {
+		return instance.Status.SetCondition(metav1.Condition{
+			Type:    dynatracev1beta1.OneAgentReadyConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  dynatracev1beta1.ReasonRolloutInProgress,
+			Message: fmt.Sprintf("%d of %d OneAgent pods are ready", ready, desired),
+		}), nil
 	}
 
-	return phaseChanged, nil
+	return instance.Status.SetCondition(metav1.Condition{
+		Type:    dynatracev1beta1.OneAgentReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReady,
+		Message: fmt.Sprintf("All %d OneAgent pods are ready", ready),
+	}), nil
 }
 
 func (r *ReconcileOneAgent) waitPodReadyState(pod corev1.Pod, labels map[string]string, waitSecs uint16) error {
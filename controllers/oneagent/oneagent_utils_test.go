@@ -1,14 +1,17 @@
 package oneagent
 
 import (
+	"context"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -57,7 +60,7 @@ func TestMigrationForDaemonSetWithoutAnnotation(t *testing.T) {
 
 	ds1 := &appsv1.DaemonSet{ObjectMeta: oaKey}
 
-	ds2, err := newDaemonSetForCR(consoleLogger, &dynatracev1alpha1.DynaKube{ObjectMeta: oaKey}, &dynatracev1alpha1.FullStackSpec{}, "classic", "cluster1")
+	ds2, err := newDaemonSetForPool(consoleLogger, &dynatracev1beta1.DynaKube{ObjectMeta: oaKey}, nodePool{spec: &dynatracev1beta1.HostInjectSpec{}}, "classic", "cluster1", archAMD64)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, ds2.Annotations[statefulset.AnnotationTemplateHash])
 
@@ -65,18 +68,20 @@ func TestMigrationForDaemonSetWithoutAnnotation(t *testing.T) {
 }
 
 func TestHasSpecChanged(t *testing.T) {
-	runTest := func(msg string, exp bool, mod func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube)) {
+	runTest := func(msg string, exp bool, mod func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube)) {
 		t.Run(msg, func(t *testing.T) {
 			key := metav1.ObjectMeta{Name: "my-oneagent", Namespace: "my-namespace"}
-			oldInstance := dynatracev1alpha1.DynaKube{ObjectMeta: key}
-			newInstance := dynatracev1alpha1.DynaKube{ObjectMeta: key}
+			oldInstance := dynatracev1beta1.DynaKube{ObjectMeta: key}
+			oldInstance.Spec.OneAgent.ClassicFullStack = &dynatracev1beta1.HostInjectSpec{}
+			newInstance := dynatracev1beta1.DynaKube{ObjectMeta: key}
+			newInstance.Spec.OneAgent.ClassicFullStack = &dynatracev1beta1.HostInjectSpec{}
 
 			mod(&oldInstance, &newInstance)
 
-			ds1, err := newDaemonSetForCR(consoleLogger, &oldInstance, &oldInstance.Spec.ClassicFullStack, "classic", "cluster1")
+			ds1, err := newDaemonSetForPool(consoleLogger, &oldInstance, nodePool{spec: oldInstance.Spec.OneAgent.ClassicFullStack}, "classic", "cluster1", archAMD64)
 			assert.NoError(t, err)
 
-			ds2, err := newDaemonSetForCR(consoleLogger, &newInstance, &newInstance.Spec.ClassicFullStack, "classic", "cluster1")
+			ds2, err := newDaemonSetForPool(consoleLogger, &newInstance, nodePool{spec: newInstance.Spec.OneAgent.ClassicFullStack}, "classic", "cluster1", archAMD64)
 			assert.NoError(t, err)
 
 			assert.NotEmpty(t, ds1.Annotations[statefulset.AnnotationTemplateHash])
@@ -86,72 +91,72 @@ func TestHasSpecChanged(t *testing.T) {
 		})
 	}
 
-	runTest("no changes", false, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {})
+	runTest("no changes", false, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {})
 
-	runTest("image added", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		new.Spec.OneAgent.Image = "docker.io/dynatrace/oneagent"
+	runTest("image added", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		new.Spec.OneAgent.ClassicFullStack.Image = "docker.io/dynatrace/oneagent"
 	})
 
-	runTest("image set but no change", false, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.OneAgent.Image = "docker.io/dynatrace/oneagent"
-		new.Spec.OneAgent.Image = "docker.io/dynatrace/oneagent"
+	runTest("image set but no change", false, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Image = "docker.io/dynatrace/oneagent"
+		new.Spec.OneAgent.ClassicFullStack.Image = "docker.io/dynatrace/oneagent"
 	})
 
-	runTest("image removed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.OneAgent.Image = "docker.io/dynatrace/oneagent"
+	runTest("image removed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Image = "docker.io/dynatrace/oneagent"
 	})
 
-	runTest("image changed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.OneAgent.Image = "registry.access.redhat.com/dynatrace/oneagent"
-		new.Spec.OneAgent.Image = "docker.io/dynatrace/oneagent"
+	runTest("image changed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Image = "registry.access.redhat.com/dynatrace/oneagent"
+		new.Spec.OneAgent.ClassicFullStack.Image = "docker.io/dynatrace/oneagent"
 	})
 
-	runTest("argument removed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.Args = []string{"INFRA_ONLY=1", "--set-host-property=OperatorVersion=snapshot"}
-		new.Spec.ClassicFullStack.Args = []string{"INFRA_ONLY=1"}
+	runTest("argument removed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Args = []string{"INFRA_ONLY=1", "--set-host-property=OperatorVersion=snapshot"}
+		new.Spec.OneAgent.ClassicFullStack.Args = []string{"INFRA_ONLY=1"}
 	})
 
-	runTest("argument changed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.Args = []string{"INFRA_ONLY=1"}
-		new.Spec.ClassicFullStack.Args = []string{"INFRA_ONLY=0"}
+	runTest("argument changed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Args = []string{"INFRA_ONLY=1"}
+		new.Spec.OneAgent.ClassicFullStack.Args = []string{"INFRA_ONLY=0"}
 	})
 
-	runTest("all arguments removed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.Args = []string{"INFRA_ONLY=1"}
+	runTest("all arguments removed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Args = []string{"INFRA_ONLY=1"}
 	})
 
-	runTest("resources added", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		new.Spec.ClassicFullStack.Resources = newResourceRequirements()
+	runTest("resources added", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		new.Spec.OneAgent.ClassicFullStack.Resources = newResourceRequirements()
 	})
 
-	runTest("resources removed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.Resources = newResourceRequirements()
+	runTest("resources removed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Resources = newResourceRequirements()
 	})
 
-	runTest("resources removed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.Resources = newResourceRequirements()
+	runTest("resources removed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.Resources = newResourceRequirements()
 	})
 
-	runTest("priority class added", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		new.Spec.ClassicFullStack.PriorityClassName = "class"
+	runTest("priority class added", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		new.Spec.OneAgent.ClassicFullStack.PriorityClassName = "class"
 	})
 
-	runTest("priority class removed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.PriorityClassName = "class"
+	runTest("priority class removed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.PriorityClassName = "class"
 	})
 
-	runTest("priority class set but no change", false, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.PriorityClassName = "class"
-		new.Spec.ClassicFullStack.PriorityClassName = "class"
+	runTest("priority class set but no change", false, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.PriorityClassName = "class"
+		new.Spec.OneAgent.ClassicFullStack.PriorityClassName = "class"
 	})
 
-	runTest("priority class changed", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		old.Spec.ClassicFullStack.PriorityClassName = "some class"
-		new.Spec.ClassicFullStack.PriorityClassName = "other class"
+	runTest("priority class changed", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		old.Spec.OneAgent.ClassicFullStack.PriorityClassName = "some class"
+		new.Spec.OneAgent.ClassicFullStack.PriorityClassName = "other class"
 	})
 
-	runTest("dns policy added", true, func(old *dynatracev1alpha1.DynaKube, new *dynatracev1alpha1.DynaKube) {
-		new.Spec.ClassicFullStack.DNSPolicy = corev1.DNSClusterFirst
+	runTest("dns policy added", true, func(old *dynatracev1beta1.DynaKube, new *dynatracev1beta1.DynaKube) {
+		new.Spec.OneAgent.ClassicFullStack.DNSPolicy = corev1.DNSClusterFirst
 	})
 }
 
@@ -220,11 +225,81 @@ func TestWaitPodReadyState(t *testing.T) {
 	})
 }
 
-func newOneAgent() *dynatracev1alpha1.DynaKube {
-	return &dynatracev1alpha1.DynaKube{
+func TestReconcileOneAgentCondition(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace}}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testName + "-" + ClassicFeature,
+			Namespace: testNamespace,
+			Labels:    buildLabels(testName, ClassicFeature),
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2},
+	}
+	clt := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	r := &ReconcileOneAgent{client: clt, feature: ClassicFeature}
+
+	t.Run(`DaemonSet not found`, func(t *testing.T) {
+		upd, err := r.reconcileOneAgentCondition(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.True(t, upd)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.OneAgentReadyConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.ReasonNotDeployed, condition.Reason)
+	})
+	t.Run(`pods not ready`, func(t *testing.T) {
+		require.NoError(t, clt.Create(context.TODO(), ds))
+
+		upd, err := r.reconcileOneAgentCondition(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.True(t, upd)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.OneAgentReadyConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, condition.Reason)
+		assert.Equal(t, "2 of 3 OneAgent pods are ready", condition.Message)
+		assert.Equal(t, dynatracev1beta1.Deploying, instance.Status.PhaseFromConditions())
+
+		upd, err = r.reconcileOneAgentCondition(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.False(t, upd)
+	})
+	t.Run(`all pods ready`, func(t *testing.T) {
+		ds.Status.NumberReady = 3
+		require.NoError(t, clt.Update(context.TODO(), ds))
+
+		upd, err := r.reconcileOneAgentCondition(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.True(t, upd)
+		assert.True(t, meta.IsStatusConditionTrue(instance.Status.Conditions, dynatracev1beta1.OneAgentReadyConditionType))
+		assert.Equal(t, dynatracev1beta1.Running, instance.Status.PhaseFromConditions())
+	})
+	t.Run(`pods of all architectures are counted`, func(t *testing.T) {
+		require.NoError(t, clt.Create(context.TODO(), &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      daemonSetName(testName, ClassicFeature, "", "arm64"),
+				Namespace: testNamespace,
+				Labels:    buildSelectorLabels(testName, ClassicFeature, "", "arm64"),
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 1},
+		}))
+
+		upd, err := r.reconcileOneAgentCondition(context.TODO(), instance)
+		assert.NoError(t, err)
+		assert.True(t, upd)
+
+		condition := meta.FindStatusCondition(instance.Status.Conditions, dynatracev1beta1.OneAgentReadyConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, "4 of 5 OneAgent pods are ready", condition.Message)
+	})
+}
+
+func newOneAgent() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DynaKube",
-			APIVersion: "dynatrace.com/v1alpha1",
+			APIVersion: "dynatrace.com/v1beta1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "my-oneagent",
@@ -0,0 +1,89 @@
+package oneagent
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/controllers/activegate/reconciler/statefulset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyPodTemplateOverride(t *testing.T) {
+	newInstance := func(override string) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: "dynatrace"},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.HostInjectSpec{
+						PodTemplateOverride: &runtime.RawExtension{Raw: []byte(override)},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run(`override is merged into the pod template`, func(t *testing.T) {
+		instance := newInstance(`{
+			"metadata": {"annotations": {"policy.example.com/approved": "true"}},
+			"spec": {
+				"containers": [
+					{"name": "dynatrace-oneagent", "readinessProbe": {"periodSeconds": 60}},
+					{"name": "sidecar", "image": "sidecar:1.0"}
+				],
+				"volumes": [{"name": "extra", "emptyDir": {}}]
+			}
+		}`)
+
+		ds, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+
+		template := ds.Spec.Template
+		assert.Equal(t, "true", template.Annotations["policy.example.com/approved"])
+		assert.NotEmpty(t, template.Annotations["container.apparmor.security.beta.kubernetes.io/dynatrace-oneagent"])
+
+		require.Len(t, template.Spec.Containers, 2)
+		oneagent := template.Spec.Containers[0]
+		assert.Equal(t, "dynatrace-oneagent", oneagent.Name)
+		assert.Equal(t, int32(60), oneagent.ReadinessProbe.PeriodSeconds)
+		assert.Equal(t, int32(30), oneagent.ReadinessProbe.InitialDelaySeconds)
+		assert.NotEmpty(t, oneagent.Env)
+		assert.Equal(t, "sidecar", template.Spec.Containers[1].Name)
+
+		var volumes []string
+		for _, vol := range template.Spec.Volumes {
+			volumes = append(volumes, vol.Name)
+		}
+		assert.Contains(t, volumes, "host-root")
+		assert.Contains(t, volumes, "extra")
+	})
+	t.Run(`selector labels can't be overridden`, func(t *testing.T) {
+		instance := newInstance(`{"metadata": {"labels": {"operator.dynatrace.com/instance": "other", "team": "platform"}}}`)
+
+		ds, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+		assert.Equal(t, testName, ds.Spec.Template.Labels["operator.dynatrace.com/instance"])
+		assert.Equal(t, "platform", ds.Spec.Template.Labels["team"])
+	})
+	t.Run(`version annotation can't be overridden`, func(t *testing.T) {
+		instance := newInstance(`{"metadata": {"annotations": {"internal.operator.dynatrace.com/version": "1.0.0"}}}`)
+		instance.Status.OneAgent.Version = "1.215.0.20210415-123456"
+
+		ds, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+		assert.Equal(t, instance.OneAgentRolloutVersion(), ds.Spec.Template.Annotations[statefulset.AnnotationVersion])
+	})
+	t.Run(`override changes the template hash`, func(t *testing.T) {
+		instance := newInstance(`{"metadata": {"annotations": {"a": "b"}}}`)
+		withOverride, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+
+		instance.Spec.OneAgent.ClassicFullStack.PodTemplateOverride = nil
+		withoutOverride, err := newDaemonSetForPool(consoleLogger, instance, nodePool{spec: instance.Spec.OneAgent.ClassicFullStack}, testClusterID, ClassicFeature, archAMD64)
+		require.NoError(t, err)
+		assert.True(t, hasDaemonSetChanged(withOverride, withoutOverride))
+	})
+}
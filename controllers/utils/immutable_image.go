@@ -1,14 +1,14 @@
 package utils
 
 import (
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtpullsecret"
 	corev1 "k8s.io/api/core/v1"
 )
 
 // SetUseImmutableImageStatus updates the status' UseImmutableImage field to indicate whether the Operator should use
 // immutable images or not.
-func SetUseImmutableImageStatus(instance *dynatracev1alpha1.DynaKube, fs *dynatracev1alpha1.FullStackSpec) bool {
+func SetUseImmutableImageStatus(instance *dynatracev1beta1.DynaKube, fs *dynatracev1beta1.HostInjectSpec) bool {
 	if fs.UseImmutableImage == instance.Status.OneAgent.UseImmutableImage {
 		return false
 	}
@@ -17,13 +17,13 @@ func SetUseImmutableImageStatus(instance *dynatracev1alpha1.DynaKube, fs *dynatr
 	return true
 }
 
-func BuildPullSecret(instance *dynatracev1alpha1.DynaKube) corev1.LocalObjectReference {
+func BuildPullSecret(instance *dynatracev1beta1.DynaKube) corev1.LocalObjectReference {
 	return corev1.LocalObjectReference{
 		Name: buildPullSecretName(instance),
 	}
 }
 
-func buildPullSecretName(instance *dynatracev1alpha1.DynaKube) string {
+func buildPullSecretName(instance *dynatracev1beta1.DynaKube) string {
 	name := instance.Name + dtpullsecret.PullSecretSuffix
 	if instance.Spec.CustomPullSecret != "" {
 		name = instance.Spec.CustomPullSecret
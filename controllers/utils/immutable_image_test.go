@@ -3,7 +3,7 @@ package utils
 import (
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/controllers/dtpullsecret"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -14,7 +14,7 @@ const testName = "test-name"
 
 func TestBuildPullSecret(t *testing.T) {
 	t.Run(`BuildPullSecret with default instance`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{}
+		instance := &dynatracev1beta1.DynaKube{}
 		pullSecret := BuildPullSecret(instance)
 
 		assert.NotNil(t, pullSecret)
@@ -22,7 +22,7 @@ func TestBuildPullSecret(t *testing.T) {
 			Name: dtpullsecret.PullSecretSuffix,
 		}, pullSecret)
 
-		instance = &dynatracev1alpha1.DynaKube{
+		instance = &dynatracev1beta1.DynaKube{
 			ObjectMeta: metav1.ObjectMeta{Name: testName},
 		}
 		pullSecret = BuildPullSecret(instance)
@@ -32,8 +32,8 @@ func TestBuildPullSecret(t *testing.T) {
 		}, pullSecret)
 	})
 	t.Run(`BuildPullSecret with custom pull secret`, func(t *testing.T) {
-		instance := &dynatracev1alpha1.DynaKube{
-			Spec: dynatracev1alpha1.DynaKubeSpec{
+		instance := &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
 				CustomPullSecret: testName,
 			}}
 		pullSecret := BuildPullSecret(instance)
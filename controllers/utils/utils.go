@@ -7,7 +7,7 @@ import (
 	"reflect"
 	"time"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
@@ -26,7 +26,7 @@ const (
 
 type Reconciliation struct {
 	Log      logr.Logger
-	Instance *dynatracev1alpha1.DynaKube
+	Instance *dynatracev1beta1.DynaKube
 	Now      metav1.Time
 
 	// If update is true, then changes on instance will be sent to the Kubernetes API.
@@ -38,9 +38,12 @@ type Reconciliation struct {
 	Err          error
 	Updated      bool
 	RequeueAfter time.Duration
+
+	// requeueBefore is an upper limit for RequeueAfter, which is kept on later updates.
+	requeueBefore time.Duration
 }
 
-func NewReconciliation(log logr.Logger, dk *dynatracev1alpha1.DynaKube) *Reconciliation {
+func NewReconciliation(log logr.Logger, dk *dynatracev1beta1.DynaKube) *Reconciliation {
 	return &Reconciliation{
 		Log:          log,
 		Instance:     dk,
@@ -64,9 +67,22 @@ func (rec *Reconciliation) Update(upd bool, d time.Duration, cause string) bool
 	rec.Log.Info("Updating DynaKube CR", "cause", cause)
 	rec.Updated = true
 	rec.RequeueAfter = d
+	if rec.requeueBefore > 0 && rec.requeueBefore < d {
+		rec.RequeueAfter = rec.requeueBefore
+	}
 	return true
 }
 
+// RequeueBefore makes sure that the reconciliation is requeued after at most d, even if it is updated later on.
+func (rec *Reconciliation) RequeueBefore(d time.Duration) {
+	if rec.requeueBefore <= 0 || d < rec.requeueBefore {
+		rec.requeueBefore = d
+	}
+	if d < rec.RequeueAfter {
+		rec.RequeueAfter = d
+	}
+}
+
 func (rec *Reconciliation) IsOutdated(last *metav1.Time, threshold time.Duration) bool {
 	return last == nil || last.Add(threshold).Before(rec.Now.Time)
 }
@@ -3,6 +3,10 @@ package utils
 import (
 	"os"
 	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/logger"
 
 	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
 	"github.com/stretchr/testify/assert"
@@ -50,3 +54,20 @@ func TestGetDeployment(t *testing.T) {
 	assert.Equal(t, "mydeployment", deploy.Name)
 	assert.Equal(t, "dynatrace", deploy.Namespace)
 }
+
+func TestReconciliation_RequeueBefore(t *testing.T) {
+	rec := NewReconciliation(logger.NewDTLogger(), &dynatracev1beta1.DynaKube{})
+
+	rec.RequeueBefore(10 * time.Minute)
+	assert.Equal(t, 10*time.Minute, rec.RequeueAfter)
+
+	rec.Update(true, 30*time.Minute, "test")
+	assert.Equal(t, 10*time.Minute, rec.RequeueAfter)
+
+	rec.Update(true, 30*time.Second, "test")
+	assert.Equal(t, 30*time.Second, rec.RequeueAfter)
+
+	rec.RequeueBefore(20 * time.Minute)
+	rec.Update(true, 30*time.Minute, "test")
+	assert.Equal(t, 10*time.Minute, rec.RequeueAfter)
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/go-logr/logr"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -27,6 +29,7 @@ import (
 
 const (
 	webhookName = "dynatrace-webhook"
+	crdName     = "dynakubes.dynatrace.com"
 )
 
 func Add(mgr manager.Manager, ns string) error {
@@ -98,6 +101,14 @@ func (r *ReconcileWebhookCertificates) Reconcile(ctx context.Context, request re
 		return reconcile.Result{}, fmt.Errorf("failed to reconcile webhook configuration: %w", err)
 	}
 
+	if err := r.reconcileValidatingWebhookConfig(ctx, r.logger, rootCerts); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to reconcile validating webhook configuration: %w", err)
+	}
+
+	if err := r.reconcileCRDConversion(ctx, r.logger, rootCerts); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to reconcile conversion webhook configuration: %w", err)
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -250,3 +261,112 @@ func (r *ReconcileWebhookCertificates) reconcileWebhookConfig(ctx context.Contex
 	cfg.Webhooks = webhookConfiguration.Webhooks
 	return r.client.Update(ctx, &cfg)
 }
+
+func (r *ReconcileWebhookCertificates) reconcileValidatingWebhookConfig(ctx context.Context, log logr.Logger, rootCerts []byte) error {
+	log.Info("Reconciling ValidatingWebhookConfiguration...")
+
+	path := "/validate"
+	scope := admissionregistrationv1.NamespacedScope
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	matchPolicy := admissionregistrationv1.Exact
+	webhookConfiguration := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: webhookName,
+			Labels: map[string]string{
+				"dynatrace.com/operator":           "dynakube",
+				"internal.dynatrace.com/component": "webhook",
+			},
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name:                    "webhook.dynatrace.com",
+			AdmissionReviewVersions: []string{"v1beta1", "v1"},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{
+					admissionregistrationv1.Create,
+					admissionregistrationv1.Update,
+				},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{"dynatrace.com"},
+					APIVersions: []string{"v1alpha1", "v1beta1"},
+					Resources:   []string{"dynakubes"},
+					Scope:       &scope,
+				},
+			}},
+			MatchPolicy: &matchPolicy,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      webhookName,
+					Namespace: r.namespace,
+					Path:      &path,
+				},
+				CABundle: rootCerts,
+			},
+			SideEffects: &sideEffects,
+		}},
+	}
+
+	var cfg admissionregistrationv1.ValidatingWebhookConfiguration
+	err := r.client.Get(ctx, client.ObjectKey{Name: webhookName}, &cfg)
+	if k8serrors.IsNotFound(err) {
+		log.Info("ValidatingWebhookConfiguration doesn't exist, creating...")
+
+		if err = r.client.Create(ctx, webhookConfiguration); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Webhooks) == 1 && bytes.Equal(cfg.Webhooks[0].ClientConfig.CABundle, rootCerts) {
+		return nil
+	}
+
+	log.Info("ValidatingWebhookConfiguration is outdated, updating...")
+	cfg.Webhooks = webhookConfiguration.Webhooks
+	return r.client.Update(ctx, &cfg)
+}
+
+func (r *ReconcileWebhookCertificates) reconcileCRDConversion(ctx context.Context, log logr.Logger, rootCerts []byte) error {
+	log.Info("Reconciling DynaKube conversion webhook...")
+
+	var crd apiextensionsv1.CustomResourceDefinition
+	err := r.client.Get(ctx, client.ObjectKey{Name: crdName}, &crd)
+	if k8serrors.IsNotFound(err) {
+		log.Info("CustomResourceDefinition doesn't exist, skipping conversion webhook configuration")
+		return nil
+	} else if meta.IsNoMatchError(err) {
+		// Clusters without apiextensions/v1 can't run conversion webhooks, the certificates are still rotated
+		log.Info("CustomResourceDefinitions v1 aren't supported, skipping conversion webhook configuration")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	conv := crd.Spec.Conversion
+	if conv != nil && conv.Strategy == apiextensionsv1.WebhookConverter && conv.Webhook != nil &&
+		conv.Webhook.ClientConfig != nil && bytes.Equal(conv.Webhook.ClientConfig.CABundle, rootCerts) {
+		return nil
+	}
+
+	path := "/convert"
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Name:      webhookName,
+					Namespace: r.namespace,
+					Path:      &path,
+				},
+				CABundle: rootCerts,
+			},
+			ConversionReviewVersions: []string{"v1", "v1beta1"},
+		},
+	}
+
+	log.Info("CustomResourceDefinition conversion webhook is outdated, updating...")
+	return r.client.Update(ctx, &crd)
+}
@@ -13,8 +13,12 @@ import (
 	"github.com/stretchr/testify/require"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -30,7 +34,7 @@ func TestReconcileWebhookCertificates(t *testing.T) {
 	now, err := time.Parse(time.RFC3339, "2018-01-10T00:00:00Z")
 	require.NoError(t, err)
 
-	c := fake.NewClient()
+	c := fake.NewClient(&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crdName}})
 	r := ReconcileWebhookCertificates{client: c, logger: logger, namespace: ns, scheme: scheme.Scheme}
 
 	reconcileAndGetCreds := func(days time.Duration) map[string]string {
@@ -54,6 +58,20 @@ func TestReconcileWebhookCertificates(t *testing.T) {
 		return string(webhookCfg.Webhooks[0].ClientConfig.CABundle)
 	}
 
+	getValidationCA := func() string {
+		var webhookCfg admissionregistrationv1.ValidatingWebhookConfiguration
+		require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: webhook.ServiceName}, &webhookCfg))
+		return string(webhookCfg.Webhooks[0].ClientConfig.CABundle)
+	}
+
+	getConversionCA := func() string {
+		var crd apiextensionsv1.CustomResourceDefinition
+		require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: crdName}, &crd))
+		require.NotNil(t, crd.Spec.Conversion)
+		assert.Equal(t, apiextensionsv1.WebhookConverter, crd.Spec.Conversion.Strategy)
+		return string(crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+	}
+
 	// Day 0: No objects exist, create them.
 
 	secret0 := reconcileAndGetCreds(0)
@@ -67,6 +85,8 @@ func TestReconcileWebhookCertificates(t *testing.T) {
 	assert.NotEmpty(t, secret0["ca.key"])
 	assert.Equal(t, secret0["ca.crt.old"], "")
 	assert.Equal(t, secret0["ca.crt"], getWebhookCA())
+	assert.Equal(t, secret0["ca.crt"], getValidationCA())
+	assert.Equal(t, secret0["ca.crt"], getConversionCA())
 
 	// Day 1: Certificates are valid, no changes.
 
@@ -98,6 +118,8 @@ func TestReconcileWebhookCertificates(t *testing.T) {
 	assert.NotEqual(t, secret9["ca.crt"], secret400["ca.crt"])
 	assert.NotEqual(t, secret9["ca.key"], secret400["ca.key"])
 	assert.Equal(t, secret400["ca.crt"]+secret9["ca.crt"], getWebhookCA())
+	assert.Equal(t, secret400["ca.crt"]+secret9["ca.crt"], getValidationCA())
+	assert.Equal(t, secret400["ca.crt"]+secret9["ca.crt"], getConversionCA())
 	assert.Equal(t, secret400["ca.crt.old"], secret9["ca.crt"])
 
 	// Day 401: CA and TLS certificates were renewed recently, no changes.
@@ -106,3 +128,29 @@ func TestReconcileWebhookCertificates(t *testing.T) {
 	assert.Equal(t, secret400, secret401)
 	assert.Equal(t, secret401["ca.crt"]+secret401["ca.crt.old"], getWebhookCA())
 }
+
+type noCRDClient struct {
+	client.Client
+}
+
+func (c noCRDClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok {
+		return &meta.NoKindMatchError{GroupKind: apiextensionsv1.Kind("CustomResourceDefinition"), SearchedVersions: []string{"v1"}}
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestReconcileWebhookCertificates_NoCRDv1(t *testing.T) {
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stdout))
+	ns := "dynatrace"
+
+	c := noCRDClient{Client: fake.NewClient()}
+	r := ReconcileWebhookCertificates{client: c, logger: logger, namespace: ns, scheme: scheme.Scheme, now: time.Now()}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: webhook.ServiceName, Namespace: ns}})
+	require.NoError(t, err)
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: webhook.SecretCertsName, Namespace: ns}, &secret))
+	assert.NotEmpty(t, secret.Data["ca.crt"])
+}
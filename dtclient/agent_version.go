@@ -1,20 +1,22 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 )
 
 // GetVersionForLatest gets the latest agent version for the given OS and installer type.
-func (dtc *dynatraceClient) GetLatestAgentVersion(os, installerType string) (string, error) {
+func (dtc *dynatraceClient) GetLatestAgentVersion(ctx context.Context, os, installerType string) (string, error) {
 	if len(os) == 0 || len(installerType) == 0 {
 		return "", errors.New("os or installerType is empty")
 	}
 
 	url := fmt.Sprintf("%s/v1/deployment/installer/agent/%s/%s/latest/metainfo", dtc.url, os, installerType)
-	resp, err := dtc.makeRequest(url, dynatracePaaSToken)
+	resp, err := dtc.makeRequest(ctx, url, dynatracePaaSToken)
 	if err != nil {
 		return "", err
 	}
@@ -31,19 +33,65 @@ func (dtc *dynatraceClient) GetLatestAgentVersion(os, installerType string) (str
 	return dtc.readResponseForLatestVersion(responseData)
 }
 
-func (dtc *dynatraceClient) GetEntityIDForIP(ip string) (string, error) {
+// GetAgentVersions gets the list of agent versions available for the given OS and installer type.
+func (dtc *dynatraceClient) GetAgentVersions(ctx context.Context, os, installerType string) ([]string, error) {
+	if len(os) == 0 || len(installerType) == 0 {
+		return nil, errors.New("os or installerType is empty")
+	}
+
+	url := fmt.Sprintf("%s/v1/deployment/installer/agent/versions/%s/%s", dtc.url, os, installerType)
+	resp, err := dtc.makeRequest(ctx, url, dynatracePaaSToken)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		//Swallow error, nothing has to be done at this point
+		_ = resp.Body.Close()
+	}()
+
+	responseData, err := dtc.getServerResponseData(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtc.readResponseForAgentVersions(responseData)
+}
+
+func (dtc *dynatraceClient) GetEntityIDForIP(ctx context.Context, ip string) (string, error) {
+	if len(ip) == 0 {
+		return "", errors.New("ip is invalid")
+	}
+
+	hostInfo, err := dtc.getHostInfoForIP(ctx, ip)
+	if err != nil {
+		return "", err
+	}
+	return entityIDOf(*hostInfo)
+}
+
+func (dtc *dynatraceClient) GetAgentVersionForIP(ctx context.Context, ip string) (string, error) {
 	if len(ip) == 0 {
 		return "", errors.New("ip is invalid")
 	}
 
-	hostInfo, err := dtc.getHostInfoForIP(ip)
+	hostInfo, err := dtc.getHostInfoForIP(ctx, ip)
 	if err != nil {
 		return "", err
 	}
+	return versionOf(*hostInfo)
+}
+
+func versionOf(hostInfo hostInfo) (string, error) {
+	if hostInfo.version == "" {
+		return "", errors.New("agent version not set for host")
+	}
+	return hostInfo.version, nil
+}
+
+func entityIDOf(hostInfo hostInfo) (string, error) {
 	if hostInfo.entityID == "" {
 		return "", errors.New("entity id not set for host")
 	}
-
 	return hostInfo.entityID, nil
 }
 
@@ -68,18 +116,58 @@ func (dtc *dynatraceClient) readResponseForLatestVersion(response []byte) (strin
 	return v, nil
 }
 
+// readResponseForAgentVersions reads the list of available agent versions from the given server response.
+func (dtc *dynatraceClient) readResponseForAgentVersions(response []byte) ([]string, error) {
+	type jsonResponse struct {
+		AvailableVersions []string
+	}
+
+	jr := &jsonResponse{}
+	err := json.Unmarshal(response, jr)
+	if err != nil {
+		dtc.logger.Error(err, "error unmarshalling json response")
+		return nil, err
+	}
+
+	return jr.AvailableVersions, nil
+}
+
 // GetVersionForLatest gets the latest agent package for the given OS and installer type.
-func (dtc *dynatraceClient) GetLatestAgent(os, installerType, flavor, arch string) (io.ReadCloser, error) {
+func (dtc *dynatraceClient) GetLatestAgent(ctx context.Context, os, installerType, flavor, arch string) (io.ReadCloser, error) {
 	if len(os) == 0 || len(installerType) == 0 {
 		return nil, errors.New("os or installerType is empty")
 	}
 
 	url := fmt.Sprintf("%s/v1/deployment/installer/agent/%s/%s/latest?bitness=64&flavor=%s&arch=%s",
 		dtc.url, os, installerType, flavor, arch)
-	resp, err := dtc.makeRequest(url, dynatracePaaSToken)
+	return dtc.getAgentPackage(ctx, url)
+}
+
+// GetAgent gets the agent package with the given version for the given OS and installer type.
+func (dtc *dynatraceClient) GetAgent(ctx context.Context, os, installerType, flavor, arch, version string) (io.ReadCloser, error) {
+	if len(os) == 0 || len(installerType) == 0 || len(version) == 0 {
+		return nil, errors.New("os, installerType or version is empty")
+	}
+
+	url := fmt.Sprintf("%s/v1/deployment/installer/agent/%s/%s/version/%s?bitness=64&flavor=%s&arch=%s",
+		dtc.url, os, installerType, version, flavor, arch)
+	return dtc.getAgentPackage(ctx, url)
+}
+
+func (dtc *dynatraceClient) getAgentPackage(ctx context.Context, url string) (io.ReadCloser, error) {
+	resp, err := dtc.makeRequest(ctx, url, dynatracePaaSToken)
 	if err != nil {
 		return nil, err
 	}
 
-	return resp.Body, err
+	if resp.StatusCode != http.StatusOK {
+		defer func() {
+			//Swallow error, nothing has to be done at this point
+			_ = resp.Body.Close()
+		}()
+		_, err = dtc.getServerResponseData(resp)
+		return nil, err
+	}
+
+	return resp.Body, nil
 }
@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -84,8 +85,9 @@ func TestGetEntityIDForIP(t *testing.T) {
 		paasToken:  paasToken,
 		httpClient: dynatraceServer.Client(),
 		url:        dynatraceServer.URL,
+		hostCache:  newHostCache(),
 	}
-	require.NoError(t, dtc.setHostCacheFromResponse([]byte(
+	require.NoError(t, dtc.addHostsToCache([]byte(
 		fmt.Sprintf(`[
 	{
 		"entityId": "HOST-42",
@@ -106,17 +108,17 @@ func TestGetEntityIDForIP(t *testing.T) {
 		}
 	}
 ]`, time.Now().UTC().Unix()*1000))))
-	id, err := dtc.GetEntityIDForIP("1.1.1.1")
+	id, err := dtc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, id)
 	assert.Equal(t, "HOST-42", id)
 
-	id, err = dtc.GetEntityIDForIP("2.2.2.2")
+	id, err = dtc.GetEntityIDForIP(context.TODO(), "2.2.2.2")
 
 	assert.Error(t, err)
 	assert.Empty(t, id)
 
-	require.NoError(t, dtc.setHostCacheFromResponse([]byte(
+	require.NoError(t, dtc.addHostsToCache([]byte(
 		fmt.Sprintf(`[
 	{
 		"entityId": "",
@@ -138,31 +140,86 @@ func TestGetEntityIDForIP(t *testing.T) {
 	}
 ]`, time.Now().UTC().Unix()*1000))))
 
-	id, err = dtc.GetEntityIDForIP("1.1.1.1")
+	id, err = dtc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
 
 	assert.Error(t, err)
 	assert.Empty(t, id)
 }
 
+func TestGetAgentVersionForIP(t *testing.T) {
+	dtc := dynatraceClient{
+		logger:               log.Log.WithName("dtc"),
+		hostCache:            newHostCache(),
+		disableHostsRequests: true,
+	}
+	require.NoError(t, dtc.addHostsToCache([]byte(
+		fmt.Sprintf(`[
+	{
+		"entityId": "HOST-42",
+		"lastSeenTimestamp": %v,
+		"ipAddresses": ["1.1.1.1"],
+		"agentVersion": {
+			"major": 1,
+			"minor": 195,
+			"revision": 0,
+			"timestamp": "20200515-045253",
+			"sourceRevision": ""
+		}
+	},
+	{
+		"entityId": "HOST-84",
+		"lastSeenTimestamp": %v,
+		"ipAddresses": ["2.2.2.2"]
+	}
+]`, time.Now().UTC().Unix()*1000, time.Now().UTC().Unix()*1000))))
+
+	version, err := dtc.GetAgentVersionForIP(context.TODO(), "1.1.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.195.0.20200515-045253", version)
+
+	_, err = dtc.GetAgentVersionForIP(context.TODO(), "2.2.2.2")
+	assert.EqualError(t, err, "agent version not set for host")
+
+	_, err = dtc.GetAgentVersionForIP(context.TODO(), "3.3.3.3")
+	assert.Error(t, err)
+
+	_, err = dtc.GetAgentVersionForIP(context.TODO(), "")
+	assert.Error(t, err)
+}
+
 func testAgentVersionGetLatestAgentVersion(t *testing.T, dynatraceClient Client) {
 	{
-		_, err := dynatraceClient.GetLatestAgentVersion("", InstallerTypeDefault)
+		_, err := dynatraceClient.GetLatestAgentVersion(context.TODO(), "", InstallerTypeDefault)
 
 		assert.Error(t, err, "empty OS")
 	}
 	{
-		_, err := dynatraceClient.GetLatestAgentVersion(OsUnix, "")
+		_, err := dynatraceClient.GetLatestAgentVersion(context.TODO(), OsUnix, "")
 
 		assert.Error(t, err, "empty installer type")
 	}
 	{
-		latestAgentVersion, err := dynatraceClient.GetLatestAgentVersion(OsUnix, InstallerTypeDefault)
+		latestAgentVersion, err := dynatraceClient.GetLatestAgentVersion(context.TODO(), OsUnix, InstallerTypeDefault)
 
 		assert.NoError(t, err)
 		assert.Equal(t, "17", latestAgentVersion, "latest agent version equals expected version")
 	}
 }
 
+func testAgentVersionGetAgentVersions(t *testing.T, dynatraceClient Client) {
+	{
+		_, err := dynatraceClient.GetAgentVersions(context.TODO(), "", InstallerTypeDefault)
+
+		assert.Error(t, err, "empty OS")
+	}
+	{
+		versions, err := dynatraceClient.GetAgentVersions(context.TODO(), OsUnix, InstallerTypeDefault)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1.213.0.20210301-123456", "1.215.0.20210415-123456"}, versions)
+	}
+}
+
 type ipHandler struct{}
 
 func (ipHandler *ipHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
@@ -185,3 +242,14 @@ func handleLatestAgentVersion(request *http.Request, writer http.ResponseWriter)
 		writeError(writer, http.StatusMethodNotAllowed)
 	}
 }
+
+func handleAgentVersions(request *http.Request, writer http.ResponseWriter) {
+	switch request.Method {
+	case "GET":
+		writer.WriteHeader(http.StatusOK)
+		out, _ := json.Marshal(map[string][]string{"availableVersions": {"1.213.0.20210301-123456", "1.215.0.20210415-123456"}})
+		_, _ = writer.Write(out)
+	default:
+		writeError(writer, http.StatusMethodNotAllowed)
+	}
+}
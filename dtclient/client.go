@@ -1,12 +1,14 @@
 package dtclient
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -27,33 +29,49 @@ type Client interface {
 	//  - IO error or unexpected response
 	//  - error response from the server (e.g. authentication failure)
 	//  - the agent version is not set or empty
-	GetLatestAgentVersion(os, installerType string) (string, error)
+	GetLatestAgentVersion(ctx context.Context, os, installerType string) (string, error)
+
+	// GetAgentVersions gets the list of agent versions available for the given OS and installer type.
+	//
+	// Returns an error for the following conditions:
+	//  - os or installerType is empty
+	//  - IO error or unexpected response
+	//  - error response from the server (e.g. authentication failure)
+	GetAgentVersions(ctx context.Context, os, installerType string) ([]string, error)
 
 	// GetLatestAgent returns a reader with the contents of the download. Must be closed by caller.
-	GetLatestAgent(os, installerType, flavor, arch string) (io.ReadCloser, error)
+	GetLatestAgent(ctx context.Context, os, installerType, flavor, arch string) (io.ReadCloser, error)
+
+	// GetAgent returns a reader with the contents of the download of the given agent version. Must be closed by caller.
+	GetAgent(ctx context.Context, os, installerType, flavor, arch, version string) (io.ReadCloser, error)
 
 	// GetCommunicationHosts returns, on success, the list of communication hosts used for available
 	// communication endpoints that the Dynatrace OneAgent can use to connect to.
 	//
 	// Returns an error if there was also an error response from the server.
-	GetConnectionInfo() (ConnectionInfo, error)
+	GetConnectionInfo(ctx context.Context) (ConnectionInfo, error)
 
 	// GetCommunicationHostForClient returns a CommunicationHost for the client's API URL. Or error, if failed to be parsed.
 	GetCommunicationHostForClient() (CommunicationHost, error)
 
 	// SendEvent posts events to dynatrace API
-	SendEvent(eventData *EventData) error
+	SendEvent(ctx context.Context, eventData *EventData) error
 
 	// GetEntityIDForIP returns the entity id for a given IP address.
 	//
 	// Returns an error in case the lookup failed.
-	GetEntityIDForIP(ip string) (string, error)
+	GetEntityIDForIP(ctx context.Context, ip string) (string, error)
+
+	// GetAgentVersionForIP returns the version of the OneAgent running on the host with the given IP address.
+	//
+	// Returns an error in case the lookup failed or the host doesn't report a version.
+	GetAgentVersionForIP(ctx context.Context, ip string) (string, error)
 
 	// GetTokenScopes returns the list of scopes assigned to a token if successful.
-	GetTokenScopes(token string) (TokenScopes, error)
+	GetTokenScopes(ctx context.Context, token string) (TokenScopes, error)
 
 	// GetTenantInfo returns TenantInfo that holds UUID, Tenant Token and Endpoints
-	GetTenantInfo() (*TenantInfo, error)
+	GetTenantInfo(ctx context.Context) (*TenantInfo, error)
 }
 
 // Known OS values.
@@ -83,10 +101,28 @@ const (
 
 // Known architectures.
 const (
-	ArchX86 = "x86"
-	ArchARM = "arm"
+	ArchX86   = "x86"
+	ArchARM   = "arm"
+	ArchPPCLE = "ppcle"
+	ArchS390  = "s390"
 )
 
+// InstallerArch returns the architecture of the OneAgent installer for the given node architecture as used by
+// Kubernetes and Go, e.g. amd64, or an empty string if OneAgent isn't available for it.
+func InstallerArch(nodeArch string) string {
+	switch nodeArch {
+	case "amd64":
+		return ArchX86
+	case "arm64":
+		return ArchARM
+	case "ppc64le":
+		return ArchPPCLE
+	case "s390x":
+		return ArchS390
+	}
+	return ""
+}
+
 // Known token scopes
 const (
 	TokenScopeInstallerDownload = "InstallerDownload"
@@ -119,15 +155,22 @@ func NewClient(url, apiToken, paasToken string, opts ...Option) (Client, error)
 		paasToken: paasToken,
 		logger:    log.Log.WithName("dynatrace.client"),
 
-		hostCache: make(map[string]hostInfo),
 		httpClient: &http.Client{
 			Transport: http.DefaultTransport.(*http.Transport).Clone(),
 		},
+
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
 	}
 
 	for _, opt := range opts {
 		opt(dc)
 	}
+	dc.hostCache = sharedHostCache(dc.url, dc.networkZone)
+
+	if dc.useAPIV2 {
+		return newDynatraceClientV2(dc), nil
+	}
 	return dc, nil
 }
 
@@ -186,3 +229,37 @@ func DisableHostsRequests(disabledHostsRequests bool) Option {
 		c.disableHostsRequests = disabledHostsRequests
 	}
 }
+
+// UseAPIV2 creates an Option that specifies whether the client should use the v2 endpoints of the Dynatrace API
+// where available. Endpoints which don't exist on the cluster, as it is the case on older Managed clusters, fall back
+// to their v1 counterpart. The default is false.
+func UseAPIV2(useAPIV2 bool) Option {
+	return func(c *dynatraceClient) {
+		c.useAPIV2 = useAPIV2
+	}
+}
+
+// DynaKube creates an Option that sets the name of the DynaKube the client is used for, which is added as label to
+// the request metrics.
+func DynaKube(name string) Option {
+	return func(c *dynatraceClient) {
+		c.dynakube = name
+	}
+}
+
+// Timeout creates an Option that limits how long requests to the Dynatrace API may take, including reading the
+// response. Downloads of OneAgent packages are only bound by the context of the request.
+func Timeout(timeout time.Duration) Option {
+	return func(c *dynatraceClient) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// Retries creates an Option that sets how often idempotent requests are retried on connection errors, rate limiting
+// and transient server errors, and the initial delay between attempts. Defaults to 3 retries starting at 500ms.
+func Retries(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *dynatraceClient) {
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+	}
+}
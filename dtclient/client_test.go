@@ -1,10 +1,15 @@
 package dtclient
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -86,3 +91,39 @@ func TestCerts(t *testing.T) {
 	certs(&dtc)
 	assert.Equal(t, [][]uint8{}, transport.TLSClientConfig.RootCAs.Subjects())
 }
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"latestAgentVersion":"1.2.3"}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, apiToken, paasToken, Timeout(50*time.Millisecond), Retries(0, 0))
+	require.NoError(t, err)
+
+	t.Run(`API requests time out`, func(t *testing.T) {
+		_, err := c.GetLatestAgentVersion(context.TODO(), OsUnix, InstallerTypePaaS)
+		assert.Error(t, err)
+	})
+	t.Run(`downloads are not affected by the timeout`, func(t *testing.T) {
+		r, err := c.GetLatestAgent(context.TODO(), OsUnix, InstallerTypePaaS, FlavorMultidistro, ArchX86)
+		require.NoError(t, err)
+		_ = r.Close()
+	})
+	t.Run(`requests are cancelled with their context`, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		_, err := c.GetLatestAgent(ctx, OsUnix, InstallerTypePaaS, FlavorMultidistro, ArchX86)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestInstallerArch(t *testing.T) {
+	assert.Equal(t, ArchX86, InstallerArch("amd64"))
+	assert.Equal(t, ArchARM, InstallerArch("arm64"))
+	assert.Equal(t, ArchPPCLE, InstallerArch("ppc64le"))
+	assert.Equal(t, ArchS390, InstallerArch("s390x"))
+	assert.Empty(t, InstallerArch("riscv64"))
+}
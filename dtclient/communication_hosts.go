@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,9 +26,9 @@ func (dtc *dynatraceClient) GetCommunicationHostForClient() (CommunicationHost,
 	return dtc.parseEndpoint(dtc.url)
 }
 
-func (dtc *dynatraceClient) GetConnectionInfo() (ConnectionInfo, error) {
+func (dtc *dynatraceClient) GetConnectionInfo(ctx context.Context) (ConnectionInfo, error) {
 	connectionInfoURL := fmt.Sprintf("%s/v1/deployment/installer/agent/connectioninfo", dtc.url)
-	resp, err := dtc.makeRequest(connectionInfoURL, dynatracePaaSToken)
+	resp, err := dtc.makeRequest(ctx, connectionInfoURL, dynatracePaaSToken)
 	if err != nil {
 		return ConnectionInfo{}, err
 	}
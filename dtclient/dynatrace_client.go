@@ -1,10 +1,13 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -25,11 +28,21 @@ type dynatraceClient struct {
 
 	networkZone string
 
+	// dynakube is the name of the DynaKube the client was created for, used to label metrics.
+	dynakube string
+
 	disableHostsRequests bool
 
+	// useAPIV2 makes NewClient return a client which uses the v2 endpoints of the Dynatrace API where available.
+	useAPIV2 bool
+
 	httpClient *http.Client
 
-	hostCache map[string]hostInfo
+	maxRetries     int
+	retryBaseDelay time.Duration
+
+	// hostCache is shared with all clients for the same tenant and network zone.
+	hostCache *hostCache
 
 	// Set for testing purposes, leave the default zero value to use the current time.
 	now time.Time
@@ -44,8 +57,8 @@ const (
 
 // makeRequest does an HTTP request by formatting the URL from the given arguments and returns the response.
 // The response body must be closed by the caller when no longer used.
-func (dtc *dynatraceClient) makeRequest(url string, tokenType tokenType) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (dtc *dynatraceClient) makeRequest(ctx context.Context, url string, tokenType tokenType) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing http request: %s", err.Error())
 	}
@@ -69,7 +82,7 @@ func (dtc *dynatraceClient) makeRequest(url string, tokenType tokenType) (*http.
 
 	req.Header.Add("Authorization", authHeader)
 
-	return dtc.httpClient.Do(req)
+	return dtc.doWithRetry(req)
 }
 
 func (dtc *dynatraceClient) getServerResponseData(response *http.Response) ([]byte, error) {
@@ -80,30 +93,40 @@ func (dtc *dynatraceClient) getServerResponseData(response *http.Response) ([]by
 
 	if response.StatusCode != http.StatusOK &&
 		response.StatusCode != http.StatusCreated {
-		return responseData, dtc.handleErrorResponseFromAPI(responseData, response.StatusCode)
+		return responseData, dtc.handleErrorResponseFromAPI(responseData, response.StatusCode, response.Header)
 	}
 
 	return responseData, nil
 }
 
-func (dtc *dynatraceClient) handleErrorResponseFromAPI(response []byte, statusCode int) error {
+func (dtc *dynatraceClient) handleErrorResponseFromAPI(response []byte, statusCode int, header http.Header) error {
 	se := serverErrorResponse{}
 	if err := json.Unmarshal(response, &se); err != nil {
-		return fmt.Errorf("response error: %d, can't unmarshal json response: %w", statusCode, err)
+		se.ErrorMessage = ServerError{Code: statusCode, Message: fmt.Sprintf("can't unmarshal json response: %s", err)}
+	}
+
+	if se.ErrorMessage.Code == 0 {
+		se.ErrorMessage.Code = statusCode
+	}
+	if retryAfter, ok := parseRetryAfter(header, dtc.currentTime()); ok {
+		se.ErrorMessage.RetryAfter = retryAfter
 	}
 
 	return se.ErrorMessage
 }
 
-func (dtc *dynatraceClient) getHostInfoForIP(ip string) (*hostInfo, error) {
-	if len(dtc.hostCache) == 0 {
-		err := dtc.buildHostCache()
-		if err != nil {
-			return nil, fmt.Errorf("error building hostcache from dynatrace cluster: %w", err)
-		}
+func (dtc *dynatraceClient) getHostInfoForIP(ctx context.Context, ip string) (*hostInfo, error) {
+	if info, ok := dtc.hostCache.get(ip, dtc.currentTime()); ok {
+		hostCacheLookups.WithLabelValues(hostCacheHit).Inc()
+		return &info, nil
 	}
+	hostCacheLookups.WithLabelValues(hostCacheMiss).Inc()
 
-	switch hostInfo, ok := dtc.hostCache[ip]; {
+	if err := dtc.readHosts(ctx, ip); err != nil {
+		return nil, fmt.Errorf("error reading hosts from dynatrace cluster: %w", err)
+	}
+
+	switch hostInfo, ok := dtc.hostCache.get(ip, dtc.currentTime()); {
 	case !ok:
 		return nil, errors.New("host not found")
 	default:
@@ -111,15 +134,54 @@ func (dtc *dynatraceClient) getHostInfoForIP(ip string) (*hostInfo, error) {
 	}
 }
 
-func (dtc *dynatraceClient) buildHostCache() error {
+// readHosts reads the host list page by page into the host cache, until a host with the given IP has been found. A
+// lookup continues from the page the previous one stopped at, and the list is only read again from the start once
+// hostCacheTTL has passed since it was last read to the end.
+func (dtc *dynatraceClient) readHosts(ctx context.Context, ip string) error {
 	if dtc.disableHostsRequests {
 		return nil
 	}
 
-	url := fmt.Sprintf("%s/v1/entity/infrastructure/hosts?includeDetails=false", dtc.url)
-	resp, err := dtc.makeRequest(url, dynatraceApiToken)
+	nextPageKey, ok := dtc.hostCache.resumePoint(dtc.currentTime())
+	if !ok {
+		return nil
+	}
+	resumed := nextPageKey != ""
+
+	for {
+		var err error
+		nextPageKey, err = dtc.readHostsPage(ctx, nextPageKey)
+		if err != nil && resumed {
+			// Page keys expire after a while, so start over from the first page.
+			dtc.logger.Info("Hosts cache: failed to continue reading hosts, starting over", "cause", err.Error())
+			dtc.hostCache.restart()
+			nextPageKey, resumed = "", false
+			continue
+		} else if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if nextPageKey == "" {
+			return nil
+		}
+		if _, found := dtc.hostCache.get(ip, dtc.currentTime()); found {
+			return nil
+		}
+	}
+}
+
+func (dtc *dynatraceClient) readHostsPage(ctx context.Context, pageKey string) (string, error) {
+	query := url.Values{}
+	if pageKey != "" {
+		query.Set("nextPageKey", pageKey)
+	} else {
+		query.Set("includeDetails", "false")
+		query.Set("pageSize", strconv.Itoa(hostsPageSize))
+	}
+
+	resp, err := dtc.makeRequest(ctx, fmt.Sprintf("%s/v1/entity/infrastructure/hosts?%s", dtc.url, query.Encode()), dynatraceApiToken)
 	if err != nil {
-		return errors.WithStack(err)
+		return "", errors.WithStack(err)
 	}
 	defer func() {
 		// Swallow error
@@ -128,18 +190,20 @@ func (dtc *dynatraceClient) buildHostCache() error {
 
 	responseData, err := dtc.getServerResponseData(resp)
 	if err != nil {
-		return errors.WithStack(err)
+		return "", errors.WithStack(err)
 	}
 
-	err = dtc.setHostCacheFromResponse(responseData)
+	err = dtc.addHostsToCache(responseData)
 	if err != nil {
-		return errors.WithStack(err)
+		return "", errors.WithStack(err)
 	}
 
-	return nil
+	nextPageKey := resp.Header.Get("Next-Page-Key")
+	dtc.hostCache.pageRead(nextPageKey, dtc.currentTime())
+	return nextPageKey, nil
 }
 
-func (dtc *dynatraceClient) setHostCacheFromResponse(response []byte) error {
+func (dtc *dynatraceClient) addHostsToCache(response []byte) error {
 	type hostInfoResponse struct {
 		IPAddresses  []string
 		AgentVersion *struct {
@@ -153,8 +217,6 @@ func (dtc *dynatraceClient) setHostCacheFromResponse(response []byte) error {
 		LastSeenTimestamp int64
 	}
 
-	dtc.hostCache = make(map[string]hostInfo)
-
 	var hostInfoResponses []hostInfoResponse
 	err := json.Unmarshal(response, &hostInfoResponses)
 	if err != nil {
@@ -162,10 +224,7 @@ func (dtc *dynatraceClient) setHostCacheFromResponse(response []byte) error {
 		return errors.WithStack(err)
 	}
 
-	now := dtc.now
-	if now.IsZero() {
-		now = time.Now().UTC()
-	}
+	now := dtc.currentTime()
 
 	var inactive []string
 
@@ -186,11 +245,9 @@ func (dtc *dynatraceClient) setHostCacheFromResponse(response []byte) error {
 			}
 
 			for _, ip := range info.IPAddresses {
-				if old, ok := dtc.hostCache[ip]; ok {
-					dtc.logger.Info("Hosts cache: replacing host", "ip", ip, "new", hostInfo.entityID, "old", old.entityID)
+				if old, replaced := dtc.hostCache.set(ip, hostInfo, now); replaced {
+					dtc.logger.Info("Hosts cache: replacing host", "ip", ip, "new", hostInfo.entityID, "old", old)
 				}
-
-				dtc.hostCache[ip] = hostInfo
 			}
 		}
 	}
@@ -210,6 +267,9 @@ type serverErrorResponse struct {
 type ServerError struct {
 	Code    int
 	Message string
+
+	// RetryAfter is the delay requested by the server through the Retry-After header, if any.
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error formats the server error code and message.
@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -26,7 +27,7 @@ func TestMakeRequest(t *testing.T) {
 		paasToken: paasToken,
 		logger:    consoleLogger,
 
-		hostCache:  make(map[string]hostInfo),
+		hostCache:  newHostCache(),
 		httpClient: http.DefaultClient,
 	}
 
@@ -34,12 +35,12 @@ func TestMakeRequest(t *testing.T) {
 
 	{
 		url := fmt.Sprintf("%s/v1/deployment/installer/agent/connectioninfo", dc.url)
-		resp, err := dc.makeRequest(url, dynatraceApiToken)
+		resp, err := dc.makeRequest(context.TODO(), url, dynatraceApiToken)
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
 	}
 	{
-		resp, err := dc.makeRequest("%s/v1/deployment/installer/agent/connectioninfo", dynatraceApiToken)
+		resp, err := dc.makeRequest(context.TODO(), "%s/v1/deployment/installer/agent/connectioninfo", dynatraceApiToken)
 		assert.Error(t, err, "unsupported protocol scheme")
 		assert.Nil(t, resp)
 	}
@@ -55,7 +56,7 @@ func TestGetResponseOrServerError(t *testing.T) {
 		paasToken: paasToken,
 		logger:    consoleLogger,
 
-		hostCache:  make(map[string]hostInfo),
+		hostCache:  newHostCache(),
 		httpClient: http.DefaultClient,
 	}
 
@@ -63,7 +64,7 @@ func TestGetResponseOrServerError(t *testing.T) {
 
 	reqURL := fmt.Sprintf("%s/v1/deployment/installer/agent/connectioninfo", dc.url)
 	{
-		resp, err := dc.makeRequest(reqURL, dynatraceApiToken)
+		resp, err := dc.makeRequest(context.TODO(), reqURL, dynatraceApiToken)
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
 
@@ -73,7 +74,7 @@ func TestGetResponseOrServerError(t *testing.T) {
 	}
 }
 
-func TestBuildHostCache(t *testing.T) {
+func TestReadHosts(t *testing.T) {
 	dynatraceServer := httptest.NewServer(dynatraceServerHandler())
 	defer dynatraceServer.Close()
 
@@ -83,26 +84,29 @@ func TestBuildHostCache(t *testing.T) {
 		now:       time.Unix(1521540000, 0),
 		logger:    consoleLogger,
 
-		hostCache:  make(map[string]hostInfo),
+		hostCache:  newHostCache(),
 		httpClient: http.DefaultClient,
 	}
 
 	require.NotNil(t, dc)
 
 	{
-		err := dc.buildHostCache()
+		err := dc.readHosts(context.TODO(), "10.11.12.13")
 		assert.Error(t, err, "error querying dynatrace server")
-		assert.Empty(t, dc.hostCache)
+		assert.Empty(t, dc.hostCache.hosts)
 	}
 	{
 		dc.apiToken = apiToken
-		err := dc.buildHostCache()
+		err := dc.readHosts(context.TODO(), "10.11.12.13")
 		assert.NoError(t, err)
-		assert.NotZero(t, len(dc.hostCache))
-		assert.ObjectsAreEqualValues(dc.hostCache, map[string]hostInfo{
-			"10.11.12.13": {version: "1.142.0.20180313-173634", entityID: "dynatraceSampleEntityId"},
-			"192.168.0.1": {version: "1.142.0.20180313-173634", entityID: "dynatraceSampleEntityId"},
-		})
+
+		info, ok := dc.hostCache.get("10.11.12.13", dc.now)
+		assert.True(t, ok)
+		assert.Equal(t, hostInfo{version: "1.142.0.20180313-173634", entityID: "dynatraceSampleEntityId"}, info)
+
+		info, ok = dc.hostCache.get("192.168.0.1", dc.now)
+		assert.True(t, ok)
+		assert.Equal(t, hostInfo{version: "1.142.0.20180313-173634", entityID: "dynatraceSampleEntityId"}, info)
 	}
 }
 
@@ -137,6 +141,7 @@ func TestDynatraceClientWithServer(t *testing.T) {
 	require.NotNil(t, dtc)
 
 	testAgentVersionGetLatestAgentVersion(t, dtc)
+	testAgentVersionGetAgentVersions(t, dtc)
 	testCommunicationHostsGetCommunicationHosts(t, dtc)
 	testSendEvent(t, dtc)
 	testGetTokenScopes(t, dtc)
@@ -156,10 +161,13 @@ func dynatraceServerHandler() http.HandlerFunc {
 
 func handleRequest(request *http.Request, writer http.ResponseWriter) {
 	latestAgentVersion := fmt.Sprintf("/v1/deployment/installer/agent/%s/%s/latest/metainfo", OsUnix, InstallerTypeDefault)
+	agentVersions := fmt.Sprintf("/v1/deployment/installer/agent/versions/%s/%s", OsUnix, InstallerTypeDefault)
 
 	switch request.URL.Path {
 	case latestAgentVersion:
 		handleLatestAgentVersion(request, writer)
+	case agentVersions:
+		handleAgentVersions(request, writer)
 	case "/v1/entity/infrastructure/hosts":
 		(&ipHandler{}).ServeHTTP(writer, request)
 	case "/v1/deployment/installer/agent/connectioninfo":
@@ -192,11 +200,12 @@ func TestIgnoreNonCurrentlySeenHosts(t *testing.T) {
 	// HOST-84 - lastSeenTimestamp: 19/05/2020 01:49 AM UTC
 
 	c := dynatraceClient{
-		logger: consoleLogger,
-		now:    time.Unix(1589969400, 0).UTC(),
+		logger:    consoleLogger,
+		now:       time.Unix(1589969400, 0).UTC(),
+		hostCache: newHostCache(),
 	}
 
-	require.NoError(t, c.setHostCacheFromResponse([]byte(`[
+	require.NoError(t, c.addHostsToCache([]byte(`[
 	{
 		"entityId": "HOST-42",
 		"displayName": "A",
@@ -228,7 +237,7 @@ func TestIgnoreNonCurrentlySeenHosts(t *testing.T) {
 	}
 ]`)))
 
-	info, err := c.getHostInfoForIP("1.1.1.1")
+	info, err := c.getHostInfoForIP(context.TODO(), "1.1.1.1")
 	require.NoError(t, err)
 	require.Equal(t, "HOST-42", info.entityID)
 	require.Equal(t, "1.195.0.20200515-045253", info.version)
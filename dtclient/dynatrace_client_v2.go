@@ -0,0 +1,291 @@
+package dtclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// dynatraceClientV2 implements the Client interface using the v2 endpoints of the Dynatrace API where available. Calls
+// not covered by v2 endpoints are handled by the embedded v1 client.
+type dynatraceClientV2 struct {
+	*dynatraceClient
+}
+
+func newDynatraceClientV2(dtc *dynatraceClient) *dynatraceClientV2 {
+	return &dynatraceClientV2{dynatraceClient: dtc}
+}
+
+// v1Fallbacks holds the endpoints which returned 404, keyed by tenant, as it is the case for older Managed clusters.
+// Requests for these endpoints are sent to the v1 API instead. It is shared by all clients, since a new client is
+// created for each reconcile.
+var v1Fallbacks = struct {
+	sync.Mutex
+	endpoints map[string]map[string]bool
+}{endpoints: make(map[string]map[string]bool)}
+
+// usesV1 returns true if requests for the endpoint are sent to the v1 API, since the cluster doesn't provide it.
+func (dtc *dynatraceClientV2) usesV1(endpoint string) bool {
+	v1Fallbacks.Lock()
+	defer v1Fallbacks.Unlock()
+
+	return v1Fallbacks.endpoints[dtc.url][endpoint]
+}
+
+// GetEntityIDForIP queries the host for the given IP through an entity selector, instead of reading the list of all
+// hosts of the environment like the v1 API requires.
+func (dtc *dynatraceClientV2) GetEntityIDForIP(ctx context.Context, ip string) (string, error) {
+	if len(ip) == 0 {
+		return "", errors.New("ip is invalid")
+	}
+	if dtc.usesV1(endpointEntities) {
+		return dtc.dynatraceClient.GetEntityIDForIP(ctx, ip)
+	}
+
+	hostInfo, err := dtc.getHostInfoForIP(ctx, ip)
+	if err != nil {
+		return "", err
+	}
+	return entityIDOf(*hostInfo)
+}
+
+// GetAgentVersionForIP returns the installer version reported for the host with the given IP.
+func (dtc *dynatraceClientV2) GetAgentVersionForIP(ctx context.Context, ip string) (string, error) {
+	if len(ip) == 0 {
+		return "", errors.New("ip is invalid")
+	}
+	if dtc.usesV1(endpointEntities) {
+		return dtc.dynatraceClient.GetAgentVersionForIP(ctx, ip)
+	}
+
+	hostInfo, err := dtc.getHostInfoForIP(ctx, ip)
+	if err != nil {
+		return "", err
+	}
+	return versionOf(*hostInfo)
+}
+
+func (dtc *dynatraceClientV2) getHostInfoForIP(ctx context.Context, ip string) (*hostInfo, error) {
+	if info, ok := dtc.hostCache.get(ip, dtc.currentTime()); ok {
+		hostCacheLookups.WithLabelValues(hostCacheHit).Inc()
+		return &info, nil
+	}
+	hostCacheLookups.WithLabelValues(hostCacheMiss).Inc()
+
+	if dtc.disableHostsRequests {
+		return nil, errors.New("host not found")
+	}
+
+	query := url.Values{}
+	query.Set("entitySelector", fmt.Sprintf(`type("HOST"),ipAddress("%s")`, ip))
+	// Hosts which haven't been seen in the last 30 minutes are ignored, same as for the v1 host cache.
+	query.Set("from", "now-30m")
+	query.Set("fields", "+properties.networkZone,+properties.installerVersion")
+
+	resp, err := dtc.makeRequest(ctx, fmt.Sprintf("%s/v2/entities?%s", dtc.url, query.Encode()), dynatraceApiToken)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		//Swallow error, nothing has to be done at this point
+		_ = resp.Body.Close()
+	}()
+
+	responseData, err := dtc.getServerResponseData(resp)
+	if dtc.fallBackToV1(endpointEntities, err) {
+		return dtc.dynatraceClient.getHostInfoForIP(ctx, ip)
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	info, err := dtc.readResponseForHostInfo(responseData, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	dtc.hostCache.set(ip, *info, dtc.currentTime())
+	return info, nil
+}
+
+func (dtc *dynatraceClientV2) readResponseForHostInfo(response []byte, ip string) (*hostInfo, error) {
+	type entity struct {
+		EntityID   string `json:"entityId"`
+		Properties struct {
+			NetworkZone      string `json:"networkZone"`
+			InstallerVersion string `json:"installerVersion"`
+		} `json:"properties"`
+	}
+	var jr struct {
+		Entities []entity `json:"entities"`
+	}
+
+	if err := json.Unmarshal(response, &jr); err != nil {
+		return nil, fmt.Errorf("error unmarshalling json response: %w", err)
+	}
+
+	var hosts []entity
+	for _, e := range jr.Entities {
+		nz := e.Properties.NetworkZone
+		if (dtc.networkZone != "" && nz == dtc.networkZone) || (dtc.networkZone == "" && (nz == "default" || nz == "")) {
+			hosts = append(hosts, e)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, errors.New("host not found")
+	} else if len(hosts) > 1 {
+		ids := make([]string, len(hosts))
+		for i, host := range hosts {
+			ids[i] = host.EntityID
+		}
+		dtc.logger.Info("several hosts found for ip, using the first one", "ip", ip, "ids", ids)
+	}
+
+	return &hostInfo{entityID: hosts[0].EntityID, version: hosts[0].Properties.InstallerVersion}, nil
+}
+
+// SendEvent posts the event to the v2 events ingest endpoint.
+func (dtc *dynatraceClientV2) SendEvent(ctx context.Context, eventData *EventData) error {
+	if eventData == nil {
+		return errors.New("no data found in eventData payload")
+	}
+	if eventData.EventType == "" {
+		return errors.New("no key set for eventType in eventData payload")
+	}
+	if dtc.usesV1(endpointEventsIngest) {
+		return dtc.dynatraceClient.SendEvent(ctx, eventData)
+	}
+
+	event := eventIngest{
+		EventType: eventData.EventType,
+		Title:     eventData.Description,
+		StartTime: eventData.StartInMillis,
+		EndTime:   eventData.EndInMillis,
+		Properties: map[string]string{
+			"dt.event.description": eventData.Description,
+			"source":               eventData.Source,
+		},
+	}
+	if eventData.AnnotationType != "" {
+		event.Properties["annotationType"] = eventData.AnnotationType
+		event.Properties["annotationDescription"] = eventData.AnnotationDescription
+	}
+	for k, v := range eventData.CustomProperties {
+		event.Properties[k] = v
+	}
+	if ids := eventData.AttachRules.EntityIDs; len(ids) > 0 {
+		event.EntitySelector = entityIDSelector(ids)
+	}
+
+	resp, err := dtc.makePostRequest(ctx, fmt.Sprintf("%s/v2/events/ingest", dtc.url), dtc.apiToken, event)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		//Swallow error, nothing has to be done at this point
+		_ = resp.Body.Close()
+	}()
+
+	_, err = dtc.getServerResponseData(resp)
+	if dtc.fallBackToV1(endpointEventsIngest, err) {
+		return dtc.dynatraceClient.SendEvent(ctx, eventData)
+	}
+	return errors.WithStack(err)
+}
+
+// eventIngest is the payload of the v2 events ingest endpoint.
+type eventIngest struct {
+	EventType      string            `json:"eventType"`
+	Title          string            `json:"title"`
+	StartTime      uint64            `json:"startTime,omitempty"`
+	EndTime        uint64            `json:"endTime,omitempty"`
+	EntitySelector string            `json:"entitySelector,omitempty"`
+	Properties     map[string]string `json:"properties,omitempty"`
+}
+
+func entityIDSelector(ids []string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = fmt.Sprintf("%q", id)
+	}
+	return fmt.Sprintf("entityId(%s)", strings.Join(quoted, ","))
+}
+
+// GetTokenScopes looks up the token through the v2 API tokens endpoint.
+func (dtc *dynatraceClientV2) GetTokenScopes(ctx context.Context, token string) (TokenScopes, error) {
+	if dtc.usesV1(endpointAPITokensLookup) {
+		return dtc.dynatraceClient.GetTokenScopes(ctx, token)
+	}
+
+	var model struct {
+		Token string `json:"token"`
+	}
+	model.Token = token
+
+	resp, err := dtc.makePostRequest(ctx, fmt.Sprintf("%s/v2/apiTokens/lookup", dtc.url), token, model)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		//Swallow error, nothing has to be done at this point
+		_ = resp.Body.Close()
+	}()
+
+	data, err := dtc.getServerResponseData(resp)
+	if dtc.fallBackToV1(endpointAPITokensLookup, err) {
+		return dtc.dynatraceClient.GetTokenScopes(ctx, token)
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return dtc.readResponseForTokenScopes(data)
+}
+
+// makePostRequest sends the payload as JSON to the given URL, authenticated with token. The response body must be
+// closed by the caller when no longer used.
+func (dtc *dynatraceClientV2) makePostRequest(ctx context.Context, url, token string, payload interface{}) (*http.Response, error) {
+	jsonStr, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return nil, fmt.Errorf("error initializing http request: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Api-Token %s", token))
+
+	resp, err := dtc.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making post request to dynatrace api: %w", err)
+	}
+	return resp, nil
+}
+
+// fallBackToV1 returns true if err shows that the cluster doesn't provide the v2 endpoint, in which case the endpoint
+// is remembered so further requests are sent to the v1 API directly.
+func (dtc *dynatraceClientV2) fallBackToV1(endpoint string, err error) bool {
+	var serr ServerError
+	if !errors.As(err, &serr) || serr.Code != http.StatusNotFound {
+		return false
+	}
+
+	dtc.logger.Info("Dynatrace API v2 endpoint not available, falling back to v1", "endpoint", endpoint)
+
+	v1Fallbacks.Lock()
+	defer v1Fallbacks.Unlock()
+
+	if v1Fallbacks.endpoints[dtc.url] == nil {
+		v1Fallbacks.endpoints[dtc.url] = make(map[string]bool)
+	}
+	v1Fallbacks.endpoints[dtc.url][endpoint] = true
+	return true
+}
@@ -0,0 +1,215 @@
+package dtclient
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientWithAPIV2(t *testing.T) {
+	dtc, err := NewClient("https://test-tenant.live.dynatrace.com/api", apiToken, paasToken, UseAPIV2(true))
+	require.NoError(t, err)
+	assert.IsType(t, &dynatraceClientV2{}, dtc)
+
+	dtc, err = NewClient("https://test-tenant.live.dynatrace.com/api", apiToken, paasToken, UseAPIV2(false))
+	require.NoError(t, err)
+	assert.IsType(t, &dynatraceClient{}, dtc)
+}
+
+func newTestClientV2(t *testing.T, handler http.Handler, opts ...Option) (*httptest.Server, *dynatraceClientV2) {
+	server := httptest.NewServer(handler)
+
+	dtc, err := NewClient(server.URL, apiToken, paasToken, append(opts, UseAPIV2(true), Retries(0, 0))...)
+	require.NoError(t, err)
+
+	return server, dtc.(*dynatraceClientV2)
+}
+
+func TestGetEntityIDForIPV2(t *testing.T) {
+	const entitiesResponse = `{
+	"totalCount": 2,
+	"pageSize": 50,
+	"entities": [
+		{"entityId": "HOST-42", "type": "HOST", "properties": {"networkZone": "default", "installerVersion": "1.215.0.20210415-123456"}},
+		{"entityId": "HOST-84", "type": "HOST", "properties": {"networkZone": "zone-a"}}
+	]
+}`
+
+	var query string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/entities" {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+		query = r.URL.Query().Get("entitySelector")
+		_, _ = w.Write([]byte(entitiesResponse))
+	})
+
+	t.Run(`host is looked up through an entity selector`, func(t *testing.T) {
+		server, dtc := newTestClientV2(t, handler)
+		defer server.Close()
+
+		entityID, err := dtc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, "HOST-42", entityID)
+		assert.Equal(t, `type("HOST"),ipAddress("1.1.1.1")`, query)
+	})
+	t.Run(`agent version is read from the same entity`, func(t *testing.T) {
+		server, dtc := newTestClientV2(t, handler)
+		defer server.Close()
+
+		version, err := dtc.GetAgentVersionForIP(context.TODO(), "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, "1.215.0.20210415-123456", version)
+
+		query = ""
+		entityID, err := dtc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, "HOST-42", entityID)
+		assert.Empty(t, query, "host is served from the cache")
+	})
+	t.Run(`hosts are filtered by network zone`, func(t *testing.T) {
+		server, dtc := newTestClientV2(t, handler, NetworkZone("zone-a"))
+		defer server.Close()
+
+		entityID, err := dtc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, "HOST-84", entityID)
+	})
+	t.Run(`no matching host is an error`, func(t *testing.T) {
+		server, dtc := newTestClientV2(t, handler, NetworkZone("zone-b"))
+		defer server.Close()
+
+		_, err := dtc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
+		assert.EqualError(t, err, "host not found")
+	})
+	t.Run(`empty ip is an error`, func(t *testing.T) {
+		server, dtc := newTestClientV2(t, handler)
+		defer server.Close()
+
+		_, err := dtc.GetEntityIDForIP(context.TODO(), "")
+		assert.Error(t, err)
+	})
+}
+
+func TestFallbackToV1(t *testing.T) {
+	var requests []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/v1/entity/infrastructure/hosts", "/v1/events", "/v1/tokens/lookup":
+			handleRequest(r, w)
+		default:
+			writeError(w, http.StatusNotFound)
+		}
+	})
+
+	server, dtc := newTestClientV2(t, handler)
+	defer server.Close()
+	dtc.now = time.Unix(1521540000, 0)
+
+	t.Run(`entities`, func(t *testing.T) {
+		requests = nil
+
+		entityID, err := dtc.GetEntityIDForIP(context.TODO(), "10.11.12.13")
+		require.NoError(t, err)
+		assert.Equal(t, "dynatraceSampleEntityId", entityID)
+		assert.Equal(t, []string{"/v2/entities", "/v1/entity/infrastructure/hosts"}, requests)
+		assert.True(t, dtc.usesV1(endpointEntities))
+
+		requests = nil
+
+		newDTC, err := NewClient(dtc.url, apiToken, paasToken, UseAPIV2(true), Retries(0, 0))
+		require.NoError(t, err)
+		newDTC.(*dynatraceClientV2).now = dtc.now
+		newDTC.(*dynatraceClientV2).hostCache = newHostCache()
+
+		entityID, err = newDTC.GetEntityIDForIP(context.TODO(), "10.11.12.13")
+		require.NoError(t, err)
+		assert.Equal(t, "dynatraceSampleEntityId", entityID)
+		assert.Equal(t, []string{"/v1/entity/infrastructure/hosts"}, requests, "fallback is kept for new clients of the same tenant")
+	})
+	t.Run(`token scopes`, func(t *testing.T) {
+		requests = nil
+
+		scopes, err := dtc.GetTokenScopes(context.TODO(), "good-token")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"DataExport", "LogExport"}, scopes)
+		assert.Equal(t, []string{"/v2/apiTokens/lookup", "/v1/tokens/lookup"}, requests)
+
+		requests = nil
+
+		_, err = dtc.GetTokenScopes(context.TODO(), "good-token")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/v1/tokens/lookup"}, requests)
+	})
+	t.Run(`events`, func(t *testing.T) {
+		requests = nil
+
+		err := dtc.SendEvent(context.TODO(), &EventData{EventType: MarkedForTerminationEvent})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/v2/events/ingest", "/v1/events"}, requests)
+	})
+}
+
+func TestSendEventV2(t *testing.T) {
+	var event eventIngest
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/events/ingest" || r.Method != "POST" {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &event); err != nil {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"reportCount":1,"eventIngestResults":[{"status":"OK"}]}`))
+	})
+
+	server, dtc := newTestClientV2(t, handler)
+	defer server.Close()
+
+	err := dtc.SendEvent(context.TODO(), &EventData{
+		EventType:     MarkedForTerminationEvent,
+		Source:        "OneAgent Operator",
+		Description:   "Kubernetes node cordoned.",
+		StartInMillis: 1000,
+		EndInMillis:   1000,
+		AttachRules:   EventDataAttachRules{EntityIDs: []string{"HOST-42", "HOST-84"}},
+		CustomProperties: map[string]string{
+			"reason": "manual-drain",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, MarkedForTerminationEvent, event.EventType)
+	assert.Equal(t, "Kubernetes node cordoned.", event.Title)
+	assert.Equal(t, uint64(1000), event.StartTime)
+	assert.Equal(t, `entityId("HOST-42","HOST-84")`, event.EntitySelector)
+	assert.Equal(t, "OneAgent Operator", event.Properties["source"])
+	assert.Equal(t, "manual-drain", event.Properties["reason"])
+
+	err = dtc.SendEvent(context.TODO(), &EventData{
+		EventType:             CustomAnnotationEvent,
+		Description:           "Kubernetes node drained",
+		AnnotationType:        "Kubernetes node lifecycle",
+		AnnotationDescription: "manual-drain",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Kubernetes node lifecycle", event.Properties["annotationType"])
+	assert.Equal(t, "manual-drain", event.Properties["annotationDescription"])
+
+	assert.Error(t, dtc.SendEvent(context.TODO(), nil))
+	assert.Error(t, dtc.SendEvent(context.TODO(), &EventData{}))
+}
@@ -0,0 +1,126 @@
+package dtclient
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// hostCacheTTL is how long a host stays in the cache before it is looked up again.
+	hostCacheTTL = 15 * time.Minute
+
+	// hostsPageSize is the number of hosts requested per page from the v1 hosts endpoint.
+	hostsPageSize = 500
+)
+
+// hostCaches holds the host caches of all clients, keyed by tenant and network zone, so they can be reused across
+// reconciles even though a new client is created for each of them.
+var hostCaches = struct {
+	sync.Mutex
+	caches map[string]*hostCache
+}{caches: make(map[string]*hostCache)}
+
+func sharedHostCache(url, networkZone string) *hostCache {
+	hostCaches.Lock()
+	defer hostCaches.Unlock()
+
+	key := url + "|" + networkZone
+	cache, ok := hostCaches.caches[key]
+	if !ok {
+		cache = newHostCache()
+		hostCaches.caches[key] = cache
+	}
+	return cache
+}
+
+// hostCache maps IP addresses to the hosts seen on them, whose entries expire after hostCacheTTL.
+type hostCache struct {
+	mu    sync.Mutex
+	hosts map[string]hostCacheEntry
+
+	// nextPageKey is set while the host list has only been read partially, so the next lookup of an unknown IP can
+	// continue where the previous one stopped.
+	nextPageKey string
+
+	// completedAt is when the host list was last read to the end. Unknown IPs are not looked up again until this is
+	// older than hostCacheTTL.
+	completedAt time.Time
+}
+
+type hostCacheEntry struct {
+	hostInfo
+	expiresAt time.Time
+}
+
+func newHostCache() *hostCache {
+	return &hostCache{hosts: make(map[string]hostCacheEntry)}
+}
+
+func (c *hostCache) get(ip string, now time.Time) (hostInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.hosts[ip]
+	if !ok || now.After(entry.expiresAt) {
+		return hostInfo{}, false
+	}
+	return entry.hostInfo, true
+}
+
+// set stores the host for ip and returns the entity id of the host it replaced, if any.
+func (c *hostCache) set(ip string, info hostInfo, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old, ok := c.hosts[ip]
+	c.hosts[ip] = hostCacheEntry{hostInfo: info, expiresAt: now.Add(hostCacheTTL)}
+
+	if ok && !now.After(old.expiresAt) && old.entityID != info.entityID {
+		return old.entityID, true
+	}
+	return "", false
+}
+
+// resumePoint returns the page key to continue reading the host list from, which is empty to start from the first
+// page. Returns false if the list has been read to the end recently, so there are no hosts to be found.
+func (c *hostCache) resumePoint(now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.nextPageKey != "" {
+		return c.nextPageKey, true
+	}
+	if !c.completedAt.IsZero() && now.Sub(c.completedAt) < hostCacheTTL {
+		return "", false
+	}
+
+	c.pruneExpired(now)
+	return "", true
+}
+
+// pageRead records how far the host list has been read. An empty nextPageKey marks the list as read to the end.
+func (c *hostCache) pageRead(nextPageKey string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextPageKey = nextPageKey
+	if nextPageKey == "" {
+		c.completedAt = now
+	}
+}
+
+// restart discards the position the host list has been read up to, so it is read from the first page again.
+func (c *hostCache) restart() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextPageKey = ""
+}
+
+func (c *hostCache) pruneExpired(now time.Time) {
+	for ip, entry := range c.hosts {
+		if now.After(entry.expiresAt) {
+			delete(c.hosts, ip)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package dtclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostCache(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run(`entries expire after ttl`, func(t *testing.T) {
+		cache := newHostCache()
+		cache.set("1.1.1.1", hostInfo{entityID: "HOST-42"}, now)
+
+		info, ok := cache.get("1.1.1.1", now.Add(hostCacheTTL))
+		assert.True(t, ok)
+		assert.Equal(t, "HOST-42", info.entityID)
+
+		_, ok = cache.get("1.1.1.1", now.Add(hostCacheTTL+time.Second))
+		assert.False(t, ok)
+	})
+	t.Run(`replaced hosts are reported`, func(t *testing.T) {
+		cache := newHostCache()
+
+		_, replaced := cache.set("1.1.1.1", hostInfo{entityID: "HOST-42"}, now)
+		assert.False(t, replaced)
+
+		old, replaced := cache.set("1.1.1.1", hostInfo{entityID: "HOST-84"}, now)
+		assert.True(t, replaced)
+		assert.Equal(t, "HOST-42", old)
+	})
+	t.Run(`host list is not read again until ttl passed`, func(t *testing.T) {
+		cache := newHostCache()
+
+		key, ok := cache.resumePoint(now)
+		assert.True(t, ok)
+		assert.Empty(t, key)
+
+		cache.pageRead("page-2", now)
+		key, ok = cache.resumePoint(now)
+		assert.True(t, ok)
+		assert.Equal(t, "page-2", key)
+
+		cache.pageRead("", now)
+		_, ok = cache.resumePoint(now.Add(time.Minute))
+		assert.False(t, ok)
+
+		key, ok = cache.resumePoint(now.Add(hostCacheTTL))
+		assert.True(t, ok)
+		assert.Empty(t, key)
+	})
+	t.Run(`caches are shared by tenant and network zone`, func(t *testing.T) {
+		assert.Same(t, sharedHostCache("https://tenant-a/api", ""), sharedHostCache("https://tenant-a/api", ""))
+		assert.NotSame(t, sharedHostCache("https://tenant-a/api", ""), sharedHostCache("https://tenant-b/api", ""))
+		assert.NotSame(t, sharedHostCache("https://tenant-a/api", ""), sharedHostCache("https://tenant-a/api", "zone"))
+	})
+}
+
+func TestReadHostsPaginated(t *testing.T) {
+	now := time.Unix(1521540000, 0)
+	host := func(id, ip string) string {
+		return fmt.Sprintf(`{"entityId": "%s", "lastSeenTimestamp": %d, "ipAddresses": ["%s"]}`, id, now.Unix()*1000, ip)
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("nextPageKey"))
+
+		switch r.URL.Query().Get("nextPageKey") {
+		case "":
+			w.Header().Set("Next-Page-Key", "page-2")
+			_, _ = w.Write([]byte(fmt.Sprintf("[%s]", host("HOST-1", "1.1.1.1"))))
+		case "page-2":
+			w.Header().Set("Next-Page-Key", "page-3")
+			_, _ = w.Write([]byte(fmt.Sprintf("[%s]", host("HOST-2", "2.2.2.2"))))
+		case "page-3":
+			_, _ = w.Write([]byte(fmt.Sprintf("[%s]", host("HOST-3", "3.3.3.3"))))
+		default:
+			writeError(w, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	dc := &dynatraceClient{
+		url:        server.URL,
+		apiToken:   apiToken,
+		paasToken:  paasToken,
+		now:        now,
+		logger:     consoleLogger,
+		hostCache:  newHostCache(),
+		httpClient: http.DefaultClient,
+	}
+
+	hits := hostCacheLookups.WithLabelValues(hostCacheHit)
+	misses := hostCacheLookups.WithLabelValues(hostCacheMiss)
+	hitsBefore, missesBefore := testutil.ToFloat64(hits), testutil.ToFloat64(misses)
+
+	entityID, err := dc.GetEntityIDForIP(context.TODO(), "2.2.2.2")
+	require.NoError(t, err)
+	assert.Equal(t, "HOST-2", entityID)
+	assert.Equal(t, []string{"", "page-2"}, requests, "reading stops at the page with the host")
+
+	entityID, err = dc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "HOST-1", entityID)
+	assert.Len(t, requests, 2, "host is served from the cache")
+
+	entityID, err = dc.GetEntityIDForIP(context.TODO(), "3.3.3.3")
+	require.NoError(t, err)
+	assert.Equal(t, "HOST-3", entityID)
+	assert.Equal(t, []string{"", "page-2", "page-3"}, requests, "reading continues where it stopped")
+
+	_, err = dc.GetEntityIDForIP(context.TODO(), "4.4.4.4")
+	assert.Error(t, err)
+	assert.Len(t, requests, 3, "unknown hosts don't cause the list to be read again")
+
+	assert.Equal(t, hitsBefore+1, testutil.ToFloat64(hits))
+	assert.Equal(t, missesBefore+3, testutil.ToFloat64(misses))
+}
+
+func TestReadHostsWithExpiredPageKey(t *testing.T) {
+	now := time.Unix(1521540000, 0)
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("nextPageKey"))
+
+		if r.URL.Query().Get("nextPageKey") != "" {
+			writeError(w, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`[{"entityId": "HOST-1", "lastSeenTimestamp": %d, "ipAddresses": ["1.1.1.1"]}]`, now.Unix()*1000)))
+	}))
+	defer server.Close()
+
+	dc := &dynatraceClient{
+		url:        server.URL,
+		apiToken:   apiToken,
+		now:        now,
+		logger:     consoleLogger,
+		hostCache:  newHostCache(),
+		httpClient: http.DefaultClient,
+	}
+	dc.hostCache.pageRead("expired", now)
+
+	entityID, err := dc.GetEntityIDForIP(context.TODO(), "1.1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "HOST-1", entityID)
+	assert.Equal(t, []string{"expired", ""}, requests)
+}
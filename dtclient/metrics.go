@@ -0,0 +1,119 @@
+package dtclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "dynatrace_operator"
+	metricsSubsystem = "dtclient"
+
+	endpointConnectionInfo = "connectioninfo"
+	endpointLatestMetaInfo = "latest_metainfo"
+	endpointLatestAgent    = "latest_agent"
+	endpointAgent          = "agent"
+	endpointAgentVersions  = "agent_versions"
+	endpointTokensLookup   = "tokens_lookup"
+	endpointHosts          = "hosts"
+	endpointEvents         = "events"
+	endpointOther          = "other"
+
+	endpointEntities        = "entities"
+	endpointEventsIngest    = "events_ingest"
+	endpointAPITokensLookup = "api_tokens_lookup"
+
+	hostCacheHit  = "hit"
+	hostCacheMiss = "miss"
+
+	// statusCodeError is used as status code label for requests which didn't get a response.
+	statusCodeError = "error"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "requests_total",
+		Help:      "Number of requests sent to the Dynatrace API, by endpoint, status code and DynaKube.",
+	}, []string{"endpoint", "status_code", "dynakube"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests sent to the Dynatrace API, by endpoint, status code and DynaKube.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"endpoint", "status_code", "dynakube"})
+
+	hostCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "host_cache_lookups_total",
+		Help:      "Number of host lookups by IP, by whether they were answered from the host cache.",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration, hostCacheLookups)
+}
+
+// do sends the request and records its outcome and latency.
+func (dtc *dynatraceClient) do(req *http.Request) (*http.Response, error) {
+	endpoint := endpointFor(req.URL.Path)
+
+	httpClient := dtc.httpClient
+	if (endpoint == endpointLatestAgent || endpoint == endpointAgent) && httpClient.Timeout > 0 {
+		// Downloads take considerably longer than API requests, so they are only bound by the request's context.
+		downloadClient := *httpClient
+		downloadClient.Timeout = 0
+		httpClient = &downloadClient
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+
+	statusCode := statusCodeError
+	if err == nil {
+		statusCode = strconv.Itoa(resp.StatusCode)
+	}
+
+	requestsTotal.WithLabelValues(endpoint, statusCode, dtc.dynakube).Inc()
+	requestDuration.WithLabelValues(endpoint, statusCode, dtc.dynakube).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+// endpointFor maps the path of a request to a label with low cardinality.
+func endpointFor(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/v1/deployment/installer/agent/connectioninfo"):
+		return endpointConnectionInfo
+	case strings.Contains(path, "/v1/deployment/installer/agent/") && strings.HasSuffix(path, "/latest/metainfo"):
+		return endpointLatestMetaInfo
+	case strings.Contains(path, "/v1/deployment/installer/agent/") && strings.HasSuffix(path, "/latest"):
+		return endpointLatestAgent
+	case strings.Contains(path, "/v1/deployment/installer/agent/") && strings.Contains(path, "/version/"):
+		return endpointAgent
+	case strings.Contains(path, "/v1/deployment/installer/agent/versions/"):
+		return endpointAgentVersions
+	case strings.HasSuffix(path, "/v1/tokens/lookup"):
+		return endpointTokensLookup
+	case strings.HasSuffix(path, "/v1/entity/infrastructure/hosts"):
+		return endpointHosts
+	case strings.HasSuffix(path, "/v1/events"):
+		return endpointEvents
+	case strings.HasSuffix(path, "/v2/entities"):
+		return endpointEntities
+	case strings.HasSuffix(path, "/v2/events/ingest"):
+		return endpointEventsIngest
+	case strings.HasSuffix(path, "/v2/apiTokens/lookup"):
+		return endpointAPITokensLookup
+	}
+	return endpointOther
+}
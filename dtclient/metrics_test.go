@@ -0,0 +1,53 @@
+package dtclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointFor(t *testing.T) {
+	assert.Equal(t, endpointConnectionInfo, endpointFor("/e/tenant/api/v1/deployment/installer/agent/connectioninfo"))
+	assert.Equal(t, endpointLatestMetaInfo, endpointFor("/api/v1/deployment/installer/agent/unix/paas/latest/metainfo"))
+	assert.Equal(t, endpointLatestAgent, endpointFor("/api/v1/deployment/installer/agent/unix/paas/latest"))
+	assert.Equal(t, endpointAgent, endpointFor("/api/v1/deployment/installer/agent/unix/paas/version/1.213.0.20210301-123456"))
+	assert.Equal(t, endpointAgentVersions, endpointFor("/api/v1/deployment/installer/agent/versions/unix/default"))
+	assert.Equal(t, endpointTokensLookup, endpointFor("/api/v1/tokens/lookup"))
+	assert.Equal(t, endpointHosts, endpointFor("/api/v1/entity/infrastructure/hosts"))
+	assert.Equal(t, endpointEvents, endpointFor("/api/v1/events"))
+	assert.Equal(t, endpointEntities, endpointFor("/api/v2/entities"))
+	assert.Equal(t, endpointEventsIngest, endpointFor("/api/v2/events/ingest"))
+	assert.Equal(t, endpointAPITokensLookup, endpointFor("/api/v2/apiTokens/lookup"))
+	assert.Equal(t, endpointOther, endpointFor("/api/v2/settings/objects"))
+}
+
+func TestRequestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"code":401,"message":"Token Authentication failed"}}`))
+	}))
+	defer server.Close()
+
+	dc := &dynatraceClient{
+		url:        server.URL,
+		apiToken:   apiToken,
+		paasToken:  paasToken,
+		logger:     consoleLogger,
+		dynakube:   "metrics-dynakube",
+		hostCache:  newHostCache(),
+		httpClient: http.DefaultClient,
+	}
+
+	requests := requestsTotal.WithLabelValues(endpointTokensLookup, "401", "metrics-dynakube")
+	before := testutil.ToFloat64(requests)
+
+	_, err := dc.GetTokenScopes(context.TODO(), apiToken)
+	require.Error(t, err)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(requests))
+}
@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"io"
 
 	"github.com/stretchr/testify/mock"
@@ -11,22 +12,32 @@ type MockDynatraceClient struct {
 	mock.Mock
 }
 
-func (o *MockDynatraceClient) GetTenantInfo() (*TenantInfo, error) {
+func (o *MockDynatraceClient) GetTenantInfo(_ context.Context) (*TenantInfo, error) {
 	args := o.Called()
 	return args.Get(0).(*TenantInfo), args.Error(1)
 }
 
-func (o *MockDynatraceClient) GetLatestAgentVersion(os, installerType string) (string, error) {
+func (o *MockDynatraceClient) GetLatestAgentVersion(_ context.Context, os, installerType string) (string, error) {
 	args := o.Called(os, installerType)
 	return args.String(0), args.Error(1)
 }
 
-func (o *MockDynatraceClient) GetLatestAgent(os, installerType, flavor, arch string) (io.ReadCloser, error) {
+func (o *MockDynatraceClient) GetAgentVersions(_ context.Context, os, installerType string) ([]string, error) {
+	args := o.Called(os, installerType)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (o *MockDynatraceClient) GetLatestAgent(_ context.Context, os, installerType, flavor, arch string) (io.ReadCloser, error) {
 	args := o.Called(os, installerType, flavor, arch)
 	return args.Get(0).(io.ReadCloser), args.Error(1)
 }
 
-func (o *MockDynatraceClient) GetConnectionInfo() (ConnectionInfo, error) {
+func (o *MockDynatraceClient) GetAgent(_ context.Context, os, installerType, flavor, arch, version string) (io.ReadCloser, error) {
+	args := o.Called(os, installerType, flavor, arch, version)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (o *MockDynatraceClient) GetConnectionInfo(_ context.Context) (ConnectionInfo, error) {
 	args := o.Called()
 	return args.Get(0).(ConnectionInfo), args.Error(1)
 }
@@ -36,17 +47,22 @@ func (o *MockDynatraceClient) GetCommunicationHostForClient() (CommunicationHost
 	return args.Get(0).(CommunicationHost), args.Error(1)
 }
 
-func (o *MockDynatraceClient) SendEvent(event *EventData) error {
+func (o *MockDynatraceClient) SendEvent(_ context.Context, event *EventData) error {
 	args := o.Called(event)
 	return args.Error(0)
 }
 
-func (o *MockDynatraceClient) GetEntityIDForIP(ip string) (string, error) {
+func (o *MockDynatraceClient) GetEntityIDForIP(_ context.Context, ip string) (string, error) {
+	args := o.Called(ip)
+	return args.String(0), args.Error(1)
+}
+
+func (o *MockDynatraceClient) GetAgentVersionForIP(_ context.Context, ip string) (string, error) {
 	args := o.Called(ip)
 	return args.String(0), args.Error(1)
 }
 
-func (o *MockDynatraceClient) GetTokenScopes(token string) (TokenScopes, error) {
+func (o *MockDynatraceClient) GetTokenScopes(_ context.Context, token string) (TokenScopes, error) {
 	args := o.Called(token)
 	return args.Get(0).(TokenScopes), args.Error(1)
 }
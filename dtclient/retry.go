@@ -0,0 +1,130 @@
+package dtclient
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+
+	// maxRetryDelay is the longest the client waits in-process before retrying a request. If the server asks for a
+	// longer delay through the Retry-After header, the request is not retried and the delay is returned to the caller
+	// as part of the ServerError instead.
+	maxRetryDelay = 10 * time.Second
+
+	// defaultRateLimitDelay is used as retry hint for rate limited requests whose response had no Retry-After header.
+	defaultRateLimitDelay = 1 * time.Minute
+)
+
+// doWithRetry sends the request and retries it with jittered exponential backoff on connection errors, rate limiting
+// and transient server errors. Only requests without a body must be given, since they are sent several times.
+func (dtc *dynatraceClient) doWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := dtc.do(req)
+		if attempt >= dtc.maxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		delay := dtc.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header, dtc.currentTime()); ok {
+				if retryAfter > maxRetryDelay {
+					return resp, err
+				}
+				delay = retryAfter
+			}
+			drainAndClose(resp.Body)
+		}
+
+		dtc.logger.Info("retrying request to Dynatrace API", "url", req.URL.Path, "attempt", attempt+1, "delay", delay)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns the delay before the given retry attempt, which is picked randomly between half and the full
+// exponential delay to avoid several operator instances retrying in lockstep.
+func (dtc *dynatraceClient) backoff(attempt int) time.Duration {
+	delay := dtc.retryBaseDelay << uint(attempt)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	half := int64(delay / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+func (dtc *dynatraceClient) currentTime() time.Time {
+	if dtc.now.IsZero() {
+		return time.Now().UTC()
+	}
+	return dtc.now
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads the Retry-After header, which can either hold a number of seconds or an HTTP date.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	raw := header.Get("Retry-After")
+	if raw == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(raw); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(raw); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func drainAndClose(body io.ReadCloser) {
+	// Reading the body to completion allows the connection to be reused.
+	_, _ = io.Copy(ioutil.Discard, body)
+	_ = body.Close()
+}
+
+// RetryAfter returns how long a caller should wait before sending further requests if err was caused by the
+// Dynatrace API rate limiting requests or being temporarily unavailable.
+func RetryAfter(err error) (time.Duration, bool) {
+	var serr ServerError
+	if !errors.As(err, &serr) {
+		return 0, false
+	}
+
+	switch serr.Code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if serr.RetryAfter > 0 {
+			return serr.RetryAfter, true
+		}
+		return defaultRateLimitDelay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,156 @@
+package dtclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetryingClient(url string, maxRetries int) *dynatraceClient {
+	return &dynatraceClient{
+		url:            url,
+		apiToken:       apiToken,
+		paasToken:      paasToken,
+		logger:         consoleLogger,
+		hostCache:      newHostCache(),
+		httpClient:     http.DefaultClient,
+		maxRetries:     maxRetries,
+		retryBaseDelay: time.Millisecond,
+	}
+}
+
+func TestMakeRequestWithRetries(t *testing.T) {
+	t.Run(`retries until the request succeeds`, func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		dc := newRetryingClient(server.URL, 3)
+		resp, err := dc.makeRequest(context.TODO(), server.URL, dynatraceApiToken)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 3, requests)
+	})
+	t.Run(`gives up after max retries`, func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		dc := newRetryingClient(server.URL, 2)
+		resp, err := dc.makeRequest(context.TODO(), server.URL, dynatraceApiToken)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, 3, requests)
+	})
+	t.Run(`does not retry client errors`, func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		dc := newRetryingClient(server.URL, 3)
+		resp, err := dc.makeRequest(context.TODO(), server.URL, dynatraceApiToken)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, 1, requests)
+	})
+	t.Run(`long Retry-After is returned to the caller`, func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Too many requests"}}`))
+		}))
+		defer server.Close()
+
+		dc := newRetryingClient(server.URL, 3)
+		_, err := dc.GetLatestAgentVersion(context.TODO(), OsUnix, InstallerTypePaaS)
+		require.Error(t, err)
+		assert.Equal(t, 1, requests)
+
+		retryAfter, ok := RetryAfter(err)
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Minute, retryAfter)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	header := func(value string) http.Header {
+		h := http.Header{}
+		h.Set("Retry-After", value)
+		return h
+	}
+
+	_, ok := parseRetryAfter(http.Header{}, now)
+	assert.False(t, ok)
+
+	delay, ok := parseRetryAfter(header("30"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	delay, ok = parseRetryAfter(header(now.Add(90*time.Second).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, delay)
+
+	_, ok = parseRetryAfter(header("soon"), now)
+	assert.False(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	dc := newRetryingClient("", 3)
+	dc.retryBaseDelay = 100 * time.Millisecond
+
+	for attempt := 0; attempt < 10; attempt++ {
+		expected := dc.retryBaseDelay << uint(attempt)
+		if expected > maxRetryDelay {
+			expected = maxRetryDelay
+		}
+
+		delay := dc.backoff(attempt)
+		assert.GreaterOrEqual(t, int64(delay), int64(expected/2))
+		assert.LessOrEqual(t, int64(delay), int64(expected))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	_, ok := RetryAfter(fmt.Errorf("some error"))
+	assert.False(t, ok)
+
+	_, ok = RetryAfter(ServerError{Code: http.StatusUnauthorized})
+	assert.False(t, ok)
+
+	retryAfter, ok := RetryAfter(errors.WithStack(ServerError{Code: http.StatusTooManyRequests}))
+	assert.True(t, ok)
+	assert.Equal(t, defaultRateLimitDelay, retryAfter)
+
+	retryAfter, ok = RetryAfter(fmt.Errorf("wrapped: %w", ServerError{Code: http.StatusServiceUnavailable, RetryAfter: 5 * time.Second}))
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, retryAfter)
+}
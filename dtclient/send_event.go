@@ -2,6 +2,7 @@ package dtclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,6 +12,8 @@ import (
 
 const (
 	MarkedForTerminationEvent = "MARKED_FOR_TERMINATION"
+	CustomInfoEvent           = "CUSTOM_INFO"
+	CustomAnnotationEvent     = "CUSTOM_ANNOTATION"
 )
 
 // EventData struct which defines what event payload should contain
@@ -21,13 +24,20 @@ type EventData struct {
 	Description   string               `json:"description"`
 	AttachRules   EventDataAttachRules `json:"attachRules"`
 	Source        string               `json:"source"`
+
+	// AnnotationType and AnnotationDescription are required for CUSTOM_ANNOTATION events
+	AnnotationType        string `json:"annotationType,omitempty"`
+	AnnotationDescription string `json:"annotationDescription,omitempty"`
+
+	// CustomProperties are additional key-value pairs shown on the event
+	CustomProperties map[string]string `json:"customProperties,omitempty"`
 }
 
 type EventDataAttachRules struct {
 	EntityIDs []string `json:"entityIds"`
 }
 
-func (dtc *dynatraceClient) SendEvent(eventData *EventData) error {
+func (dtc *dynatraceClient) SendEvent(ctx context.Context, eventData *EventData) error {
 	if eventData == nil {
 		return errors.New("no data found in eventData payload")
 	}
@@ -42,14 +52,14 @@ func (dtc *dynatraceClient) SendEvent(eventData *EventData) error {
 	}
 
 	url := fmt.Sprintf("%s/v1/events", dtc.url)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonStr))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonStr))
 	if err != nil {
 		return fmt.Errorf("error initializing http request: %s", err.Error())
 	}
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Authorization", fmt.Sprintf("Api-Token %s", dtc.apiToken))
 
-	response, err := dtc.httpClient.Do(req)
+	response, err := dtc.do(req)
 	if err != nil {
 		return fmt.Errorf("error making post request to dynatrace api: %s", err.Error())
 	}
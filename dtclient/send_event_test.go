@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -43,7 +44,7 @@ func TestSendEvent(t *testing.T) {
 		dynatraceServer, dynatraceClient := createTestDynatraceClient(t, sendEventHandlerStub())
 		defer dynatraceServer.Close()
 
-		err := dynatraceClient.SendEvent(nil)
+		err := dynatraceClient.SendEvent(context.TODO(), nil)
 		assert.Error(t, err)
 		assert.Equal(t, "no data found in eventData payload", err.Error())
 	})
@@ -51,27 +52,27 @@ func TestSendEvent(t *testing.T) {
 		dynatraceServer, dynatraceClient := createTestDynatraceClient(t, sendEventHandlerStub())
 		defer dynatraceServer.Close()
 
-		err := dynatraceClient.SendEvent(&empty)
+		err := dynatraceClient.SendEvent(context.TODO(), &empty)
 		assert.Error(t, err)
 		assert.Equal(t, "no key set for eventType in eventData payload", err.Error())
 
-		err = dynatraceClient.SendEvent(&eventTypeOnly)
+		err = dynatraceClient.SendEvent(context.TODO(), &eventTypeOnly)
 		assert.NoError(t, err)
 	})
 	t.Run("SendEvent request error", func(t *testing.T) {
 		dynatraceServer, dynatraceClient := createTestDynatraceClient(t, sendEventHandlerError())
 
-		err := dynatraceClient.SendEvent(&empty)
+		err := dynatraceClient.SendEvent(context.TODO(), &empty)
 		assert.Error(t, err)
 		assert.Equal(t, "no key set for eventType in eventData payload", err.Error())
 
-		err = dynatraceClient.SendEvent(&eventTypeOnly)
+		err = dynatraceClient.SendEvent(context.TODO(), &eventTypeOnly)
 		assert.Error(t, err)
 		assert.Equal(t, "dynatrace server error 500: error received from server", err.Error())
 
 		dynatraceServer.Close()
 
-		err = dynatraceClient.SendEvent(&eventTypeOnly)
+		err = dynatraceClient.SendEvent(context.TODO(), &eventTypeOnly)
 		assert.Error(t, err)
 		assert.True(t,
 			// Reason differs between local tests and travis test, so only check main error message
@@ -109,7 +110,7 @@ func testSendEvent(t *testing.T, dynatraceClient Client) {
 		err := json.Unmarshal(testValidEventData, &testEventData)
 		assert.NoError(t, err)
 
-		err = dynatraceClient.SendEvent(&testEventData)
+		err = dynatraceClient.SendEvent(context.TODO(), &testEventData)
 		assert.NoError(t, err)
 	}
 	{
@@ -126,7 +127,7 @@ func testSendEvent(t *testing.T, dynatraceClient Client) {
 		err := json.Unmarshal(testInvalidEventData, &testEventData)
 		assert.NoError(t, err)
 
-		err = dynatraceClient.SendEvent(&testEventData)
+		err = dynatraceClient.SendEvent(context.TODO(), &testEventData)
 		assert.Error(t, err, "no eventType set")
 	}
 	{
@@ -145,7 +146,7 @@ func testSendEvent(t *testing.T, dynatraceClient Client) {
 		err := json.Unmarshal(testExtraKeysEventData, &testEventData)
 		assert.NoError(t, err)
 
-		err = dynatraceClient.SendEvent(&testEventData)
+		err = dynatraceClient.SendEvent(context.TODO(), &testEventData)
 		assert.NoError(t, err)
 	}
 }
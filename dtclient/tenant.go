@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -15,9 +16,10 @@ type TenantInfo struct {
 	CommunicationEndpoint string
 }
 
-func (dtc *dynatraceClient) GetTenantInfo() (*TenantInfo, error) {
+func (dtc *dynatraceClient) GetTenantInfo(ctx context.Context) (*TenantInfo, error) {
 	url := fmt.Sprintf("%s/v1/deployment/installer/agent/connectioninfo", dtc.url)
 	response, err := dtc.makeRequest(
+		ctx,
 		url,
 		dynatracePaaSToken,
 	)
@@ -34,10 +36,7 @@ func (dtc *dynatraceClient) GetTenantInfo() (*TenantInfo, error) {
 
 	data, err := dtc.getServerResponseData(response)
 	if err != nil {
-		err = dtc.handleErrorResponseFromAPI(data, response.StatusCode)
-		if err != nil {
-			dtc.logger.Error(err, err.Error())
-		}
+		dtc.logger.Error(err, err.Error())
 		return nil, errors.WithStack(err)
 	}
 
@@ -1,6 +1,7 @@
 package dtclient
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -26,7 +27,7 @@ func TestTenant(t *testing.T) {
 		dynatraceServer, dynatraceClient := createTestDynatraceClient(t, tenantServerHandler())
 		defer dynatraceServer.Close()
 
-		tenantInfo, err := dynatraceClient.GetTenantInfo()
+		tenantInfo, err := dynatraceClient.GetTenantInfo(context.TODO())
 		assert.NoError(t, err)
 		assert.NotNil(t, tenantInfo)
 
@@ -41,7 +42,7 @@ func TestTenant(t *testing.T) {
 		faultyDynatraceServer, faultyDynatraceClient := createTestDynatraceClient(t, tenantInternalServerError())
 		defer faultyDynatraceServer.Close()
 
-		tenantInfo, err := faultyDynatraceClient.GetTenantInfo()
+		tenantInfo, err := faultyDynatraceClient.GetTenantInfo(context.TODO())
 		assert.Error(t, err)
 		assert.Nil(t, tenantInfo)
 
@@ -51,7 +52,7 @@ func TestTenant(t *testing.T) {
 		faultyDynatraceServer, faultyDynatraceClient := createTestDynatraceClient(t, tenantMalformedJson())
 		defer faultyDynatraceServer.Close()
 
-		tenantInfo, err := faultyDynatraceClient.GetTenantInfo()
+		tenantInfo, err := faultyDynatraceClient.GetTenantInfo(context.TODO())
 		assert.Error(t, err)
 		assert.Nil(t, tenantInfo)
 
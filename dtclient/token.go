@@ -2,6 +2,7 @@ package dtclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -22,7 +23,7 @@ func (s TokenScopes) Contains(scope string) bool {
 	return false
 }
 
-func (dtc *dynatraceClient) GetTokenScopes(token string) (TokenScopes, error) {
+func (dtc *dynatraceClient) GetTokenScopes(ctx context.Context, token string) (TokenScopes, error) {
 	var model struct {
 		Token string `json:"token"`
 	}
@@ -33,14 +34,14 @@ func (dtc *dynatraceClient) GetTokenScopes(token string) (TokenScopes, error) {
 		return nil, errors.WithStack(err)
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/tokens/lookup", dtc.url), bytes.NewBuffer(jsonStr))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/tokens/lookup", dtc.url), bytes.NewBuffer(jsonStr))
 	if err != nil {
 		return nil, fmt.Errorf("error initializing http request: %w", err)
 	}
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Authorization", fmt.Sprintf("Api-Token %s", token))
 
-	resp, err := dtc.httpClient.Do(req)
+	resp, err := dtc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error making post request to dynatrace api: %w", err)
 	}
@@ -5,7 +5,7 @@ package e2e
 import (
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,7 +17,7 @@ func CreateClient(t *testing.T) client.Client {
 	cfg, err := config.GetConfig()
 	assert.NoError(t, err)
 
-	err = dynatracev1alpha1.AddToScheme(scheme.Scheme)
+	err = dynatracev1beta1.AddToScheme(scheme.Scheme)
 	assert.NoError(t, err)
 
 	clt, err := client.New(cfg, client.Options{
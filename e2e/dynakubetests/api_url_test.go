@@ -9,7 +9,7 @@ import (
 	"os"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/e2e"
 	"github.com/stretchr/testify/assert"
@@ -36,16 +36,16 @@ func TestApiURL(t *testing.T) {
 	err := e2e.PrepareEnvironment(clt, namespace)
 	require.NoError(t, err)
 
-	instance := dynatracev1alpha1.DynaKube{
+	instance := dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
 			Name:      testName,
 		},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: apiURL,
 			Tokens: e2e.TokenSecretName,
-			ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-				Enabled: true,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 			},
 		},
 	}
@@ -54,27 +54,27 @@ func TestApiURL(t *testing.T) {
 	assert.NoError(t, err)
 
 	phaseWait := e2e.NewOneAgentWaitConfiguration(t, clt, maxWaitCycles, namespace, testName)
-	err = phaseWait.WaitForPhase(dynatracev1alpha1.Deploying)
+	err = phaseWait.WaitForPhase(dynatracev1beta1.Deploying)
 	assert.NoError(t, err)
 
-	err = phaseWait.WaitForPhase(dynatracev1alpha1.Running)
+	err = phaseWait.WaitForPhase(dynatracev1beta1.Running)
 	assert.NoError(t, err)
 
 	apiToken, paasToken := e2e.GetTokensFromEnv()
 	dtc, err := dtclient.NewClient(apiURL, apiToken, paasToken)
 	assert.NoError(t, err)
 
-	connectionInfo, err := dtc.GetConnectionInfo()
+	connectionInfo, err := dtc.GetConnectionInfo(context.TODO())
 	assert.NoError(t, err)
 	assert.NotNil(t, connectionInfo)
 	assert.Equal(t, environmentId, connectionInfo.TenantUUID)
 	assert.True(t, containsAPIConnectionHost(connectionInfo, apiURL))
 
-	apiScopes, err := dtc.GetTokenScopes(apiToken)
+	apiScopes, err := dtc.GetTokenScopes(context.TODO(), apiToken)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, apiScopes)
 
-	paasScopes, err := dtc.GetTokenScopes(paasToken)
+	paasScopes, err := dtc.GetTokenScopes(context.TODO(), paasToken)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, paasScopes)
 }
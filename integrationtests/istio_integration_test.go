@@ -6,7 +6,7 @@ import (
 	"context"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	istiov1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -18,12 +18,12 @@ func TestReconcileOneAgent_ReconcileIstio(t *testing.T) {
 
 	defer e.Stop()
 
-	e.AddOneAgent("dynakube", &dynatracev1alpha1.DynaKubeSpec{
+	e.AddOneAgent("dynakube", &dynatracev1beta1.DynaKubeSpec{
 		APIURL:      DefaultTestAPIURL,
 		Tokens:      "token-test",
 		EnableIstio: true,
-		ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-			Enabled: true,
+		OneAgent: dynatracev1beta1.OneAgentSpec{
+			ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 		},
 	})
 
@@ -58,21 +58,21 @@ func TestReconcileOneAgent_ReconcileIstioWithMultipleOneAgentObjects(t *testing.
 
 	defer e.Stop()
 
-	e.AddOneAgent("oneagent1", &dynatracev1alpha1.DynaKubeSpec{
+	e.AddOneAgent("oneagent1", &dynatracev1beta1.DynaKubeSpec{
 		APIURL:      DefaultTestAPIURL,
 		Tokens:      "token-test",
 		EnableIstio: true,
-		ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-			Enabled: true,
+		OneAgent: dynatracev1beta1.OneAgentSpec{
+			ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 		},
 	})
 
-	e.AddOneAgent("oneagent2", &dynatracev1alpha1.DynaKubeSpec{
+	e.AddOneAgent("oneagent2", &dynatracev1beta1.DynaKubeSpec{
 		APIURL:      DefaultTestAPIURL,
 		Tokens:      "token-test",
 		EnableIstio: true,
-		ClassicFullStack: dynatracev1alpha1.FullStackSpec{
-			Enabled: true,
+		OneAgent: dynatracev1beta1.OneAgentSpec{
+			ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
 		},
 	})
 
@@ -39,6 +39,9 @@ const (
 	// ServiceName is the name used for the webhook's corresponding Service and MutatingWebhookConfiguration objects.
 	ServiceName = "dynatrace-webhook"
 
+	// DeploymentName is the name of the webhook's Deployment.
+	DeploymentName = "dynatrace-webhook"
+
 	// InstallContainerName is the name used for the install container
 	InstallContainerName = "install-oneagent"
 )
@@ -10,7 +10,7 @@ import (
 	"strconv"
 	"strings"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/controllers/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/controllers/utils"
@@ -45,6 +45,7 @@ func AddToManager(mgr manager.Manager, ns string) error {
 		}
 	}
 
+	registerValidateEndpoint(mgr)
 	registerHealthzEndpoint(mgr)
 	return nil
 }
@@ -145,7 +146,7 @@ func (m *podInjector) Handle(ctx context.Context, req admission.Request) admissi
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf("no DynaKube instance set for namespace: %s", req.Namespace))
 	}
 
-	var oa dynatracev1alpha1.DynaKube
+	var oa dynatracev1beta1.DynaKube
 	if err := m.client.Get(ctx, client.ObjectKey{Name: oaName, Namespace: m.namespace}, &oa); k8serrors.IsNotFound(err) {
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf(
 			"namespace '%s' is assigned to DynaKube instance '%s' but doesn't exist", req.Namespace, oaName))
@@ -153,7 +154,7 @@ func (m *podInjector) Handle(ctx context.Context, req admission.Request) admissi
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	if !oa.Spec.CodeModules.Enabled {
+	if !oa.NeedsAppInjection() {
 		logger.Info("injection disabled")
 		return admission.Patched("")
 	}
@@ -216,7 +217,7 @@ func (m *podInjector) Handle(ctx context.Context, req admission.Request) admissi
 	failurePolicy := utils.GetField(pod.Annotations, dtwebhook.AnnotationFailurePolicy, "silent")
 	image := m.image
 
-	dkVol := oa.Spec.CodeModules.Volume
+	dkVol := oa.AppInjectionSpec().Volume
 	if dkVol == (corev1.VolumeSource{}) {
 		dkVol.CSI = &corev1.CSIVolumeSource{
 			Driver: dtcsi.DriverName,
@@ -292,7 +293,7 @@ func (m *podInjector) Handle(ctx context.Context, req admission.Request) admissi
 			{Name: "oneagent-share", MountPath: "/mnt/share"},
 			{Name: "oneagent-config", MountPath: "/mnt/config"},
 		},
-		Resources: oa.Spec.CodeModules.Resources,
+		Resources: oa.AppInjectionSpec().InitResources,
 	}
 
 	for i := range pod.Spec.Containers {
@@ -329,7 +330,7 @@ func updateInstallContainer(ic *corev1.Container, number int, name string, image
 }
 
 // updateContainer sets missing preload Variables
-func updateContainer(c *corev1.Container, oa *dynatracev1alpha1.DynaKube,
+func updateContainer(c *corev1.Container, oa *dynatracev1beta1.DynaKube,
 	pod *corev1.Pod, deploymentMetadata *deploymentmetadata.DeploymentMetadata) {
 
 	logger.Info("updating container with missing preload variables", "containerName", c.Name)
@@ -361,7 +362,7 @@ func updateContainer(c *corev1.Container, oa *dynatracev1alpha1.DynaKube,
 			Value: deploymentMetadata.AsString(),
 		})
 
-	if oa.Spec.Proxy != nil && (oa.Spec.Proxy.Value != "" || oa.Spec.Proxy.ValueFrom != "") {
+	if oa.CommunicationProxy() != nil {
 		c.Env = append(c.Env,
 			corev1.EnvVar{
 				Name: "DT_PROXY",
@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/scheme"
@@ -59,31 +59,33 @@ func TestInjectionWithMissingOneAgentAPM(t *testing.T) {
 	require.Equal(t, resp.Result.Message, "namespace 'test-namespace' is assigned to DynaKube instance 'dynakube' but doesn't exist")
 }
 
-func createPodInjector(_ *testing.T, decoder *admission.Decoder) (*podInjector, *dynatracev1alpha1.DynaKube) {
-	dynakube := &dynatracev1alpha1.DynaKube{
+func createPodInjector(_ *testing.T, decoder *admission.Decoder) (*podInjector, *dynatracev1beta1.DynaKube) {
+	dynakube := &dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: "oneagent", Namespace: "dynatrace"},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
+		Spec: dynatracev1beta1.DynaKubeSpec{
 			APIURL: "https://test-api-url.com/api",
-			InfraMonitoring: dynatracev1alpha1.FullStackSpec{
-				Enabled:           true,
-				UseImmutableImage: true,
-			},
-			CodeModules: dynatracev1alpha1.CodeModulesSpec{
-				Enabled: true,
-				Resources: corev1.ResourceRequirements{
-					Limits: corev1.ResourceList{
-						corev1.ResourceCPU:    resource.MustParse("1"),
-						corev1.ResourceMemory: resource.MustParse("500M"),
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{
+					HostInjectSpec: dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
 					},
-					Requests: corev1.ResourceList{
-						corev1.ResourceCPU:    resource.MustParse("100m"),
-						corev1.ResourceMemory: resource.MustParse("100M"),
+					AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{
+						InitResources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("500M"),
+							},
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("100m"),
+								corev1.ResourceMemory: resource.MustParse("100M"),
+							},
+						},
 					},
 				},
 			},
 		},
-		Status: dynatracev1alpha1.DynaKubeStatus{
-			OneAgent: dynatracev1alpha1.OneAgentStatus{
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
 				UseImmutableImage: true,
 			},
 		},
@@ -110,7 +112,7 @@ func TestPodInjection(t *testing.T) {
 	require.NoError(t, err)
 
 	inj, instance := createPodInjector(t, decoder)
-	instance.Spec.CodeModules.Volume = corev1.VolumeSource{
+	instance.Spec.OneAgent.CloudNativeFullStack.Volume = corev1.VolumeSource{
 		EmptyDir: &corev1.EmptyDirVolumeSource{},
 	}
 	err = inj.client.Update(context.TODO(), instance)
@@ -245,20 +247,21 @@ func TestPodInjectionWithCSI(t *testing.T) {
 	assert.Equal(t, expected, updPod)
 }
 
-func createDynakubeInstance(_ *testing.T) *dynatracev1alpha1.DynaKube {
-	instance := &dynatracev1alpha1.DynaKube{
+func createDynakubeInstance(_ *testing.T) *dynatracev1beta1.DynaKube {
+	instance := &dynatracev1beta1.DynaKube{
 		ObjectMeta: metav1.ObjectMeta{Name: "oneagent", Namespace: "dynatrace"},
-		Spec: dynatracev1alpha1.DynaKubeSpec{
-			InfraMonitoring: dynatracev1alpha1.FullStackSpec{
-				Enabled:           true,
-				UseImmutableImage: true,
-			},
-			CodeModules: dynatracev1alpha1.CodeModulesSpec{
-				Enabled: true,
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{
+					HostInjectSpec: dynatracev1beta1.HostInjectSpec{
+						UseImmutableImage: true,
+					},
+					AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{},
+				},
 			},
 		},
-		Status: dynatracev1alpha1.DynaKubeStatus{
-			OneAgent: dynatracev1alpha1.OneAgentStatus{
+		Status: dynatracev1beta1.DynaKubeStatus{
+			OneAgent: dynatracev1beta1.OneAgentStatus{
 				UseImmutableImage: true,
 			},
 		},
@@ -267,14 +270,12 @@ func createDynakubeInstance(_ *testing.T) *dynatracev1alpha1.DynaKube {
 	return instance
 }
 
-func withAgentVersion(_ *testing.T, instance *dynatracev1alpha1.DynaKube, version string) {
-	instance.Spec.OneAgent = dynatracev1alpha1.OneAgentSpec{
-		Version: version,
-	}
+func withAgentVersion(_ *testing.T, instance *dynatracev1beta1.DynaKube, version string) {
+	instance.Spec.OneAgent.CloudNativeFullStack.Version = version
 }
 
-func withoutCSIDriver(_ *testing.T, instance *dynatracev1alpha1.DynaKube) {
-	instance.Spec.CodeModules.Volume = corev1.VolumeSource{
+func withoutCSIDriver(_ *testing.T, instance *dynatracev1beta1.DynaKube) {
+	instance.Spec.OneAgent.CloudNativeFullStack.Volume = corev1.VolumeSource{
 		EmptyDir: &corev1.EmptyDirVolumeSource{},
 	}
 }
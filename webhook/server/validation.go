@@ -0,0 +1,293 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/controllers/dynakube/updates/maintenance"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func registerValidateEndpoint(mgr manager.Manager) {
+	mgr.GetWebhookServer().Register("/validate", &webhook.Admission{Handler: &dynakubeValidator{
+		apiReader: mgr.GetAPIReader(),
+	}})
+}
+
+// dynakubeValidator rejects DynaKube objects which can't be reconciled
+type dynakubeValidator struct {
+	apiReader client.Reader
+	decoder   *admission.Decoder
+}
+
+// Handle validates incoming DynaKube objects of any served version
+func (v *dynakubeValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	dk, msg, err := v.decode(req.Kind.Version, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	logger.Info("validating DynaKube", "name", req.Name, "namespace", req.Namespace, "version", req.Kind.Version)
+
+	msg = append(msg, validateAPIURL(dk)...)
+	msg = append(msg, validateOneAgentModes(dk)...)
+	msg = append(msg, validateFeatureFlags(dk)...)
+	msg = append(msg, validateCodeModulesVolume(dk)...)
+	msg = append(msg, validatePodTemplateOverride(dk)...)
+	msg = append(msg, validateHostConfig(dk)...)
+	msg = append(msg, validateMaintenanceWindow(dk)...)
+
+	tokenMsg, err := v.validateTokenSecret(ctx, dk, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	// A missing token secret only denies changes to the tokens, so that the DynaKube can still be updated and fixed
+	var warnings []string
+	if len(tokenMsg) > 0 {
+		changed, err := v.tokensChanged(req, dk)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if changed {
+			msg = append(msg, tokenMsg...)
+		} else {
+			warnings = tokenMsg
+		}
+	}
+
+	if len(msg) > 0 {
+		return admission.Denied(strings.Join(msg, ", ")).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// tokensChanged returns true if the request creates the DynaKube or changes the name of its token secret
+func (v *dynakubeValidator) tokensChanged(req admission.Request, dk *dynatracev1beta1.DynaKube) (bool, error) {
+	if req.Operation != admissionv1.Update {
+		return true, nil
+	}
+
+	old, _, err := v.decode(req.Kind.Version, req.OldObject)
+	if err != nil {
+		return false, err
+	}
+	return old.Tokens() != dk.Tokens(), nil
+}
+
+// decode returns the raw object of the given version as a v1beta1 DynaKube. v1alpha1 objects are converted, and configurations
+// which would be silently dropped by the conversion are reported as validation errors.
+func (v *dynakubeValidator) decode(version string, raw runtime.RawExtension) (*dynatracev1beta1.DynaKube, []string, error) {
+	if version != dynatracev1alpha1.GroupVersion.Version {
+		var dk dynatracev1beta1.DynaKube
+		if err := v.decoder.DecodeRaw(raw, &dk); err != nil {
+			return nil, nil, err
+		}
+		return &dk, nil, nil
+	}
+
+	var old dynatracev1alpha1.DynaKube
+	if err := v.decoder.DecodeRaw(raw, &old); err != nil {
+		return nil, nil, err
+	}
+
+	var msg []string
+	if old.Spec.ClassicFullStack.Enabled && old.Spec.InfraMonitoring.Enabled {
+		msg = append(msg, ".spec.classicFullStack and .spec.infraMonitoring can't be enabled at the same time")
+	}
+	if old.Spec.ClassicFullStack.Enabled && old.Spec.CodeModules.Enabled {
+		msg = append(msg, ".spec.classicFullStack and .spec.codeModules can't be enabled at the same time")
+	}
+
+	var dk dynatracev1beta1.DynaKube
+	if err := old.ConvertTo(&dk); err != nil {
+		return nil, nil, err
+	}
+	return &dk, msg, nil
+}
+
+func validateAPIURL(dk *dynatracev1beta1.DynaKube) []string {
+	if dk.Spec.APIURL == "" {
+		return []string{".spec.apiUrl is missing"}
+	}
+	if !strings.HasSuffix(dk.Spec.APIURL, "/api") {
+		return []string{fmt.Sprintf(".spec.apiUrl '%s' must end with /api", dk.Spec.APIURL)}
+	}
+	return nil
+}
+
+func validateOneAgentModes(dk *dynatracev1beta1.DynaKube) []string {
+	oa := dk.Spec.OneAgent
+	modes := []struct {
+		name    string
+		enabled bool
+	}{
+		{"classicFullStack", oa.ClassicFullStack != nil},
+		{"hostMonitoring", oa.HostMonitoring != nil},
+		{"cloudNativeFullStack", oa.CloudNativeFullStack != nil},
+		{"applicationMonitoring", oa.ApplicationMonitoring != nil},
+	}
+
+	var enabled []string
+	for _, mode := range modes {
+		if mode.enabled {
+			enabled = append(enabled, ".spec.oneAgent."+mode.name)
+		}
+	}
+
+	if len(enabled) > 1 {
+		return []string{fmt.Sprintf("only one OneAgent mode can be used at a time, got %s", strings.Join(enabled, " and "))}
+	}
+	return nil
+}
+
+func validateFeatureFlags(dk *dynatracev1beta1.DynaKube) []string {
+	if err := dk.ValidateFeatureOneAgentMaxUnavailable(); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+func validateCodeModulesVolume(dk *dynatracev1beta1.DynaKube) []string {
+	appInjectionSpec := dk.AppInjectionSpec()
+	if appInjectionSpec == nil || appInjectionSpec.Volume == (corev1.VolumeSource{}) {
+		return nil
+	}
+
+	vol := appInjectionSpec.Volume
+	if n := countVolumeSources(vol); n > 1 {
+		return []string{fmt.Sprintf("code modules volume must have exactly one volume source, got %d", n)}
+	}
+
+	if vol.CSI != nil && vol.CSI.Driver != dtcsi.DriverName {
+		return []string{fmt.Sprintf("code modules CSI volume must use driver '%s', got '%s'", dtcsi.DriverName, vol.CSI.Driver)}
+	}
+	return nil
+}
+
+func validatePodTemplateOverride(dk *dynatracev1beta1.DynaKube) []string {
+	fs := dk.HostInjectSpec()
+	if fs == nil {
+		return nil
+	}
+
+	if err := dynatracev1beta1.ApplyPodTemplateOverride(&corev1.PodTemplateSpec{}, fs.PodTemplateOverride); err != nil {
+		return []string{fmt.Sprintf("OneAgent podTemplateOverride can't be applied: %s", err)}
+	}
+	return nil
+}
+
+var (
+	hostGroupPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,100}$`)
+	hostTagPattern   = regexp.MustCompile(`^[^=\s]+(=.+)?$`)
+)
+
+// validateHostConfig checks the host config of OneAgent and of its node pools, which are passed as installer arguments.
+// Node pools are defined by host config overrides, and deployed by DaemonSets named after them.
+func validateHostConfig(dk *dynatracev1beta1.DynaKube) []string {
+	fs := dk.HostInjectSpec()
+	if fs == nil {
+		return nil
+	}
+
+	msg := validateHostConfigFields(".spec.oneAgent host config", fs.HostConfig)
+	if fs.HostGroup != "" {
+		for _, arg := range fs.Args {
+			if strings.HasPrefix(arg, "--set-host-group=") {
+				msg = append(msg, "OneAgent host group can't be set both by hostGroup and by the --set-host-group argument")
+				break
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	for i, override := range fs.HostConfigOverrides {
+		field := fmt.Sprintf(".spec.oneAgent hostConfigOverrides[%d]", i)
+		for _, err := range validation.IsDNS1123Label(override.Name) {
+			msg = append(msg, fmt.Sprintf("%s name '%s' is invalid: %s", field, override.Name, err))
+		}
+		if dynatracev1beta1.IsSupportedOneAgentArchitecture(override.Name) {
+			msg = append(msg, fmt.Sprintf("%s name '%s' can't be the name of a node architecture", field, override.Name))
+		}
+		if names[override.Name] {
+			msg = append(msg, fmt.Sprintf("%s name '%s' is used more than once", field, override.Name))
+		}
+		names[override.Name] = true
+
+		if len(override.NodeSelector) == 0 {
+			msg = append(msg, fmt.Sprintf("%s nodeSelector is missing", field))
+		}
+		msg = append(msg, validateHostConfigFields(field, override.HostConfig)...)
+	}
+	return msg
+}
+
+func validateHostConfigFields(field string, hc dynatracev1beta1.HostConfig) []string {
+	var msg []string
+	if hc.HostGroup != "" && (!hostGroupPattern.MatchString(hc.HostGroup) || strings.HasPrefix(hc.HostGroup, "dt.")) {
+		msg = append(msg, fmt.Sprintf("%s hostGroup '%s' must consist of at most 100 alphanumeric characters, '-', '_' or '.', and can't start with 'dt.'", field, hc.HostGroup))
+	}
+	for _, tag := range hc.HostTags {
+		if !hostTagPattern.MatchString(tag) {
+			msg = append(msg, fmt.Sprintf("%s hostTags '%s' must be a key or key=value without whitespaces in the key", field, tag))
+		}
+	}
+	for key := range hc.HostProperties {
+		if key == "" || strings.Contains(key, "=") || key == "OperatorVersion" {
+			msg = append(msg, fmt.Sprintf("%s hostProperties key '%s' must be non-empty, can't contain '=' and can't be OperatorVersion", field, key))
+		}
+	}
+	return msg
+}
+
+func validateMaintenanceWindow(dk *dynatracev1beta1.DynaKube) []string {
+	if _, err := maintenance.NewWindow(dk.Spec.MaintenanceWindow); err != nil {
+		return []string{fmt.Sprintf(".spec.maintenanceWindow is invalid: %s", err)}
+	}
+	return nil
+}
+
+// countVolumeSources returns how many of the mutually exclusive sources are set on the VolumeSource
+func countVolumeSources(vol corev1.VolumeSource) int {
+	var n int
+	val := reflect.ValueOf(vol)
+	for i := 0; i < val.NumField(); i++ {
+		if f := val.Field(i); f.Kind() == reflect.Ptr && !f.IsNil() {
+			n++
+		}
+	}
+	return n
+}
+
+func (v *dynakubeValidator) validateTokenSecret(ctx context.Context, dk *dynatracev1beta1.DynaKube, namespace string) ([]string, error) {
+	var secret corev1.Secret
+	err := v.apiReader.Get(ctx, client.ObjectKey{Name: dk.Tokens(), Namespace: namespace}, &secret)
+	if k8serrors.IsNotFound(err) {
+		return []string{fmt.Sprintf("token secret '%s' doesn't exist in namespace '%s'", dk.Tokens(), namespace)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// InjectDecoder injects the decoder
+func (v *dynakubeValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
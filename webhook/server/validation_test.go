@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1alpha1 "github.com/Dynatrace/dynatrace-operator/api/v1alpha1"
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/api/v1beta1"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/scheme"
+	"github.com/Dynatrace/dynatrace-operator/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	testDynakubeName = "dynakube"
+	testOperatorNs   = "dynatrace"
+	testValidAPIURL  = "https://test-tenant.dev.dynatracelabs.com/api"
+)
+
+func newValidDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testOperatorNs},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testValidAPIURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.HostInjectSpec{},
+			},
+		},
+	}
+}
+
+func runValidation(t *testing.T, obj runtime.Object, version string, objects ...client.Object) admission.Response {
+	return handle(t, admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "dynatrace.com", Version: version, Kind: "DynaKube"},
+		Name:      testDynakubeName,
+		Namespace: testOperatorNs,
+		Operation: admissionv1.Create,
+		Object:    toRaw(t, obj),
+	}, objects...)
+}
+
+func runUpdateValidation(t *testing.T, old, obj runtime.Object, version string, objects ...client.Object) admission.Response {
+	return handle(t, admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "dynatrace.com", Version: version, Kind: "DynaKube"},
+		Name:      testDynakubeName,
+		Namespace: testOperatorNs,
+		Operation: admissionv1.Update,
+		Object:    toRaw(t, obj),
+		OldObject: toRaw(t, old),
+	}, objects...)
+}
+
+func handle(t *testing.T, request admissionv1.AdmissionRequest, objects ...client.Object) admission.Response {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	require.NoError(t, err)
+
+	v := &dynakubeValidator{
+		apiReader: fake.NewClient(objects...),
+		decoder:   decoder,
+	}
+
+	req := admission.Request{AdmissionRequest: request}
+	resp := v.Handle(context.TODO(), req)
+	require.NoError(t, resp.Complete(req))
+	return resp
+}
+
+func toRaw(t *testing.T, obj runtime.Object) runtime.RawExtension {
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return runtime.RawExtension{Raw: raw}
+}
+
+func tokenSecret() *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testOperatorNs}}
+}
+
+func TestDynakubeValidator(t *testing.T) {
+	t.Run(`valid dynakube is allowed`, func(t *testing.T) {
+		resp := runValidation(t, newValidDynakube(), "v1beta1", tokenSecret())
+		assert.True(t, resp.Allowed)
+	})
+	t.Run(`api url without /api suffix is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.APIURL = "https://test-tenant.dev.dynatracelabs.com"
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "must end with /api")
+	})
+	t.Run(`missing api url is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.APIURL = ""
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), ".spec.apiUrl is missing")
+	})
+	t.Run(`conflicting oneagent modes are denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent.HostMonitoring = &dynatracev1beta1.HostInjectSpec{}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "only one OneAgent mode can be used at a time")
+	})
+	t.Run(`conflicting v1alpha1 oneagent modes are denied`, func(t *testing.T) {
+		dk := &dynatracev1alpha1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testOperatorNs},
+			Spec: dynatracev1alpha1.DynaKubeSpec{
+				APIURL:           testValidAPIURL,
+				ClassicFullStack: dynatracev1alpha1.FullStackSpec{Enabled: true},
+				InfraMonitoring:  dynatracev1alpha1.FullStackSpec{Enabled: true},
+			},
+		}
+
+		resp := runValidation(t, dk, "v1alpha1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), ".spec.classicFullStack and .spec.infraMonitoring")
+	})
+	t.Run(`valid v1alpha1 dynakube is allowed`, func(t *testing.T) {
+		dk := &dynatracev1alpha1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testOperatorNs},
+			Spec: dynatracev1alpha1.DynaKubeSpec{
+				APIURL:          testValidAPIURL,
+				InfraMonitoring: dynatracev1alpha1.FullStackSpec{Enabled: true},
+				CodeModules:     dynatracev1alpha1.CodeModulesSpec{Enabled: true},
+			},
+		}
+
+		resp := runValidation(t, dk, "v1alpha1", tokenSecret())
+		assert.True(t, resp.Allowed)
+	})
+	t.Run(`missing token secret is denied`, func(t *testing.T) {
+		resp := runValidation(t, newValidDynakube(), "v1beta1")
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "token secret 'dynakube' doesn't exist")
+	})
+	t.Run(`missing token secret only warns on updates which keep the tokens`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Annotations = map[string]string{"example.com/updated": "true"}
+
+		resp := runUpdateValidation(t, newValidDynakube(), dk, "v1beta1")
+		assert.True(t, resp.Allowed)
+		assert.Equal(t, []string{"token secret 'dynakube' doesn't exist in namespace 'dynatrace'"}, resp.Warnings)
+	})
+	t.Run(`missing token secret is denied on updates of the tokens`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.Tokens = "other-tokens"
+
+		resp := runUpdateValidation(t, newValidDynakube(), dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "token secret 'other-tokens' doesn't exist")
+	})
+	t.Run(`unparseable max unavailable annotation is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Annotations = map[string]string{"alpha.operator.dynatrace.com/feature-oneagent-max-unavailable": "two"}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "must be a positive integer")
+	})
+	t.Run(`csi volume with foreign driver is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent = dynatracev1beta1.OneAgentSpec{ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{}}
+		dk.Spec.OneAgent.ApplicationMonitoring.Volume.CSI = &corev1.CSIVolumeSource{Driver: "other.csi.driver"}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "must use driver")
+	})
+	t.Run(`volume with several sources is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent = dynatracev1beta1.OneAgentSpec{ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{}}
+		dk.Spec.OneAgent.ApplicationMonitoring.Volume = corev1.VolumeSource{
+			CSI:      &corev1.CSIVolumeSource{Driver: dtcsi.DriverName},
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "exactly one volume source")
+	})
+	t.Run(`pod template override which can't be applied is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent.ClassicFullStack.PodTemplateOverride = &runtime.RawExtension{Raw: []byte(`{"spec":{"containers":"sidecar"}}`)}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "podTemplateOverride can't be applied")
+	})
+	t.Run(`valid host config is allowed`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent.ClassicFullStack.HostConfig = dynatracev1beta1.HostConfig{
+			HostGroup:      "prod.eu-west_1",
+			HostTags:       []string{"team=platform", "critical"},
+			HostProperties: map[string]string{"owner": "platform"},
+		}
+		dk.Spec.OneAgent.ClassicFullStack.HostConfigOverrides = []dynatracev1beta1.HostConfigOverride{
+			{Name: "gpu", NodeSelector: map[string]string{"gpu": "true"}, HostConfig: dynatracev1beta1.HostConfig{HostGroup: "gpu"}},
+		}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.True(t, resp.Allowed, resp.Result.Reason)
+	})
+	t.Run(`invalid host config is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent.ClassicFullStack.Args = []string{"--set-host-group=other"}
+		dk.Spec.OneAgent.ClassicFullStack.HostConfig = dynatracev1beta1.HostConfig{
+			HostGroup:      "dt.reserved",
+			HostTags:       []string{"=value"},
+			HostProperties: map[string]string{"OperatorVersion": "1"},
+		}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "hostGroup 'dt.reserved'")
+		assert.Contains(t, string(resp.Result.Reason), "hostTags '=value'")
+		assert.Contains(t, string(resp.Result.Reason), "hostProperties key 'OperatorVersion'")
+		assert.Contains(t, string(resp.Result.Reason), "--set-host-group argument")
+	})
+	t.Run(`invalid host config overrides are denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.OneAgent.ClassicFullStack.HostConfigOverrides = []dynatracev1beta1.HostConfigOverride{
+			{Name: "arm64", NodeSelector: map[string]string{"a": "b"}},
+			{Name: "gpu"},
+			{Name: "gpu", NodeSelector: map[string]string{"a": "b"}},
+			{Name: "GPU_nodes", NodeSelector: map[string]string{"a": "b"}},
+		}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "name 'arm64' can't be the name of a node architecture")
+		assert.Contains(t, string(resp.Result.Reason), "hostConfigOverrides[1] nodeSelector is missing")
+		assert.Contains(t, string(resp.Result.Reason), "name 'gpu' is used more than once")
+		assert.Contains(t, string(resp.Result.Reason), "name 'GPU_nodes' is invalid")
+	})
+	t.Run(`valid maintenance window is allowed`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.MaintenanceWindow = &dynatracev1beta1.MaintenanceWindowSpec{
+			Schedule: "0 2 * * 1-5",
+			Duration: metav1.Duration{Duration: 2 * time.Hour},
+		}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.True(t, resp.Allowed)
+	})
+	t.Run(`invalid maintenance window is denied`, func(t *testing.T) {
+		dk := newValidDynakube()
+		dk.Spec.MaintenanceWindow = &dynatracev1beta1.MaintenanceWindowSpec{
+			Schedule: "0 25 * * *",
+			Duration: metav1.Duration{Duration: 2 * time.Hour},
+		}
+
+		resp := runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), ".spec.maintenanceWindow is invalid: invalid maintenance window schedule '0 25 * * *'")
+
+		dk.Spec.MaintenanceWindow.Schedule = "0 2 * * *"
+		dk.Spec.MaintenanceWindow.Duration = metav1.Duration{}
+
+		resp = runValidation(t, dk, "v1beta1", tokenSecret())
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, string(resp.Result.Reason), "invalid maintenance window duration")
+	})
+}